@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"log"
 	"os"
 	"strings"
@@ -38,7 +39,7 @@ func hostsArgsFunc(cmd *cobra.Command, args []string, toComplete string) ([]stri
 
 	var completions []string
 
-	hosts, _ := DB.GetHosts()
+	hosts, _ := DB.GetHostsContext(commandContext(cmd))
 
 	for _, host := range hosts {
 		if strings.HasPrefix(host.Hostname, toComplete) {
@@ -56,7 +57,7 @@ func groupsArgsFunc(cmd *cobra.Command, args []string, toComplete string) ([]str
 
 	var completions []string
 
-	groups, _ := DB.GetGroups()
+	groups, _ := DB.GetGroupsContext(commandContext(cmd))
 
 	for _, group := range groups {
 		if strings.HasPrefix(group.Name, toComplete) {
@@ -66,3 +67,17 @@ func groupsArgsFunc(cmd *cobra.Command, args []string, toComplete string) ([]str
 
 	return completions, cobra.ShellCompDirectiveDefault
 }
+
+// commandContext return the command context. The completion functions are called on the
+// command found by cobra rather than the executed one, which has no context set
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+
+	if ctx := cmd.Root().Context(); ctx != nil {
+		return ctx
+	}
+
+	return context.Background()
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/tatsushid/go-prettytable"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// editable is a host or group as shown in the editor
+type editable interface {
+	UnmarshalVars() error
+}
+
+// fieldChanges return the editor json fields that differ between the read, stored and edited versions of a
+// record as rows of field name and the three values. A nil version is a record that does not exist
+func fieldChanges(read, stored, yours editable) (rows [][]string, err error) {
+	versions := make([]map[string]json.RawMessage, 3)
+
+	for i, v := range []editable{read, stored, yours} {
+		versions[i] = make(map[string]json.RawMessage)
+
+		if v == nil {
+			continue
+		}
+
+		if err = v.UnmarshalVars(); err != nil {
+			return nil, err
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(b, &versions[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	var fields []string
+
+	for _, version := range versions {
+		for field := range version {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		// the revision always differ, it is what detected the conflict
+		if field == "revision" {
+			continue
+		}
+
+		values := make([]string, 3)
+
+		for j := range versions {
+			values[j] = "-"
+			if value, ok := versions[j][field]; ok {
+				values[j] = string(value)
+			}
+		}
+
+		if values[0] == values[1] && values[1] == values[2] {
+			continue
+		}
+
+		rows = append(rows, append([]string{field}, values...))
+	}
+
+	return rows, nil
+}
+
+// printConflict print the fields of a record changed by someone else, by the user or by both since it was read
+func printConflict(name string, rows [][]string) {
+	tbl, err := prettytable.NewTable([]prettytable.Column{
+		{Header: "Name", MinWidth: 12},
+		{Header: "Field", MinWidth: 12},
+		{Header: "Read", MinWidth: 12},
+		{Header: "Stored", MinWidth: 12},
+		{Header: "Yours", MinWidth: 12},
+	}...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tbl.Separator = separator
+
+	for _, row := range rows {
+		if err = tbl.AddRow(name, row[0], row[1], row[2], row[3]); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// nolint: errcheck,gosec
+	tbl.Print()
+}
+
+// resolveHostsConflict show how the hosts changed since they were read and let the user edit their changes
+// again on top of the stored hosts. It return the stored hosts as the new base and the edited hosts
+func resolveHostsConflict(ctx context.Context, base, yours datastructs.Hosts, conflict error) (
+	stored, edited datastructs.Hosts, err error) {
+	fmt.Println(conflict)
+
+	stored = make(datastructs.Hosts, len(yours))
+
+	for i := range yours {
+		if stored[i], err = DB.SelectHostContext(ctx, yours[i].Hostname); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = loadAddresses(ctx, DB, stored); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range yours {
+		var read, current editable
+
+		for j := range base {
+			if base[j].Hostname == yours[i].Hostname {
+				read = &base[j]
+			}
+		}
+
+		if stored[i].ID != 0 {
+			current = &stored[i]
+		}
+
+		rows, err := fieldChanges(read, current, &yours[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		printConflict(yours[i].Hostname, rows)
+
+		// the edit is now based on the stored host
+		yours[i].Revision = stored[i].Revision
+	}
+
+	if accept {
+		return nil, nil, conflict
+	}
+
+	fmt.Println("Edit your changes again on top of the stored hosts?")
+
+	if !User.confirm() {
+		return nil, nil, conflict
+	}
+
+	edited, err = editHosts(&yours)
+
+	return stored, edited, err
+}
+
+// resolveGroupConflict is resolveHostsConflict for a single group
+func resolveGroupConflict(ctx context.Context, base, yours datastructs.Group, conflict error) (
+	stored, edited datastructs.Group, err error) {
+	fmt.Println(conflict)
+
+	if stored, err = DB.SelectGroupContext(ctx, yours.Name); err != nil {
+		return stored, edited, err
+	}
+
+	var current editable
+
+	if stored.ID != 0 {
+		current = &stored
+	}
+
+	rows, err := fieldChanges(&base, current, &yours)
+	if err != nil {
+		return stored, edited, err
+	}
+
+	printConflict(yours.Name, rows)
+
+	// the edit is now based on the stored group
+	yours.Revision = stored.Revision
+
+	if accept {
+		return stored, edited, conflict
+	}
+
+	fmt.Println("Edit your changes again on top of the stored group?")
+
+	if !User.confirm() {
+		return stored, edited, conflict
+	}
+
+	edited, err = editGroup(&yours)
+
+	return stored, edited, err
+}
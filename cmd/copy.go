@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
@@ -35,18 +37,18 @@ var copyHostVar = &cobra.Command{
 	ValidArgsFunction: hostsArgsFunc,
 	Args:              cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := copyHostCase(args); err != nil {
+		if err := copyHostCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func copyHostCase(args []string) error {
+func copyHostCase(ctx context.Context, args []string) error {
 	var hosts datastructs.Hosts
 
 	var err error
 
-	hosts, err = scanHosts(args[0])
+	hosts, err = scanHosts(ctx, DB, args[0])
 	if err != nil {
 		return err
 	}
@@ -85,7 +87,9 @@ func copyHostCase(args []string) error {
 	printHosts(hosts)
 
 	if User.confirm() {
-		err = confirmedHosts(&hosts)
+		err = inTx(ctx, func(tx database.Querier) error {
+			return confirmedHosts(ctx, tx, &hosts)
+		})
 		if err != nil {
 			return err
 		}
@@ -106,23 +110,25 @@ var copyGroupVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := copyGroupCase(args); err != nil {
+		if err := copyGroupCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func copyGroupCase(args []string) error {
+func copyGroupCase(ctx context.Context, args []string) error {
 	var group datastructs.Group
 
 	var err error
 
-	group, err = viewGroupByName(args[0])
+	group, err = viewGroupByName(ctx, DB, args[0])
 	if err != nil {
 		return err
 	}
 
+	// the copy is a new group, it does not carry the revision of its source
 	group.Name = args[1]
+	group.Revision = 0
 
 	group, err = editGroup(&group)
 	if err != nil {
@@ -132,7 +138,9 @@ func copyGroupCase(args []string) error {
 	printGroups([]datastructs.Group{group})
 
 	if User.confirm() {
-		err := createGroup(&group)
+		err := inTx(ctx, func(tx database.Querier) error {
+			return createGroup(ctx, tx, &group)
+		})
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"testing"
 )
 
@@ -32,7 +33,7 @@ func Test_copyHostCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := copyHostCase(tt.args); (err != nil) != tt.wantErr {
+			if err := copyHostCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("copyHostCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -62,7 +63,7 @@ func Test_copyGroupCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := copyGroupCase(tt.args); (err != nil) != tt.wantErr {
+			if err := copyGroupCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("copyGroupCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
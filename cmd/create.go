@@ -1,21 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
 var (
-	monitor bool
-	enable  bool
-	accept  bool
-	ip      string
-	group   string
+	monitor      bool
+	enable       bool
+	accept       bool
+	ip           string
+	directGroups []string
+	addresses    []string
+	primary      string
 )
 
 func init() {
@@ -24,7 +29,11 @@ func init() {
 	create.PersistentFlags().BoolVarP(&monitor, "monitor", "m", true, "set monitor value [true|false] (default: true)")
 	create.PersistentFlags().BoolVarP(&enable, "enable", "e", true, "set enable value [true|false] (default: true)")
 	createHostVar.Flags().StringVar(&ip, "ip", "", "set host ip")
-	createHostVar.Flags().StringVarP(&group, "group", "g", "", "default host group")
+	createHostVar.Flags().StringSliceVarP(&directGroups, "group", "g", nil,
+		"host direct groups, repeat the flag or comma separate to set multiple groups")
+	createHostVar.Flags().StringSliceVar(&addresses, "address", nil,
+		"add or update host address as name=address, repeat the flag or comma separate to set multiple addresses")
+	createHostVar.Flags().StringVar(&primary, "primary", "", "set the host address with this name as primary")
 
 	create.AddCommand(createHostVar)
 	create.AddCommand(createGroupVar)
@@ -45,26 +54,34 @@ var createHostVar = &cobra.Command{
 	Long: "create new host or modify existing one, expecting argument host hostname/fqdn as the host to create or edit" +
 		"the new or edited host would open in your favorite editor as editable json",
 	Example: "admiral create host new-host\nadmiral create" +
-		" host new-host.domain.com\nadmiral edit host existing-host",
+		" host new-host.domain.com\nadmiral edit host existing-host\nadmiral create host new-host -g web -g dc1\n" +
+		"admiral create host new-host --address management=10.0.0.1 --address public=1.2.3.4 --primary public",
 	ValidArgsFunction: hostsArgsFunc,
 	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := createHostCase(args); err != nil {
+		if err := createHostCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func createHostCase(args []string) error {
+func createHostCase(ctx context.Context, args []string) error {
 	var hosts datastructs.Hosts
 
 	var err error
 
-	hosts, err = returnHosts(args[0])
+	hosts, err = returnHosts(ctx, args[0])
 	if err != nil {
 		return err
 	}
 
+	if err = loadAddresses(ctx, DB, hosts); err != nil {
+		return err
+	}
+
+	// the hosts as read, shown next to the stored and edited hosts if someone else changed them meanwhile
+	base := append(datastructs.Hosts(nil), hosts...)
+
 	enableF := create.Flag("enable")
 	if enableF.Changed {
 		for i := range hosts {
@@ -80,6 +97,10 @@ func createHostCase(args []string) error {
 	}
 
 	if ip != "" {
+		if _, err = datastructs.ParseIP(ip); err != nil {
+			return err
+		}
+
 		if len(hosts) == 1 {
 			hosts[0].Host = ip
 		} else {
@@ -87,39 +108,58 @@ func createHostCase(args []string) error {
 		}
 	}
 
-	if group != "" {
+	if len(directGroups) > 0 {
 		for i := range hosts {
-			hosts[i].DirectGroup = group
+			hosts[i].DirectGroups = datastructs.NewGroupNames(directGroups)
 			hosts[i].InheritedGroups = ""
 		}
 	}
 
-	if !enableF.Changed && !monitorF.Changed && ip == "" && group == "" {
+	if len(addresses) > 0 || primary != "" {
+		if len(hosts) != 1 {
+			return fmt.Errorf("cannot set host addresses, too many host matches")
+		}
+
+		hosts[0].Addresses, err = setAddresses(hosts[0].Addresses, addresses, primary)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !enableF.Changed && !monitorF.Changed && ip == "" && len(directGroups) == 0 && len(addresses) == 0 &&
+		primary == "" {
 		hosts, err = editHosts(&hosts)
 		if err != nil {
 			return err
 		}
 	}
 
-	printHosts(hosts)
+	for {
+		printHosts(hosts)
 
-	if accept || User.confirm() {
-		err = confirmedHosts(&hosts)
+		if !accept && !User.confirm() {
+			return fmt.Errorf("aborted")
+		}
+
+		err = inTx(ctx, func(tx database.Querier) error {
+			return confirmedHosts(ctx, tx, &hosts)
+		})
+		if !errors.Is(err, datastructs.ErrRevisionConflict) {
+			return err
+		}
+
+		base, hosts, err = resolveHostsConflict(ctx, base, hosts, err)
 		if err != nil {
 			return err
 		}
-	} else {
-		return fmt.Errorf("aborted")
 	}
-
-	return nil
 }
 
 // returnHosts return existing records or list of hosts with one new record
-func returnHosts(val string) (hosts []datastructs.Host, err error) {
+func returnHosts(ctx context.Context, val string) (hosts []datastructs.Host, err error) {
 	fqdn := strings.SplitN(val, ".", 2)
 
-	hosts, err = scanHosts(fqdn[0])
+	hosts, err = scanHosts(ctx, DB, fqdn[0])
 	if err != nil {
 		return hosts, err
 	}
@@ -204,62 +244,173 @@ func editHosts(hosts *datastructs.Hosts) (returnHosts datastructs.Hosts, err err
 	return unmarshalHosts(modifiedHostB)
 }
 
-// nolint: gocognit
-func confirmedHosts(hostsToCreate *datastructs.Hosts) (err error) {
+// setAddresses return addresses with the name=address values added or updated and the address named primary
+// set as the only primary address
+func setAddresses(addresses datastructs.Addresses, values []string, primary string) (
+	datastructs.Addresses, error) {
+	updated := append(datastructs.Addresses{}, addresses...)
+
+	for _, val := range values {
+		pair := strings.SplitN(val, "=", 2)
+		if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return nil, fmt.Errorf("invalid address %v, expecting name=address", val)
+		}
+
+		i := 0
+		for i < len(updated) && updated[i].Name != pair[0] {
+			i++
+		}
+
+		if i == len(updated) {
+			updated = append(updated, datastructs.Address{Name: pair[0]})
+		}
+
+		updated[i].Address = pair[1]
+	}
+
+	if primary == "" {
+		return updated, nil
+	}
+
+	if _, ok := updated.Get(primary); !ok {
+		return nil, fmt.Errorf("no address named %v", primary)
+	}
+
+	for i := range updated {
+		updated[i].Primary = updated[i].Name == primary
+	}
+
+	return updated, nil
+}
+
+func confirmedHosts(ctx context.Context, db database.Querier, hostsToCreate *datastructs.Hosts) (err error) {
 	hosts := *hostsToCreate
 	for i := range hosts {
-		var group datastructs.Group
+		if err = hosts[i].Addresses.Validate(); err != nil {
+			return err
+		}
 
-		err = createHost(&hosts[i])
+		err = createHost(ctx, db, &hosts[i])
 		if err != nil && err.Error() != "no lines affected" {
 			return err
 		}
 
-		if hosts[i].DirectGroup == "" {
-			log.Println("created host without group. please make sure to add the host to default group")
-		} else {
-			group, err = viewGroupByName(hosts[i].DirectGroup)
-			if err != nil {
-				return err
-			}
+		var created datastructs.Host
 
-			var existingHostGroup []datastructs.HostGroup
+		// retrieving the created host to get its ID
+		created, err = db.SelectHostContext(ctx, hosts[i].Hostname)
+		if err != nil {
+			return err
+		}
 
-			// if host already got host-group relationship first delete it
-			existingHostGroup, err = viewHostGroupByHost(hosts[i].Hostname)
-			if err != nil && err.Error() != "no record matched request" {
+		// hosts without addresses list (e.g. imported from file without addresses) keep their addresses
+		if hosts[i].Addresses != nil {
+			if err = setHostAddresses(ctx, db, &created, hosts[i].Addresses); err != nil {
 				return err
-			} else if existingHostGroup != nil {
-				_, err = deleteHostGroup(&existingHostGroup[0])
-				if err != nil {
-					return err
-				}
 			}
+		}
 
-			var created datastructs.Hosts
+		if len(hosts[i].DirectGroups) == 0 {
+			log.Println("created host without group. please make sure to add the host to default group")
+			continue
+		}
 
-			// retrieving the created host to get its ID
-			created, err = scanHosts(hosts[i].Hostname)
-			if err != nil {
-				return err
-			}
+		if err = setHostGroups(ctx, db, &created, hosts[i].DirectGroups); err != nil {
+			return err
+		}
+	}
 
-			err = createHostGroup(&created[0], &group)
-			if err != nil && !strings.Contains(err.Error(), "Duplicate entry") {
-				return err
-			}
+	return nil
+}
+
+// setHostAddresses add or update the listed host addresses and remove the addresses not listed
+func setHostAddresses(ctx context.Context, db database.Querier, host *datastructs.Host,
+	addresses datastructs.Addresses) error {
+	existing, err := db.SelectAddressesContext(ctx, host.Hostname)
+	if err != nil {
+		return err
+	}
+
+	for i := range existing {
+		if _, ok := addresses.Get(existing[i].Name); ok {
+			continue
+		}
+
+		if _, err = db.DeleteAddressContext(ctx, &existing[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, address := range addresses {
+		if current, ok := existing.Get(address.Name); ok && current.Address == address.Address &&
+			current.Primary == address.Primary {
+			continue
+		}
+
+		address.HostID = host.ID
+		address.Host = host.Hostname
+
+		if _, err = db.InsertAddressContext(ctx, &address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setHostGroups add the host to the groups it is not yet a member of and remove it from the groups not listed
+func setHostGroups(ctx context.Context, db database.Querier, host *datastructs.Host,
+	groups datastructs.GroupNames) error {
+	existing, err := db.SelectHostGroupContext(ctx, host.Hostname)
+	if err != nil {
+		return err
+	}
+
+	member := make(map[string]bool)
+
+	for i := range existing {
+		if groups.Contains(existing[i].Group) {
+			member[existing[i].Group] = true
+			continue
+		}
+
+		if _, err = deleteHostGroup(ctx, db, &existing[i]); err != nil {
+			return err
 		}
 	}
 
-	return err
+	for _, name := range groups {
+		if member[name] {
+			continue
+		}
+
+		group, err := viewGroupByName(ctx, db, name)
+		if err != nil {
+			return err
+		}
+
+		if err = createHostGroup(ctx, db, host, &group); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func createHost(host *datastructs.Host) error {
+func createHost(ctx context.Context, db database.Querier, host *datastructs.Host) error {
 	if host.Hostname == "" || host.Host == "" {
 		return fmt.Errorf("missing mandatory field ip or hostname")
 	}
 
-	i, err := DB.InsertHost(host)
+	addr, err := datastructs.ParseIP(host.Host)
+	if err != nil {
+		return err
+	}
+
+	// store the ip in its canonical form so it is found and sorted the same way regardless of how it was typed
+	host.Host = addr.String()
+
+	i, err := db.InsertHostContext(ctx, host)
 	if err != nil {
 		return err
 	} else if i == 0 {
@@ -269,8 +420,9 @@ func createHost(host *datastructs.Host) error {
 	return nil
 }
 
-func viewHostGroupByHost(host string) (hostGroup []datastructs.HostGroup, err error) {
-	hostGroup, err = DB.SelectHostGroup(host)
+func viewHostGroupByHost(ctx context.Context, db database.Querier, host string) (
+	hostGroup []datastructs.HostGroup, err error) {
+	hostGroup, err = db.SelectHostGroupContext(ctx, host)
 	if err != nil {
 		return hostGroup, err
 	} else if hostGroup == nil {
@@ -280,8 +432,9 @@ func viewHostGroupByHost(host string) (hostGroup []datastructs.HostGroup, err er
 	return hostGroup, nil
 }
 
-func deleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
-	affected, err = DB.DeleteHostGroup(hostGroup)
+func deleteHostGroup(ctx context.Context, db database.Querier, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	affected, err = db.DeleteHostGroupContext(ctx, hostGroup)
 	if err != nil {
 		return affected, err
 	} else if affected == 0 {
@@ -291,13 +444,15 @@ func deleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err erro
 	return affected, nil
 }
 
-func createHostGroup(host *datastructs.Host, group *datastructs.Group) error {
+func createHostGroup(ctx context.Context, db database.Querier, host *datastructs.Host, group *datastructs.Group) error {
 	hostGroup := &datastructs.HostGroup{
 		HostID:  host.ID,
+		Host:    host.Hostname,
 		GroupID: group.ID,
+		Group:   group.Name,
 	}
 
-	i, err := DB.InsertHostGroup(hostGroup)
+	i, err := db.InsertHostGroupContext(ctx, hostGroup)
 	if err != nil {
 		return err
 	} else if i == 0 {
@@ -316,18 +471,18 @@ var createGroupVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := createGroupCase(args); err != nil {
+		if err := createGroupCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func createGroupCase(args []string) error {
+func createGroupCase(ctx context.Context, args []string) error {
 	var group datastructs.Group
 
 	var err error
 
-	group, err = viewGroupByName(args[0])
+	group, err = viewGroupByName(ctx, DB, args[0])
 	if err != nil {
 		if err.Error() == "requested group does not exists" {
 			group = Conf.NewDefaultGroup()
@@ -337,6 +492,9 @@ func createGroupCase(args []string) error {
 		}
 	}
 
+	// the group as read, shown next to the stored and edited group if someone else changed it meanwhile
+	base := group
+
 	enableF := create.Flag("enable")
 	if enableF.Changed {
 		group.Enabled = enable
@@ -354,18 +512,25 @@ func createGroupCase(args []string) error {
 		}
 	}
 
-	printGroups([]datastructs.Group{group})
+	for {
+		printGroups([]datastructs.Group{group})
+
+		if !accept && !User.confirm() {
+			return fmt.Errorf("aborted")
+		}
+
+		err = inTx(ctx, func(tx database.Querier) error {
+			return createGroup(ctx, tx, &group)
+		})
+		if !errors.Is(err, datastructs.ErrRevisionConflict) {
+			return err
+		}
 
-	if accept || User.confirm() {
-		err := createGroup(&group)
+		base, group, err = resolveGroupConflict(ctx, base, group, err)
 		if err != nil {
 			return err
 		}
-	} else {
-		return fmt.Errorf("aborted")
 	}
-
-	return nil
 }
 
 func unmarshalGroups(group *datastructs.Group) (b []byte, err error) {
@@ -408,12 +573,12 @@ func editGroup(group *datastructs.Group) (returnGroup datastructs.Group, err err
 	return returnGroup, err
 }
 
-func createGroup(group *datastructs.Group) error {
+func createGroup(ctx context.Context, db database.Querier, group *datastructs.Group) error {
 	if group.Name == "" {
 		return fmt.Errorf("missing mandatory field name")
 	}
 
-	i, err := DB.InsertGroup(group)
+	i, err := db.InsertGroupContext(ctx, group)
 	if err != nil {
 		return err
 	} else if i == 0 {
@@ -432,29 +597,29 @@ var createChildVar = &cobra.Command{
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: groupsArgsFunc,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := createChildCase(args); err != nil {
+		if err := createChildCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func createChildCase(args []string) error {
+func createChildCase(ctx context.Context, args []string) error {
 	var childGroups []datastructs.ChildGroup
 
 	var err error
 
 	// check if relationship already exists
-	childGroups, _ = viewChildGroup(args[0], args[1])
+	childGroups, _ = viewChildGroup(ctx, args[0], args[1])
 	if len(childGroups) != 0 {
 		return fmt.Errorf("Group relationship already exists")
 	}
 
-	child, err := viewGroupByName(args[0])
+	child, err := viewGroupByName(ctx, DB, args[0])
 	if err != nil {
 		return err
 	}
 
-	parent, err := viewGroupByName(args[1])
+	parent, err := viewGroupByName(ctx, DB, args[1])
 	if err != nil {
 		return err
 	}
@@ -471,7 +636,9 @@ func createChildCase(args []string) error {
 	printChildGroups(childGroups)
 
 	if User.confirm() {
-		err = createChildGroup(&parent, &child)
+		err = inTx(ctx, func(tx database.Querier) error {
+			return createChildGroup(ctx, tx, &parent, &child)
+		})
 		if err != nil {
 			return err
 		}
@@ -482,7 +649,8 @@ func createChildCase(args []string) error {
 	return nil
 }
 
-func createChildGroup(parent *datastructs.Group, child *datastructs.Group) error {
+func createChildGroup(ctx context.Context, db database.Querier, parent *datastructs.Group,
+	child *datastructs.Group) error {
 	if child.ID == parent.ID {
 		return fmt.Errorf("child and parent cannot be the same group")
 	}
@@ -494,10 +662,12 @@ func createChildGroup(parent *datastructs.Group, child *datastructs.Group) error
 
 	childGroup := &datastructs.ChildGroup{
 		ParentID: parent.ID,
+		Parent:   parent.Name,
 		ChildID:  child.ID,
+		Child:    child.Name,
 	}
 
-	i, err := DB.InsertChildGroup(childGroup)
+	i, err := db.InsertChildGroupContext(ctx, childGroup)
 	if err != nil {
 		return err
 	} else if i == 0 {
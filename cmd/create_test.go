@@ -2,6 +2,8 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"reflect"
 	"testing"
 
@@ -35,7 +37,7 @@ func Test_createHostCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createHostCase(tt.args); (err != nil) != tt.wantErr {
+			if err := createHostCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("createHostCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -48,8 +50,8 @@ func Example_updateHostFromFlags() {
 
 	executeCommand(rootCmd, "create", "host", "host3", "-e=true", "-m=false", "-g", "group2")
 	// output:
-	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups
-	// 3.3.3.3      |host3        |domain.local |true         |false        |group2       |
+	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups |Created              |Updated
+	// 3.3.3.3      |host3        |domain.local |true         |false        |group2       |                 |2020-12-01T10:00:00Z |2020-12-01T10:00:00Z
 }
 
 func Example_createHostCaseFromFlags() {
@@ -58,8 +60,8 @@ func Example_createHostCaseFromFlags() {
 
 	executeCommand(rootCmd, "create", "host", "host11.domain.com", "-e=true", "-m=false", "-g", "group2", "--ip", "11.11.11.11")
 	// output:
-	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups
-	// 11.11.11.11  |host11       |domain.com   |true         |false        |group2       |
+	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups |Created      |Updated
+	// 11.11.11.11  |host11       |domain.com   |true         |false        |group2       |                 |             |
 }
 
 var emptyHost10 = `{
@@ -69,7 +71,7 @@ var emptyHost10 = `{
   "variables": {},
   "enable": true,
   "monitor": true,
-  "direct_group": ""
+  "direct_groups": []
 }`
 
 var testHost1Edit = `{
@@ -83,7 +85,9 @@ var testHost1Edit = `{
   },
   "enable": true,
   "monitor": true,
-  "direct_group": "group1"
+  "direct_groups": [
+    "group1"
+  ]
 }`
 
 func Test_returnHosts(t *testing.T) {
@@ -127,7 +131,7 @@ func Test_returnHosts(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHosts, err := returnHosts(tt.args.val)
+			gotHosts, err := returnHosts(context.Background(), tt.args.val)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("returnHosts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -156,7 +160,7 @@ func Test_confirmedHost(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := confirmedHosts(tt.args.host); (err != nil) != tt.wantErr {
+			if err := confirmedHosts(context.Background(), DB, tt.args.host); (err != nil) != tt.wantErr {
 				t.Errorf("confirmedHosts() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -204,7 +208,7 @@ func Test_viewHostByHostname(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHost, err := viewHostByHostname(tt.args.hostname)
+			gotHost, err := viewHostByHostname(context.Background(), tt.args.hostname)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("viewHostByHostname() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -217,14 +221,14 @@ func Test_viewHostByHostname(t *testing.T) {
 }
 
 var testHost10 = datastructs.Host{
-	ID:          10,
-	Hostname:    "host10",
-	Host:        "10.10.10.10",
-	Domain:      "local",
-	Variables:   "{\"var10\": \"val10\"}",
-	Enabled:     true,
-	Monitored:   true,
-	DirectGroup: "group1",
+	ID:           10,
+	Hostname:     "host10",
+	Host:         "10.10.10.10",
+	Domain:       "local",
+	Variables:    "{\"var10\": \"val10\"}",
+	Enabled:      true,
+	Monitored:    true,
+	DirectGroups: datastructs.GroupNames{"group1"},
 }
 
 func Test_createHost(t *testing.T) {
@@ -282,10 +286,24 @@ func Test_createHost(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Invalid host ip",
+			args: args{
+				host: &datastructs.Host{Hostname: "host11", Host: "1.1.1", Variables: "{}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "IPv6 host ip",
+			args: args{
+				host: &datastructs.Host{Hostname: "host12", Host: "2001:DB8::12", Variables: "{}"},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createHost(tt.args.host); (err != nil) != tt.wantErr {
+			if err := createHost(context.Background(), DB, tt.args.host); (err != nil) != tt.wantErr {
 				t.Errorf("createHost() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -325,7 +343,7 @@ func Test_viewHostGroupByHost(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHostGroup, err := viewHostGroupByHost(tt.args.host)
+			gotHostGroup, err := viewHostGroupByHost(context.Background(), DB, tt.args.host)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("viewHostGroupByHost() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -370,7 +388,7 @@ func Test_deleteHostGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAffected, err := deleteHostGroup(tt.args.hostGroup)
+			gotAffected, err := deleteHostGroup(context.Background(), DB, tt.args.hostGroup)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("deleteHostGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -400,18 +418,26 @@ func Test_createHostGroup(t *testing.T) {
 			name: "Insert New",
 			args: args{
 				host:  &testHost3,
-				group: &testGroup3,
+				group: &testGroup1,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Insert Update group",
+			name: "Insert additional group",
 			args: args{
 				host:  &testHost1,
 				group: &testGroup2,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Insert existing membership",
+			args: args{
+				host:  &testHost1,
+				group: &testGroup1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "Insert none-existing host",
 			args: args{
@@ -423,13 +449,59 @@ func Test_createHostGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createHostGroup(tt.args.host, tt.args.group); (err != nil) != tt.wantErr {
+			if err := createHostGroup(context.Background(), DB, tt.args.host, tt.args.group); (err != nil) != tt.wantErr {
 				t.Errorf("createHostGroup() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func Test_setHostGroups(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	tests := []struct {
+		name    string
+		groups  datastructs.GroupNames
+		want    datastructs.GroupNames
+		wantErr bool
+	}{
+		{
+			name:   "add groups",
+			groups: datastructs.GroupNames{"group1", "group2", "group3"},
+			want:   datastructs.GroupNames{"group1", "group2", "group3"},
+		},
+		{
+			name:   "remove groups",
+			groups: datastructs.GroupNames{"group2"},
+			want:   datastructs.GroupNames{"group2"},
+		},
+		{
+			name:    "none-existing group",
+			groups:  datastructs.GroupNames{"group2", "group10"},
+			want:    datastructs.GroupNames{"group2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := setHostGroups(context.Background(), DB, &testHost1, tt.groups); (err != nil) != tt.wantErr {
+				t.Errorf("setHostGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			host, err := DB.SelectHost(testHost1.Hostname)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(host.DirectGroups, tt.want) {
+				t.Errorf("setHostGroups() direct groups = %v, want %v", host.DirectGroups, tt.want)
+			}
+		})
+	}
+}
+
 func Test_createGroupCase(t *testing.T) {
 	testDB := prepEnv()
 
@@ -448,13 +520,143 @@ func Test_createGroupCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createGroupCase(tt.args); (err != nil) != tt.wantErr {
+			if err := createGroupCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("createGroupCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+// conflictUser disable the edited record and count the edits, before the first edit returns change
+// store a concurrent change of the record as a colleague saving it meanwhile would
+type conflictUser struct {
+	testUser
+	edits  *int
+	change func()
+}
+
+func (u conflictUser) Edit(data []byte) ([]byte, error) {
+	*u.edits++
+	if *u.edits == 1 {
+		u.change()
+	}
+
+	return bytes.Replace(data, []byte(`"enable": true`), []byte(`"enable": false`), 1), nil
+}
+
+func Test_createHostCase_conflict(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	edits := 0
+	User = conflictUser{edits: &edits, change: func() {
+		host1, _ := testDB.SelectHost("host1")
+		host1.Domain = "domain.com"
+		if _, err := testDB.InsertHost(&host1); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	defer func() { User = testUser{} }()
+
+	if err := createHostCase(context.Background(), []string{"host1"}); err != nil {
+		t.Fatalf("createHostCase() error = %v", err)
+	}
+
+	if edits != 2 {
+		t.Errorf("createHostCase() edits = %v, want the host edited again after the conflict", edits)
+	}
+
+	if got, _ := testDB.SelectHost("host1"); got.Enabled || got.Revision != 3 {
+		t.Errorf("SelectHost() = %+v, want host1 disabled at revision 3", got)
+	}
+}
+
+func Test_createHostCase_groupsConflict(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	edits := 0
+	User = conflictUser{edits: &edits, change: func() {
+		// only the groups of the host change
+		if _, err := testDB.InsertHostGroup(&datastructs.HostGroup{HostID: 1, GroupID: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	defer func() { User = testUser{} }()
+
+	if err := createHostCase(context.Background(), []string{"host1"}); err != nil {
+		t.Fatalf("createHostCase() error = %v", err)
+	}
+
+	if edits != 2 {
+		t.Errorf("createHostCase() edits = %v, want the host edited again after the conflict", edits)
+	}
+
+	if got, _ := testDB.SelectHost("host1"); got.Enabled || got.Revision != 4 {
+		t.Errorf("SelectHost() = %+v, want host1 disabled at revision 4", got)
+	}
+}
+
+func Test_createGroupCase_conflict(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	edits := 0
+	User = conflictUser{edits: &edits, change: func() {
+		group1, _ := testDB.SelectGroup("group1")
+		group1.Monitored = false
+		if _, err := testDB.InsertGroup(&group1); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	defer func() { User = testUser{} }()
+
+	if err := createGroupCase(context.Background(), []string{"group1"}); err != nil {
+		t.Fatalf("createGroupCase() error = %v", err)
+	}
+
+	if edits != 2 {
+		t.Errorf("createGroupCase() edits = %v, want the group edited again after the conflict", edits)
+	}
+
+	if got, _ := testDB.SelectGroup("group1"); got.Enabled || got.Revision != 3 {
+		t.Errorf("SelectGroup() = %+v, want group1 disabled at revision 3", got)
+	}
+}
+
+func Test_fieldChanges(t *testing.T) {
+	read := testHost1
+	stored := testHost1
+	stored.Domain = "domain.com"
+	stored.Revision = 2
+	yours := testHost1
+	yours.Enabled = false
+
+	got, err := fieldChanges(&read, &stored, &yours)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"domain", `"domain.local"`, `"domain.com"`, `"domain.local"`},
+		{"enable", "true", "true", "false"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fieldChanges() = %v, want %v", got, want)
+	}
+
+	// a record deleted meanwhile has no stored values
+	if got, _ = fieldChanges(&read, nil, &yours); len(got) == 0 || got[0][2] != "-" {
+		t.Errorf("fieldChanges() = %v, want - for the missing stored values", got)
+	}
+}
+
 var emptyGroup10 = `{
   "name": "group10",
   "variables": {},
@@ -470,7 +672,10 @@ var testGroup1Edit = `{
     }
   },
   "enable": true,
-  "monitor": true
+  "monitor": true,
+  "created": "2020-11-01T10:00:00Z",
+  "updated": "2020-11-01T10:00:00Z",
+  "revision": 1
 }`
 
 func Test_unmarshalGroups(t *testing.T) {
@@ -574,7 +779,7 @@ func Test_createGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createGroup(tt.args.group); (err != nil) != tt.wantErr {
+			if err := createGroup(context.Background(), DB, tt.args.group); (err != nil) != tt.wantErr {
 				testGroup1 = tmpGroup
 				t.Errorf("createGroup() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -621,7 +826,7 @@ func Test_createChildCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createChildCase(tt.args); (err != nil) != tt.wantErr {
+			if err := createChildCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("createChildCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -685,9 +890,148 @@ func Test_createChildGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createChildGroup(tt.args.parent, tt.args.child); (err != nil) != tt.wantErr {
+			if err := createChildGroup(context.Background(), DB, tt.args.parent, tt.args.child); (err != nil) != tt.wantErr {
 				t.Errorf("createChildGroup() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_setAddresses(t *testing.T) {
+	existing := datastructs.Addresses{{Name: "public", Address: "1.1.1.1", Primary: true}}
+
+	tests := []struct {
+		name    string
+		values  []string
+		primary string
+		want    datastructs.Addresses
+		wantErr bool
+	}{
+		{
+			name:   "add address",
+			values: []string{"management=10.0.0.1"},
+			want: datastructs.Addresses{{Name: "public", Address: "1.1.1.1", Primary: true},
+				{Name: "management", Address: "10.0.0.1"}},
+		},
+		{
+			name:   "update address",
+			values: []string{"public=2.2.2.2"},
+			want:   datastructs.Addresses{{Name: "public", Address: "2.2.2.2", Primary: true}},
+		},
+		{
+			name:    "change primary",
+			values:  []string{"management=10.0.0.1"},
+			primary: "management",
+			want: datastructs.Addresses{{Name: "public", Address: "1.1.1.1"},
+				{Name: "management", Address: "10.0.0.1", Primary: true}},
+		},
+		{
+			name:    "invalid value",
+			values:  []string{"management"},
+			wantErr: true,
+		},
+		{
+			name:    "none-existing primary",
+			primary: "storage",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setAddresses(existing, tt.values, tt.primary)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("setAddresses() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !existing[0].Primary || existing[0].Address != "1.1.1.1" {
+		t.Errorf("setAddresses() modified the original addresses %v", existing)
+	}
+}
+
+func Test_setHostAddresses(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	tests := []struct {
+		name      string
+		addresses datastructs.Addresses
+	}{
+		{
+			name: "add addresses",
+			addresses: datastructs.Addresses{{Name: "management", Address: "10.0.0.1"},
+				{Name: "public", Address: "1.1.1.1", Primary: true}},
+		},
+		{
+			name: "update and remove addresses",
+			addresses: datastructs.Addresses{{Name: "management", Address: "10.0.0.2", Primary: true},
+				{Name: "storage", Address: "192.168.0.1"}},
+		},
+		{
+			name:      "remove all",
+			addresses: datastructs.Addresses{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := setHostAddresses(context.Background(), DB, &testHost1, tt.addresses); err != nil {
+				t.Fatalf("setHostAddresses() error = %v", err)
+			}
+
+			got, err := DB.SelectAddresses(testHost1.Hostname)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.addresses) {
+				t.Fatalf("setHostAddresses() addresses = %v, want %v", got, tt.addresses)
+			}
+
+			for _, want := range tt.addresses {
+				if address, ok := got.Get(want.Name); !ok || address.Address != want.Address ||
+					address.Primary != want.Primary {
+					t.Errorf("setHostAddresses() addresses = %v, want %v", got, tt.addresses)
+				}
+			}
+		})
+	}
+}
+
+func Test_createHost_canonicalIP(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	host := datastructs.Host{Hostname: "host12", Host: "2001:DB8:0::12", Variables: "{}"}
+	if err := createHost(context.Background(), DB, &host); err != nil {
+		t.Fatalf("createHost() error = %v", err)
+	}
+
+	if got, _ := DB.SelectHost("host12"); got.Host != "2001:db8::12" {
+		t.Errorf("createHost() ip = %v, want 2001:db8::12", got.Host)
+	}
+}
+
+func Test_sortHostsByIP(t *testing.T) {
+	hosts := datastructs.Hosts{{Host: "10.0.0.10"}, {Host: "not-an-ip"}, {Host: "2001:db8::1"}, {Host: "10.0.0.9"}}
+
+	if err := hosts.Sort("ip"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for i := range hosts {
+		got = append(got, hosts[i].Host)
+	}
+
+	if want := []string{"10.0.0.9", "10.0.0.10", "2001:db8::1", "not-an-ip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Hosts.Sort() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var copyDryRun bool
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateVar)
+	dbCmd.AddCommand(dbStatusVar)
+	dbCmd.AddCommand(dbCopyVar)
+	dbCopyVar.Flags().BoolVar(&copyDryRun, "dry-run", false, "only show what would be copied")
+}
+
+var dbCmd = &cobra.Command{
+	Use:       "db",
+	ValidArgs: []string{"migrate", "status", "copy"},
+	Short:     "manage the database scheme",
+}
+
+var dbMigrateVar = &cobra.Command{
+	Use:   "migrate",
+	Short: "apply pending database scheme migrations",
+	Long: "apply all pending scheme migrations to the configured database in order." +
+		" Use it to initialize a new database or after upgrading admiral",
+	Example: "admiral db migrate",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dbMigrate(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func dbMigrate() error {
+	applied, err := migrateSchema()
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("database scheme is up to date")
+		return nil
+	}
+
+	printMigrations(applied)
+
+	return nil
+}
+
+var dbStatusVar = &cobra.Command{
+	Use:     "status",
+	Short:   "view the database scheme version",
+	Long:    "view all scheme migrations known to admiral and when they were applied to the configured database",
+	Example: "admiral db status",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := schemaStatus()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printMigrations(status)
+	},
+}
+
+func migrator() (database.Migrator, error) {
+	m, ok := DB.(database.Migrator)
+	if !ok {
+		return nil, database.ErrMigrationsUnsupported
+	}
+
+	return m, nil
+}
+
+func migrateSchema() (applied []datastructs.Migration, err error) {
+	m, err := migrator()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Migrate()
+}
+
+func schemaStatus() (status []datastructs.Migration, err error) {
+	m, err := migrator()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.MigrationStatus()
+}
+
+// warnPendingMigrations let the user know the database scheme is older than the binary
+func warnPendingMigrations() {
+	status, err := schemaStatus()
+	if err != nil {
+		return
+	}
+
+	for _, m := range status {
+		if m.AppliedAt == "" {
+			log.Println("database scheme is not up to date, please run `admiral db migrate`")
+			return
+		}
+	}
+}
+
+var dbCopyVar = &cobra.Command{
+	Use:   "copy source destination",
+	Short: "copy the inventory to another database backend",
+	Long: "copy the groups, child-group relationships, hosts, host-group memberships and addresses from the source" +
+		" to the destination backend, both named by their section in the config file (" +
+		strings.Join(config.Backends, ", ") + "). Pending scheme migrations are applied to the destination which" +
+		" must hold no hosts or groups. The copy is rolled back if the number of copied records differ from the" +
+		" source. Hosts and groups in the trash and the audit log are not copied",
+	Example:   "admiral db copy sqlite mariadb --dry-run\nadmiral db copy sqlite mariadb",
+	ValidArgs: config.Backends,
+	Args:      cobra.ExactValidArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dbCopy(cmd.Context(), args[0], args[1]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// inventoryCounts is the number of records of each kind in an inventory
+type inventoryCounts struct {
+	Groups      int
+	ChildGroups int
+	Hosts       int
+	HostGroups  int
+	Addresses   int
+}
+
+func dbCopy(ctx context.Context, source, destination string) error {
+	if source == destination {
+		return fmt.Errorf("source and destination are the same backend")
+	}
+
+	src, err := connectBackend(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	defer src.Close() // nolint: errcheck
+
+	dst, err := connectBackend(ctx, destination)
+	if err != nil {
+		return err
+	}
+
+	defer dst.Close() // nolint: errcheck
+
+	want, err := countInventory(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	pending, err := pendingMigrations(dst)
+	if err != nil {
+		return err
+	}
+
+	if copyDryRun {
+		if len(pending) > 0 {
+			fmt.Println("scheme migrations to apply to the destination:")
+			printMigrations(pending)
+		} else if err = checkEmpty(ctx, dst, destination); err != nil {
+			return err
+		}
+
+		printInventoryCounts([]string{"Source"}, want)
+		fmt.Println("dry run, nothing was copied")
+
+		return nil
+	}
+
+	if len(pending) > 0 {
+		if _, err = dst.(database.Migrator).Migrate(); err != nil {
+			return err
+		}
+	}
+
+	if err = checkEmpty(ctx, dst, destination); err != nil {
+		return err
+	}
+
+	return inTxOf(ctx, dst, func(tx database.Querier) error {
+		if err := copyInventory(ctx, src, tx); err != nil {
+			return err
+		}
+
+		got, err := countInventory(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		printInventoryCounts([]string{"Source", "Copied"}, want, got)
+
+		if got != want {
+			return fmt.Errorf("the number of copied records differ from the source, the copy was rolled back")
+		}
+
+		return nil
+	})
+}
+
+// connectBackend connect to the backend configured in the named section of the config file
+func connectBackend(ctx context.Context, name string) (database.DBInterface, error) {
+	conf, err := Conf.Backend(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return database.ConnectContext(ctx, conf)
+}
+
+// pendingMigrations return the scheme migrations not yet applied to db, none for backends without scheme
+func pendingMigrations(db database.DBInterface) (pending []datastructs.Migration, err error) {
+	m, ok := db.(database.Migrator)
+	if !ok {
+		return nil, nil
+	}
+
+	status, err := m.MigrationStatus()
+	if errors.Is(err, database.ErrMigrationsUnsupported) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range status {
+		if migration.AppliedAt == "" {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// checkEmpty return error if db holds hosts or groups, including the ones in the trash
+func checkEmpty(ctx context.Context, db database.Querier, name string) error {
+	hosts, err := db.GetHostsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	groups, err := db.GetGroupsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	deletedHosts, err := db.GetDeletedHostsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	deletedGroups, err := db.GetDeletedGroupsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if numHosts, numGroups := len(hosts)+len(deletedHosts), len(groups)+len(deletedGroups); numHosts+numGroups > 0 {
+		return fmt.Errorf("destination %v is not empty, it holds %v hosts and %v groups", name, numHosts, numGroups)
+	}
+
+	return nil
+}
+
+func countInventory(ctx context.Context, db database.Querier) (counts inventoryCounts, err error) {
+	groups, err := db.GetGroupsContext(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	childGroups, err := db.GetChildGroupsContext(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	hosts, err := db.GetHostsContext(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	hostGroups, err := db.GetHostGroupsContext(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	addresses, err := db.GetAddressesContext(ctx)
+	if err != nil {
+		return counts, err
+	}
+
+	return inventoryCounts{Groups: len(groups), ChildGroups: len(childGroups), Hosts: len(hosts),
+		HostGroups: len(hostGroups), Addresses: len(addresses)}, nil
+}
+
+// copyInventory insert the records of src to dst by name, the IDs of the copied records are the ones
+// given by dst
+func copyInventory(ctx context.Context, src database.Querier, dst database.Querier) error {
+	dump, err := dumpInventory(ctx, src, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return loadInventory(ctx, dst, &dump)
+}
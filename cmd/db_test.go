@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func Test_schemaStatus(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	status, err := schemaStatus()
+	if err != nil {
+		t.Fatalf("schemaStatus() error = %v", err)
+	}
+
+	if len(status) == 0 {
+		t.Fatalf("schemaStatus() returned no migrations")
+	}
+
+	for _, m := range status {
+		if m.AppliedAt == "" {
+			t.Errorf("schemaStatus() migration %v is pending, want all applied on connect", m.Version)
+		}
+	}
+}
+
+func Test_migrateSchema(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	applied, err := migrateSchema()
+	if err != nil {
+		t.Fatalf("migrateSchema() error = %v", err)
+	}
+
+	if len(applied) != 0 {
+		t.Errorf("migrateSchema() = %v, want nothing to apply", applied)
+	}
+}
+
+func Test_dbCopy(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	if _, err := testDB.InsertAddress(&datastructs.Address{HostID: testHost1.ID, Name: "public",
+		Address: "1.1.1.1", Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := testConf
+	conf.SQLite = dbConfig
+	conf.Files = config.FilesConfig{Path: t.TempDir()}
+	Conf = &conf
+
+	defer func() { Conf = &testConf }()
+
+	if err := dbCopy(context.Background(), "sqlite", "sqlite"); err == nil {
+		t.Errorf("dbCopy() error = nil, want error for the same source and destination")
+	}
+
+	copyDryRun = true
+	if err := dbCopy(context.Background(), "sqlite", "files"); err != nil {
+		t.Fatalf("dbCopy() dry run error = %v", err)
+	}
+
+	copyDryRun = false
+
+	files, err := database.Connect(&config.Config{Files: conf.Files})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hosts, _ := files.GetHosts(); len(hosts) != 0 {
+		t.Fatalf("GetHosts() = %v, want nothing copied by dry run", hosts)
+	}
+
+	if err = dbCopy(context.Background(), "sqlite", "files"); err != nil {
+		t.Fatalf("dbCopy() error = %v", err)
+	}
+
+	if files, err = database.Connect(&config.Config{Files: conf.Files}); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := countInventory(context.Background(), testDB)
+	if got, _ := countInventory(context.Background(), files); got != want {
+		t.Errorf("countInventory() = %+v, want %+v", got, want)
+	}
+
+	// the files backend store the variables as YAML and return them as compact JSON
+	if got, _ := files.SelectHost("host3"); got.Variables != `{"host_var3":"host_val3"}` ||
+		got.InheritedGroups != testHost3.InheritedGroups {
+		t.Errorf("SelectHost() = %+v, want copied %+v", got, testHost3)
+	}
+
+	if got, _ := files.SelectAddresses("host1"); len(got) != 1 || !got[0].Primary {
+		t.Errorf("SelectAddresses() = %+v, want copied primary address", got)
+	}
+
+	if err = dbCopy(context.Background(), "sqlite", "files"); err == nil {
+		t.Errorf("dbCopy() error = nil, want error for none-empty destination")
+	}
+}
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
@@ -22,6 +24,7 @@ var delete = &cobra.Command{
 	ValidArgs:  []string{"host", "group", "child"},
 	ArgAliases: []string{"hosts", "groups"},
 	Short:      "delete existing record",
+	Long:       "move existing host or group to the trash, see `admiral trash` and `admiral restore`",
 }
 
 var deleteHostVar = &cobra.Command{
@@ -31,18 +34,18 @@ var deleteHostVar = &cobra.Command{
 	ValidArgsFunction: hostsArgsFunc,
 	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := deleteHostCase(args); err != nil {
+		if err := deleteHostCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func deleteHostCase(args []string) error {
+func deleteHostCase(ctx context.Context, args []string) error {
 	var hosts []datastructs.Host
 
 	var err error
 
-	hosts, err = scanHosts(args[0])
+	hosts, err = scanHosts(ctx, DB, args[0])
 	if err != nil {
 		return err
 	}
@@ -54,7 +57,12 @@ func deleteHostCase(args []string) error {
 		printHosts(hosts)
 
 		if User.confirm() {
-			affected, err := deleteHost(&hosts[0])
+			var affected int64
+
+			err := inTx(ctx, func(tx database.Querier) (err error) {
+				affected, err = deleteHost(ctx, tx, &hosts[0])
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -71,8 +79,8 @@ func deleteHostCase(args []string) error {
 	return nil
 }
 
-func deleteHost(host *datastructs.Host) (affected int64, err error) {
-	affected, err = DB.DeleteHost(host)
+func deleteHost(ctx context.Context, db database.Querier, host *datastructs.Host) (affected int64, err error) {
+	affected, err = db.DeleteHostContext(ctx, host)
 	if err != nil {
 		return affected, err
 	} else if affected == 0 {
@@ -89,18 +97,18 @@ var deleteGroupVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := deleteGroupCase(args); err != nil {
+		if err := deleteGroupCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func deleteGroupCase(args []string) error {
+func deleteGroupCase(ctx context.Context, args []string) error {
 	var groups []datastructs.Group
 
 	var err error
 
-	groups, err = scanGroups(args[0])
+	groups, err = scanGroups(ctx, args[0])
 	if err != nil {
 		return err
 	}
@@ -112,7 +120,12 @@ func deleteGroupCase(args []string) error {
 		printGroups(groups)
 
 		if User.confirm() {
-			affected, err := deleteGroup(&groups[0])
+			var affected int64
+
+			err := inTx(ctx, func(tx database.Querier) (err error) {
+				affected, err = deleteGroup(ctx, tx, &groups[0])
+				return err
+			})
 			if err != nil {
 				return err
 			}
@@ -129,8 +142,8 @@ func deleteGroupCase(args []string) error {
 	return nil
 }
 
-func deleteGroup(group *datastructs.Group) (affected int64, err error) {
-	affected, err = DB.DeleteGroup(group)
+func deleteGroup(ctx context.Context, db database.Querier, group *datastructs.Group) (affected int64, err error) {
+	affected, err = db.DeleteGroupContext(ctx, group)
 	if err != nil {
 		return affected, err
 	} else if affected == 0 {
@@ -148,18 +161,18 @@ var deleteChildVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := deleteChildCase(args); err != nil {
+		if err := deleteChildCase(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func deleteChildCase(args []string) error {
+func deleteChildCase(ctx context.Context, args []string) error {
 	var childGroups []datastructs.ChildGroup
 
 	var err error
 
-	childGroups, err = viewChildGroup(args[0], args[1])
+	childGroups, err = viewChildGroup(ctx, args[0], args[1])
 	if err != nil {
 		return (err)
 	}
@@ -167,7 +180,12 @@ func deleteChildCase(args []string) error {
 	printChildGroups(childGroups)
 
 	if User.confirm() {
-		affected, err := deleteChildGroup(&childGroups[0])
+		var affected int64
+
+		err := inTx(ctx, func(tx database.Querier) (err error) {
+			affected, err = deleteChildGroup(ctx, tx, &childGroups[0])
+			return err
+		})
 		if err != nil {
 			return err
 		}
@@ -180,8 +198,9 @@ func deleteChildCase(args []string) error {
 	return nil
 }
 
-func deleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
-	affected, err = DB.DeleteChildGroup(childGroup)
+func deleteChildGroup(ctx context.Context, db database.Querier, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	affected, err = db.DeleteChildGroupContext(ctx, childGroup)
 	if err != nil {
 		return affected, err
 	} else if affected == 0 {
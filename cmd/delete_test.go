@@ -2,6 +2,7 @@
 package cmd
 
 import (
+	"context"
 	"testing"
 
 	"github.com/via-justa/admiral/datastructs"
@@ -39,7 +40,7 @@ func Test_deleteHostCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := deleteHostCase(tt.args); (err != nil) != tt.wantErr {
+			if err := deleteHostCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("deleteHostCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -79,7 +80,7 @@ func Test_deleteHost(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAffected, err := deleteHost(tt.args.host)
+			gotAffected, err := deleteHost(context.Background(), DB, tt.args.host)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("deleteHost() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -119,7 +120,7 @@ func Test_deleteGroupCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := deleteGroupCase(tt.args); (err != nil) != tt.wantErr {
+			if err := deleteGroupCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("deleteGroupCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -159,7 +160,7 @@ func Test_deleteGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAffected, err := deleteGroup(tt.args.group)
+			gotAffected, err := deleteGroup(context.Background(), DB, tt.args.group)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("deleteGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -202,7 +203,7 @@ func Test_deleteChildCase(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := deleteChildCase(tt.args); (err != nil) != tt.wantErr {
+			if err := deleteChildCase(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("deleteChildCase() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -242,7 +243,7 @@ func Test_deleteChildGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAffected, err := deleteChildGroup(tt.args.childGroup)
+			gotAffected, err := deleteChildGroup(context.Background(), DB, tt.args.childGroup)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("deleteChildGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
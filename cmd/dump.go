@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func init() {
+	rootCmd.AddCommand(dumpVar)
+}
+
+var dumpVar = &cobra.Command{
+	Use:   "dump",
+	Short: "dump the whole inventory as json",
+	Long: "print the groups, child-group relationships and hosts with their variables, group memberships and" +
+		" addresses as a single versioned json document that can be restored to any database backend with" +
+		" `admiral restore`. Hosts and groups in the trash and the audit log are not dumped",
+	Example: "admiral dump > backup.json",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dumpCase(cmd.Context(), time.Now()); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func dumpCase(ctx context.Context, now time.Time) error {
+	dump, err := dumpInventory(ctx, DB, now)
+	if err != nil {
+		return err
+	}
+
+	b, err := marshalDump(&dump)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
+
+// marshalDump encode the dump as indented json with the variables as json values
+func marshalDump(dump *datastructs.Dump) ([]byte, error) {
+	for i := range dump.Groups {
+		if err := dump.Groups[i].UnmarshalVars(); err != nil {
+			return nil, fmt.Errorf("group %v: %w", dump.Groups[i].Name, err)
+		}
+	}
+
+	for i := range dump.Hosts {
+		if err := dump.Hosts[i].UnmarshalVars(); err != nil {
+			return nil, fmt.Errorf("host %v: %w", dump.Hosts[i].Hostname, err)
+		}
+	}
+
+	return json.MarshalIndent(dump, "", "    ")
+}
+
+// dumpInventory return the inventory of db as of now. Only the fields restored by loadInventory are kept, the
+// variables are left as stored
+func dumpInventory(ctx context.Context, db database.Querier, now time.Time) (dump datastructs.Dump, err error) {
+	dump = datastructs.Dump{Version: datastructs.DumpVersion, Created: now.UTC().Format(time.RFC3339),
+		Groups: datastructs.Groups{}, ChildGroups: []datastructs.DumpChildGroup{}, Hosts: datastructs.Hosts{}}
+
+	groups, err := db.GetGroupsContext(ctx)
+	if err != nil {
+		return dump, err
+	}
+
+	for i := range groups {
+		dump.Groups = append(dump.Groups, datastructs.Group{Name: groups[i].Name, Variables: groups[i].Variables,
+			Enabled: groups[i].Enabled, Monitored: groups[i].Monitored})
+	}
+
+	childGroups, err := db.GetChildGroupsContext(ctx)
+	if err != nil {
+		return dump, err
+	}
+
+	for _, cg := range childGroups {
+		dump.ChildGroups = append(dump.ChildGroups, datastructs.DumpChildGroup{Parent: cg.Parent, Child: cg.Child})
+	}
+
+	hosts, err := db.GetHostsContext(ctx)
+	if err != nil {
+		return dump, err
+	}
+
+	if err = loadAddresses(ctx, db, hosts); err != nil {
+		return dump, err
+	}
+
+	for i := range hosts {
+		host := datastructs.Host{Host: hosts[i].Host, Hostname: hosts[i].Hostname, Domain: hosts[i].Domain,
+			Variables: hosts[i].Variables, Enabled: hosts[i].Enabled, Monitored: hosts[i].Monitored,
+			DirectGroups: hosts[i].DirectGroups, Addresses: datastructs.Addresses{}}
+
+		for _, a := range hosts[i].Addresses {
+			host.Addresses = append(host.Addresses, datastructs.Address{Name: a.Name, Address: a.Address,
+				Primary: a.Primary})
+		}
+
+		dump.Hosts = append(dump.Hosts, host)
+	}
+
+	return dump, nil
+}
+
+// loadInventory insert the records of the dump to db by name, the IDs of the loaded records are the ones
+// given by db
+func loadInventory(ctx context.Context, db database.Querier, dump *datastructs.Dump) error {
+	groupIDs := make(map[string]int, len(dump.Groups))
+
+	for i := range dump.Groups {
+		group := dump.Groups[i]
+		group.Revision = 0
+
+		if err := createGroup(ctx, db, &group); err != nil {
+			return fmt.Errorf("group %v: %w", group.Name, err)
+		}
+
+		group, err := db.SelectGroupContext(ctx, group.Name)
+		if err != nil {
+			return err
+		}
+
+		groupIDs[group.Name] = group.ID
+	}
+
+	for _, cg := range dump.ChildGroups {
+		parentID, childID := groupIDs[cg.Parent], groupIDs[cg.Child]
+		if parentID == 0 || childID == 0 {
+			return fmt.Errorf("child group %v of %v: unknown group", cg.Child, cg.Parent)
+		}
+
+		if _, err := db.InsertChildGroupContext(ctx, &datastructs.ChildGroup{ParentID: parentID, Parent: cg.Parent,
+			ChildID: childID, Child: cg.Child}); err != nil {
+			return fmt.Errorf("child group %v of %v: %w", cg.Child, cg.Parent, err)
+		}
+	}
+
+	for i := range dump.Hosts {
+		host := dump.Hosts[i]
+		host.Revision = 0
+
+		if err := createHost(ctx, db, &host); err != nil {
+			return fmt.Errorf("host %v: %w", host.Hostname, err)
+		}
+
+		created, err := db.SelectHostContext(ctx, host.Hostname)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range host.DirectGroups {
+			if groupIDs[name] == 0 {
+				return fmt.Errorf("host %v in group %v: unknown group", host.Hostname, name)
+			}
+
+			if _, err = db.InsertHostGroupContext(ctx, &datastructs.HostGroup{HostID: created.ID,
+				Host: created.Hostname, GroupID: groupIDs[name], Group: name}); err != nil {
+				return fmt.Errorf("host %v in group %v: %w", host.Hostname, name, err)
+			}
+		}
+
+		for _, a := range host.Addresses {
+			if _, err = db.InsertAddressContext(ctx, &datastructs.Address{HostID: created.ID, Host: created.Hostname,
+				Name: a.Name, Address: a.Address, Primary: a.Primary}); err != nil {
+				return fmt.Errorf("host %v address %v: %w", host.Hostname, a.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dumpCounts return the number of records of each kind in the dump
+func dumpCounts(dump *datastructs.Dump) inventoryCounts {
+	counts := inventoryCounts{Groups: len(dump.Groups), ChildGroups: len(dump.ChildGroups), Hosts: len(dump.Hosts)}
+
+	for i := range dump.Hosts {
+		counts.HostGroups += len(dump.Hosts[i].DirectGroups)
+		counts.Addresses += len(dump.Hosts[i].Addresses)
+	}
+
+	return counts
+}
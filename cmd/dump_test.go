@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func Test_dumpInventory(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	if _, err := testDB.InsertAddress(&datastructs.Address{HostID: testHost1.ID, Name: "public",
+		Address: "1.1.1.1", Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	dump, err := dumpInventory(context.Background(), testDB, now)
+	if err != nil {
+		t.Fatalf("dumpInventory() error = %v", err)
+	}
+
+	if dump.Version != datastructs.DumpVersion || dump.Created != "2020-11-02T10:00:00Z" {
+		t.Errorf("dumpInventory() version = %v created = %v, want %v %v", dump.Version, dump.Created,
+			datastructs.DumpVersion, "2020-11-02T10:00:00Z")
+	}
+
+	want, _ := countInventory(context.Background(), testDB)
+	if got := dumpCounts(&dump); got != want {
+		t.Errorf("dumpCounts() = %+v, want %+v", got, want)
+	}
+
+	for _, host := range dump.Hosts {
+		if host.Revision != 0 || host.Created != "" || host.ID != 0 {
+			t.Errorf("dumpInventory() host = %+v, want no revision, timestamps or ID", host)
+		}
+	}
+}
+
+func Test_restoreDumpCase(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	if _, err := testDB.InsertAddress(&datastructs.Address{HostID: testHost1.ID, Name: "public",
+		Address: "1.1.1.1", Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	dump, err := dumpInventory(context.Background(), testDB, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := dumpCounts(&dump)
+
+	b, err := marshalDump(&dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err = ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = restoreDumpCase(context.Background(), path, false); err == nil {
+		t.Errorf("restoreDumpCase() error = nil, want error for none-empty inventory without wipe")
+	}
+
+	// changes made after the dump are replaced, the trash is emptied
+	host2, _ := testDB.SelectHost("host2")
+	if _, err = testDB.DeleteHost(&host2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = testDB.InsertGroup(&datastructs.Group{Name: "group10", Variables: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = restoreDumpCase(context.Background(), path, true); err != nil {
+		t.Fatalf("restoreDumpCase() error = %v", err)
+	}
+
+	if got, _ := countInventory(context.Background(), testDB); got != want {
+		t.Errorf("countInventory() = %+v, want %+v", got, want)
+	}
+
+	if got, _ := testDB.GetDeletedHosts(); len(got) != 0 {
+		t.Errorf("GetDeletedHosts() = %+v, want empty trash", got)
+	}
+
+	// the variables are restored as compact json
+	if got, _ := testDB.SelectHost("host3"); got.Variables != `{"host_var3":"host_val3"}` ||
+		got.DirectGroups.String() != testHost3.DirectGroups.String() ||
+		got.InheritedGroups != testHost3.InheritedGroups {
+		t.Errorf("SelectHost() = %+v, want restored %+v", got, testHost3)
+	}
+
+	if got, _ := testDB.SelectAddresses("host1"); len(got) != 1 || !got[0].Primary {
+		t.Errorf("SelectAddresses() = %+v, want restored primary address", got)
+	}
+}
+
+func Test_readDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "dump",
+			content: `{"version": 1, "groups": [{"name": "group1", "variables": {"a": 1}}]}`,
+		},
+		{
+			name:    "not a dump",
+			content: `[{"hostname": "host1"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "not versioned",
+			content: `{"groups": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "newer version",
+			content: `{"version": 1000, "groups": []}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "backup.json")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readDump(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readDump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got.Groups[0].Variables != `{"a":1}` {
+				t.Errorf("readDump() variables = %v, want %v", got.Groups[0].Variables, `{"a":1}`)
+			}
+		})
+	}
+}
+
+func Test_restoreArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "dump file argument",
+			args:    []string{"restore", "backup.json"},
+			want:    "restore",
+			wantErr: false,
+		},
+		{
+			name:    "dump file argument with wipe",
+			args:    []string{"restore", "backup.json", "--wipe"},
+			want:    "restore",
+			wantErr: false,
+		},
+		{
+			name:    "dump file flag",
+			args:    []string{"restore", "--file", "backup.json"},
+			want:    "restore",
+			wantErr: false,
+		},
+		{
+			name:    "no dump file",
+			args:    []string{"restore", "--wipe"},
+			want:    "restore",
+			wantErr: true,
+		},
+		{
+			name:    "dump file argument and flag",
+			args:    []string{"restore", "backup.json", "--file", "other.json"},
+			want:    "restore",
+			wantErr: true,
+		},
+		{
+			name:    "two dump files",
+			args:    []string{"restore", "backup.json", "other.json"},
+			want:    "restore",
+			wantErr: true,
+		},
+		{
+			name:    "host from the trash",
+			args:    []string{"restore", "host", "host1"},
+			want:    "host",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the flags keep their value between the cases
+			restoreFile, restoreWipe = "", false
+
+			c, args, err := rootCmd.Find(tt.args)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+
+			if c.Name() != tt.want {
+				t.Errorf("Find() = %v, want %v", c.Name(), tt.want)
+			}
+
+			if err = c.ParseFlags(args); err == nil {
+				err = c.ValidateArgs(c.Flags().Args())
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("restore %v error = %v, wantErr %v", args, err, tt.wantErr)
+			}
+		})
+	}
+}
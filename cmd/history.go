@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var historyAsJSON bool
+
+func init() {
+	rootCmd.AddCommand(history)
+	history.PersistentFlags().BoolVarP(&historyAsJSON, "json", "j", false,
+		"view in json format (present the state before and after each change)")
+
+	history.AddCommand(historyHostVar)
+	history.AddCommand(historyGroupVar)
+}
+
+var history = &cobra.Command{
+	Use:       "history [host | group]",
+	ValidArgs: []string{"host", "group"},
+	Short:     "view the audit log",
+	Long: "view all changes recorded in the audit log or only the changes made to a single host or group." +
+		" Use the change ID with `admiral revert` to undo a change",
+	Example: "admiral history\nadmiral history host host1\nadmiral history group group1 -j",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viewHistory(cmd.Context(), "", ""); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var historyHostVar = &cobra.Command{
+	Use:               "host hostname",
+	Short:             "view the changes made to a host",
+	Long:              "view the changes made to a host and to its group memberships",
+	Example:           "admiral history host host1",
+	ValidArgsFunction: hostsArgsFunc,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viewHistory(cmd.Context(), datastructs.EntityHost, args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var historyGroupVar = &cobra.Command{
+	Use:               "group 'group name'",
+	Short:             "view the changes made to a group",
+	Long:              "view the changes made to a group, its child-group relationships and its hosts memberships",
+	Example:           "admiral history group group1",
+	ValidArgsFunction: groupsArgsFunc,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viewHistory(cmd.Context(), datastructs.EntityGroup, args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func viewHistory(ctx context.Context, entity, name string) error {
+	changes, err := listChanges(ctx, entity, name)
+	if err != nil {
+		return err
+	}
+
+	if historyAsJSON {
+		b, _ := json.MarshalIndent(changes, "", "    ")
+
+		fmt.Printf("%s\n", b)
+	} else {
+		printChanges(changes)
+	}
+
+	return nil
+}
+
+func listChanges(ctx context.Context, entity, name string) (changes []datastructs.Change, err error) {
+	changes, err = DB.SelectChangesContext(ctx, entity, name)
+	if err != nil {
+		return changes, err
+	} else if name != "" && len(changes) == 0 {
+		return changes, fmt.Errorf("no change matched request")
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,165 @@
+// nolint
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func Test_listChanges(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	if err := deleteHostCase(context.Background(), []string{"host1"}); err != nil {
+		t.Fatalf("deleteHostCase() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		entity      string
+		val         string
+		wantActions []string
+		wantErr     bool
+	}{
+		{
+			name:        "host changes",
+			entity:      datastructs.EntityHost,
+			val:         "host1",
+			wantActions: []string{datastructs.ActionDelete},
+		},
+		{
+			name:    "group memberships kept in the trash",
+			entity:  datastructs.EntityGroup,
+			val:     "group1",
+			wantErr: true,
+		},
+		{
+			name:    "no changes",
+			entity:  datastructs.EntityHost,
+			val:     "host2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listChanges(context.Background(), tt.entity, tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("listChanges() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if len(got) != len(tt.wantActions) {
+				t.Fatalf("listChanges() = %v, want actions %v", got, tt.wantActions)
+			}
+
+			for i := range got {
+				if got[i].Action != tt.wantActions[i] || got[i].Actor == "" || got[i].ChangedAt == "" {
+					t.Errorf("listChanges() = %v, want actions %v", got, tt.wantActions)
+				}
+			}
+		})
+	}
+}
+
+func Test_revertCase(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	if err := deleteHostCase(ctx, []string{"host1"}); err != nil {
+		t.Fatalf("deleteHostCase() error = %v", err)
+	}
+
+	if err := createGroup(ctx, DB, &datastructs.Group{Name: "new-group", Variables: "{}"}); err != nil {
+		t.Fatalf("createGroup() error = %v", err)
+	}
+
+	host2 := datastructs.Host{ID: 2, Hostname: "host2"}
+
+	addresses := datastructs.Addresses{{Name: "management", Address: "10.0.0.2"}}
+	if err := setHostAddresses(ctx, DB, &host2, addresses); err != nil {
+		t.Fatalf("setHostAddresses() error = %v", err)
+	}
+
+	if err := setHostAddresses(ctx, DB, &host2, datastructs.Addresses{}); err != nil {
+		t.Fatalf("setHostAddresses() error = %v", err)
+	}
+
+	changes, err := DB.SelectChanges("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hostDeleted, groupCreated, addressDeleted int64
+
+	for _, c := range changes {
+		switch {
+		case c.Entity == datastructs.EntityHost && c.Name == "host1":
+			hostDeleted = c.ID
+		case c.Entity == datastructs.EntityGroup && c.Name == "new-group":
+			groupCreated = c.ID
+		case c.Entity == datastructs.EntityAddress && c.Action == datastructs.ActionDelete:
+			addressDeleted = c.ID
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		check   func() bool
+		wantErr bool
+	}{
+		{
+			name: "restore deleted host and its group",
+			args: []string{strconv.FormatInt(hostDeleted, 10)},
+			check: func() bool {
+				hostGroups, _ := DB.SelectHostGroup("host1")
+				return len(hostGroups) == 1 && hostGroups[0].Group == "group1"
+			},
+		},
+		{
+			name: "delete created group",
+			args: []string{strconv.FormatInt(groupCreated, 10)},
+			check: func() bool {
+				group, _ := DB.SelectGroup("new-group")
+				return group.ID == 0
+			},
+		},
+		{
+			name: "restore deleted address",
+			args: []string{strconv.FormatInt(addressDeleted, 10)},
+			check: func() bool {
+				addresses, _ := DB.SelectAddresses("host2")
+				return len(addresses) == 1 && addresses[0].Address == "10.0.0.2"
+			},
+		},
+		{
+			name:    "unknown change",
+			args:    []string{"1000"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid change id",
+			args:    []string{"last"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := revertCase(ctx, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("revertCase() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.check != nil && !tt.check() {
+				t.Errorf("revertCase() did not restore the state before change %v", tt.args[0])
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"log"
 
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 
 	"github.com/spf13/cobra"
@@ -31,13 +33,13 @@ var importHosts = &cobra.Command{
 	Long:  "bulk import hosts from json encoded file [file path]",
 	Args:  cobra.ExactValidArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := importHostsFromPath(args); err != nil {
+		if err := importHostsFromPath(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func importHostsFromPath(args []string) (err error) {
+func importHostsFromPath(ctx context.Context, args []string) (err error) {
 	var file []byte
 
 	var hosts []datastructs.Host
@@ -52,19 +54,24 @@ func importHostsFromPath(args []string) (err error) {
 		return err
 	}
 
-	for i := range hosts {
-		err = hosts[i].MarshalVars()
-		if err != nil {
-			return err
-		}
+	return inTx(ctx, func(tx database.Querier) (err error) {
+		for i := range hosts {
+			err = hosts[i].MarshalVars()
+			if err != nil {
+				return err
+			}
+
+			// imported hosts overwrite the stored ones, whatever revision they were exported at
+			hosts[i].Revision = 0
 
-		err = createHost(&hosts[i])
-		if err != nil {
-			return err
+			err = createHost(ctx, tx, &hosts[i])
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	return err
+		return err
+	})
 }
 
 var importGroups = &cobra.Command{
@@ -73,13 +80,13 @@ var importGroups = &cobra.Command{
 	Long:  "bulk import groups from json encoded file [file path]",
 	Args:  cobra.ExactValidArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := importGroupsFromPath(args); err != nil {
+		if err := importGroupsFromPath(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func importGroupsFromPath(args []string) (err error) {
+func importGroupsFromPath(ctx context.Context, args []string) (err error) {
 	var file []byte
 
 	var groups []datastructs.Group
@@ -94,19 +101,24 @@ func importGroupsFromPath(args []string) (err error) {
 		return err
 	}
 
-	for i := range groups {
-		err = groups[i].MarshalVars()
-		if err != nil {
-			return err
-		}
+	return inTx(ctx, func(tx database.Querier) (err error) {
+		for i := range groups {
+			err = groups[i].MarshalVars()
+			if err != nil {
+				return err
+			}
+
+			// imported groups overwrite the stored ones, whatever revision they were exported at
+			groups[i].Revision = 0
 
-		err = createGroup(&groups[i])
-		if err != nil {
-			return err
+			err = createGroup(ctx, tx, &groups[i])
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	return err
+		return err
+	})
 }
 
 var importChildren = &cobra.Command{
@@ -115,13 +127,13 @@ var importChildren = &cobra.Command{
 	Long:  "bulk import child group relationships from json encoded file [file path]",
 	Args:  cobra.ExactValidArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := importChildrenFromPath(args); err != nil {
+		if err := importChildrenFromPath(cmd.Context(), args); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
-func importChildrenFromPath(args []string) (err error) {
+func importChildrenFromPath(ctx context.Context, args []string) (err error) {
 	var file []byte
 
 	var children []datastructs.ChildGroup
@@ -136,24 +148,26 @@ func importChildrenFromPath(args []string) (err error) {
 		return err
 	}
 
-	for i := range children {
-		var child, parent datastructs.Group
+	return inTx(ctx, func(tx database.Querier) (err error) {
+		for i := range children {
+			var child, parent datastructs.Group
 
-		child, err = viewGroupByName(children[i].Child)
-		if err != nil {
-			return err
-		}
+			child, err = viewGroupByName(ctx, tx, children[i].Child)
+			if err != nil {
+				return err
+			}
 
-		parent, err = viewGroupByName(children[i].Parent)
-		if err != nil {
-			return err
-		}
+			parent, err = viewGroupByName(ctx, tx, children[i].Parent)
+			if err != nil {
+				return err
+			}
 
-		err = createChildGroup(&child, &parent)
-		if err != nil {
-			return err
+			err = createChildGroup(ctx, tx, &child, &parent)
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	return err
+		return err
+	})
 }
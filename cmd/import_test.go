@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"testing"
 )
 
@@ -29,14 +30,25 @@ func Test_importHostsFromPath(t *testing.T) {
 			args:    []string{"../fixtures/files/hosts-corrupted.json"},
 			wantErr: true,
 		},
+		{
+			name:    "import file with invalid host",
+			args:    []string{"../fixtures/files/hosts-partial.json"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := importHostsFromPath(tt.args); (err != nil) != tt.wantErr {
+			if err := importHostsFromPath(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("importHostsFromPath() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
+
+	// failed import should not leave any of the file hosts behind
+	host, err := DB.SelectHost("host11")
+	if err != nil || host.ID != 0 {
+		t.Errorf("importHostsFromPath() partially imported host = %v, err = %v", host, err)
+	}
 }
 
 func Test_importGroupsFromPath(t *testing.T) {
@@ -67,7 +79,7 @@ func Test_importGroupsFromPath(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := importGroupsFromPath(tt.args); (err != nil) != tt.wantErr {
+			if err := importGroupsFromPath(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("importGroupsFromPath() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -102,7 +114,7 @@ func Test_importChildrenFromPath(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := importChildrenFromPath(tt.args); (err != nil) != tt.wantErr {
+			if err := importChildrenFromPath(context.Background(), tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("importChildrenFromPath() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
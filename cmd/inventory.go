@@ -1,156 +1,275 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
+// inventoryScript is the name admiral is installed or linked as to be used as ansible inventory script, it then
+// run the inventory command with the arguments given by ansible
+const inventoryScript = "admiral-inventory"
+
+var (
+	inventoryList   bool
+	inventoryHost   string
+	inventoryFormat string
+)
+
 func init() {
 	rootCmd.AddCommand(genInventory)
+
+	genInventory.Flags().BoolVar(&inventoryList, "list", false,
+		"output the whole inventory, as ansible expect from inventory scripts (default)")
+	genInventory.Flags().StringVar(&inventoryHost, "host", "", "output the variables of the host by its inventory"+
+		" name, hostname.domain or hostname, exit with status 1 if the host is not in the inventory")
+	genInventory.Flags().StringVarP(&inventoryFormat, "format", "f", "json", "output format of the inventory, one"+
+		" of "+strings.Join(inventoryFormats, ", ")+", --list and --host output json")
 }
 
 var genInventory = &cobra.Command{
 	Use:     "inventory",
 	Aliases: []string{"inv"},
 	Short:   "Output Ansible compatible inventory structure",
-	Example: "admiral inventory\nadmiral inventory > inventory.json",
+	Long: "Output the enabled hosts and groups in the ansible inventory script structure, or as static INI or YAML" +
+		" inventory with --format. Ansible can run admiral as inventory script when it is installed or linked as " +
+		inventoryScript + ", e.g.\nln -s $(which admiral) /usr/local/bin/" + inventoryScript +
+		"\nansible -i $(which " + inventoryScript + ") all -m ping",
+	Example: "admiral inventory\nadmiral inventory > inventory.json\nadmiral inventory --format ini > hosts.ini\n" +
+		"admiral inventory --host host1.example.com",
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		inv, err := inventory()
+		var err error
+
+		switch {
+		case inventoryList && inventoryHost != "":
+			err = fmt.Errorf("--list and --host cannot be used together")
+		case (inventoryList || inventoryHost != "") && inventoryFormat != "json":
+			err = fmt.Errorf("--list and --host output json, --format %v cannot be used with them", inventoryFormat)
+		case inventoryHost != "":
+			err = writeHostVars(cmd.Context(), DB, os.Stdout, inventoryHost)
+		default:
+			err = writeInventoryFormat(cmd.Context(), DB, os.Stdout, inventoryFormat)
+		}
+
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		fmt.Printf("%s", inv)
 	},
 }
 
-type inventoryData struct {
-	hosts       []datastructs.Host
-	groups      []datastructs.Group
-	childGroups []datastructs.ChildGroup
-}
-
-func getInventoryData() (inv inventoryData, err error) {
-	inv.hosts, err = DB.GetHosts()
-	if err != nil {
-		return inv, err
+// inventoryScriptArgs return the command line args running the inventory command when admiral is run as
+// inventoryScript, args unchanged otherwise
+func inventoryScriptArgs(args []string) []string {
+	if len(args) == 0 || strings.TrimSuffix(filepath.Base(args[0]), ".exe") != inventoryScript {
+		return args
 	}
 
-	inv.groups, err = DB.GetGroups()
+	return append([]string{args[0], genInventory.Name()}, args[1:]...)
+}
+
+// hostAddresses return the addresses of all hosts by host ID
+func hostAddresses(ctx context.Context, db database.Querier) (map[int]datastructs.Addresses, error) {
+	addresses, err := db.GetAddressesContext(ctx)
 	if err != nil {
-		return inv, err
+		return nil, err
 	}
 
-	inv.childGroups, err = DB.GetChildGroups()
-	if err != nil {
-		return inv, err
+	byHost := make(map[int]datastructs.Addresses)
+	for i := range addresses {
+		byHost[addresses[i].HostID] = append(byHost[addresses[i].HostID], addresses[i])
 	}
 
-	return inv, nil
+	return byHost, nil
 }
 
-func (inv *inventoryData) getChildren(parent *datastructs.Group) (children []string) {
-	// Get group children
-	for _, childGroup := range inv.childGroups {
-		if childGroup.ParentID == parent.ID {
-			children = append(children, childGroup.Child)
+// setAddressVars set ansible_host to the host primary address, or to the host ip when it has no primary
+// address, and the host other addresses in the addresses var by their name
+func setAddressVars(hostVars datastructs.InventoryVars, host *datastructs.Host) {
+	hostVars["ansible_host"] = host.Host
+
+	addresses := make(map[string]string)
+
+	for _, address := range host.Addresses {
+		if address.Primary {
+			hostVars["ansible_host"] = address.Address
+			continue
 		}
+
+		addresses[address.Name] = address.Address
 	}
 
-	return children
+	if len(addresses) > 0 {
+		hostVars["addresses"] = addresses
+	}
 }
 
-func (inv *inventoryData) getGroupHosts(group *datastructs.Group) (groupHosts []string) {
-	for i := range inv.hosts {
-		if inv.hosts[i].DirectGroup == group.Name {
-			groupHosts = append(groupHosts, inv.hosts[i].Hostname+"."+inv.hosts[i].Domain)
+// selectInventoryHost return the enabled host of the inventory name, hostname.domain, or of the hostname
+func selectInventoryHost(ctx context.Context, db database.Querier, name string) (host datastructs.Host, err error) {
+	host, err = db.SelectHostContext(ctx, name)
+	if err != nil {
+		return host, err
+	}
+
+	// the hostname may hold dots as well, every split of the name is tried
+	for i := 0; host.ID == 0 && i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+
+		host, err = db.SelectHostContext(ctx, name[:i])
+		if err != nil {
+			return host, err
+		}
+
+		if host.Domain != name[i+1:] {
+			host = datastructs.Host{}
 		}
 	}
 
-	return groupHosts
-}
+	if host.ID == 0 || !host.Enabled {
+		return host, fmt.Errorf("host %v is not in the inventory", name)
+	}
 
-func (inv *inventoryData) buildInventoryGroups() (datastructs.InventoryGroups, error) {
-	inventoryGroups := datastructs.InventoryGroups{}
+	return host, nil
+}
 
-	for _, parent := range inv.groups {
-		if parent.Enabled {
-			p := parent
-			children := inv.getChildren(&p)
+// writeHostVars write the variables of the host to w, as written under _meta.hostvars in the inventory
+func writeHostVars(ctx context.Context, db database.Querier, w io.Writer, name string) error {
+	host, err := selectInventoryHost(ctx, db, name)
+	if err != nil {
+		return err
+	}
 
-			// get group vars
-			var GroupVars datastructs.InventoryVars
+	var hostVars datastructs.InventoryVars
+	if err = json.Unmarshal([]byte(host.Variables), &hostVars); err != nil {
+		return fmt.Errorf("host %v: %w", host.Hostname, err)
+	}
 
-			err := json.Unmarshal([]byte(parent.Variables), &GroupVars)
-			if err != nil {
-				return nil, err
-			}
+	host.Addresses, err = db.SelectAddressesContext(ctx, host.Hostname)
+	if err != nil {
+		return err
+	}
 
-			groupHosts := inv.getGroupHosts(&p)
+	setAddressVars(hostVars, &host)
 
-			inventoryGroups[parent.Name] = datastructs.InventoryGroupsData{
-				Children: children,
-				Vars:     GroupVars,
-				Hosts:    groupHosts,
-			}
-		}
+	b, err := json.MarshalIndent(hostVars, "", jsonIndent)
+	if err != nil {
+		return err
 	}
 
-	return inventoryGroups, nil
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
 }
 
-// inventory return the entire inventory in Ansible acceptable json structure
-func inventory() ([]byte, error) {
-	invData, err := getInventoryData()
+// walkInventory call host with the variables of every enabled host, by its inventory name, while the hosts are
+// read, then group with every enabled group. Only the names of the group hosts are kept in memory meanwhile
+func walkInventory(ctx context.Context, db database.Querier,
+	host func(name string, vars datastructs.InventoryVars) error,
+	group func(name string, data datastructs.InventoryGroupsData) error) error {
+	childGroups, err := db.GetChildGroupsContext(ctx)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	children := make(map[int][]string)
+	for _, cg := range childGroups {
+		children[cg.ParentID] = append(children[cg.ParentID], cg.Child)
+	}
+
+	addresses, err := hostAddresses(ctx, db)
+	if err != nil {
+		return err
 	}
 
-	// generate inventory hosts
-	inventoryHosts := datastructs.InventoryHosts{}
+	groupHosts := make(map[string][]string)
 
-	for i := range invData.hosts {
-		if invData.hosts[i].Enabled {
-			var hostVars datastructs.InventoryVars
+	err = db.IterateHostsContext(ctx, datastructs.Page{}, func(h *datastructs.Host) error {
+		if !h.Enabled {
+			return nil
+		}
 
-			err = json.Unmarshal([]byte(invData.hosts[i].Variables), &hostVars)
-			if err != nil {
-				return nil, err
-			}
+		var hostVars datastructs.InventoryVars
+		if err := json.Unmarshal([]byte(h.Variables), &hostVars); err != nil {
+			return fmt.Errorf("host %v: %w", h.Hostname, err)
+		}
 
-			hostVars["ansible_ssh_host"] = invData.hosts[i].Host
+		h.Addresses = addresses[h.ID]
+		setAddressVars(hostVars, h)
 
-			inventoryHosts[invData.hosts[i].Hostname+"."+invData.hosts[i].Domain] = hostVars
+		fqdn := h.Hostname + "." + h.Domain
+		for _, group := range h.DirectGroups {
+			groupHosts[group] = append(groupHosts[group], fqdn)
 		}
-	}
 
-	inventoryGroups, err := invData.buildInventoryGroups()
+		return host(fqdn, hostVars)
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	inv := datastructs.Inventory{}
-	inv.Meta.HostVars = inventoryHosts
+	return db.IterateGroupsContext(ctx, datastructs.Page{}, func(g *datastructs.Group) error {
+		if !g.Enabled {
+			return nil
+		}
+
+		var groupVars datastructs.InventoryVars
+		if err := json.Unmarshal([]byte(g.Variables), &groupVars); err != nil {
+			return fmt.Errorf("group %v: %w", g.Name, err)
+		}
 
-	// As we cannot skip the top level key, we do some black marshel
-	// magic to combine the groups and hosts into the inventory
-	var m map[string]interface{}
+		return group(g.Name, datastructs.InventoryGroupsData{
+			Children: children[g.ID],
+			Hosts:    groupHosts[g.Name],
+			Vars:     groupVars,
+		})
+	})
+}
 
-	hostsBytes, _ := json.Marshal(inv)
-	if err := json.Unmarshal(hostsBytes, &m); err != nil {
-		return nil, err
+// writeInventory write the entire inventory in Ansible acceptable json structure to w. The enabled hosts are
+// written under _meta.hostvars while they are read, followed by the enabled groups
+func writeInventory(ctx context.Context, db database.Querier, w io.Writer) error {
+	jw := newJSONWriter(w)
+	jw.Open("", "{")
+	jw.Open("_meta", "{")
+	jw.Open("hostvars", "{")
+
+	// the host variables object is closed before the first group
+	hostVarsOpen := true
+	closeHostVars := func() {
+		if hostVarsOpen {
+			jw.Close("}")
+			jw.Close("}")
+
+			hostVarsOpen = false
+		}
 	}
 
-	groupsBytes, _ := json.Marshal(inventoryGroups)
-	if err := json.Unmarshal(groupsBytes, &m); err != nil {
-		return nil, err
+	err := walkInventory(ctx, db, func(name string, vars datastructs.InventoryVars) error {
+		jw.Value(name, vars)
+		return nil
+	}, func(name string, data datastructs.InventoryGroupsData) error {
+		closeHostVars()
+		jw.Value(name, data)
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	invBytes, _ := json.MarshalIndent(m, "", "    ")
+	closeHostVars()
+	jw.Close("}")
 
-	return invBytes, nil
+	return jw.Flush()
 }
@@ -2,25 +2,30 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"reflect"
 	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
 )
 
 var inv = `{
     "_meta": {
         "hostvars": {
             "host1.domain.local": {
-                "ansible_ssh_host": "1.1.1.1",
+                "ansible_host": "1.1.1.1",
                 "host_var1": {
                     "host_sub_var1": "host_sub_val1"
                 }
             },
             "host2.domain.local": {
-                "ansible_ssh_host": "2.2.2.2",
+                "ansible_host": "2.2.2.2",
                 "host_var2": "host_val2"
             },
             "host3.domain.local": {
-                "ansible_ssh_host": "3.3.3.3",
+                "ansible_host": "3.3.3.3",
                 "host_var3": "host_val3"
             }
         }
@@ -88,14 +93,156 @@ func Test_inventory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := inventory()
+			got, err := inventoryBytes(context.Background())
 			if (err != nil) != tt.wantErr {
-				t.Errorf("inventory() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("writeInventory() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("inventory() = %s, want %s", got, tt.want)
+				t.Errorf("writeInventory() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inventory_multipleGroups(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	err := setHostGroups(context.Background(), DB, &testHost1, datastructs.GroupNames{"group1", "group2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := inventoryBytes(context.Background())
+	if err != nil {
+		t.Fatalf("writeInventory() error = %v", err)
+	}
+
+	var groups datastructs.InventoryGroups
+	if err = json.Unmarshal(got, &groups); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, group := range []string{"group1", "group2"} {
+		hosts := groups[group].Hosts
+		if len(hosts) == 0 || hosts[0] != "host1.domain.local" {
+			t.Errorf("writeInventory() %v hosts = %v, want host1.domain.local", group, hosts)
+		}
+	}
+}
+
+func Test_inventory_addresses(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	err := setHostAddresses(context.Background(), DB, &testHost1, datastructs.Addresses{
+		{Name: "management", Address: "10.0.0.1"},
+		{Name: "public", Address: "8.8.8.8", Primary: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := inventoryBytes(context.Background())
+	if err != nil {
+		t.Fatalf("writeInventory() error = %v", err)
+	}
+
+	var inv datastructs.Inventory
+	if err = json.Unmarshal(got, &inv); err != nil {
+		t.Fatal(err)
+	}
+
+	host1 := inv.Meta.HostVars["host1.domain.local"]
+	if host1["ansible_host"] != "8.8.8.8" {
+		t.Errorf("writeInventory() host1 ansible_host = %v, want primary address 8.8.8.8", host1["ansible_host"])
+	}
+
+	if want := map[string]interface{}{"management": "10.0.0.1"}; !reflect.DeepEqual(host1["addresses"], want) {
+		t.Errorf("writeInventory() host1 addresses = %v, want %v", host1["addresses"], want)
+	}
+
+	if host2 := inv.Meta.HostVars["host2.domain.local"]; host2["ansible_host"] != "2.2.2.2" {
+		t.Errorf("writeInventory() host2 ansible_host = %v, want host ip 2.2.2.2", host2["ansible_host"])
+	}
+}
+
+func Test_writeHostVars(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	disabled := testHost3
+	disabled.Enabled = false
+
+	if _, err := DB.InsertHost(&disabled); err != nil {
+		t.Fatal(err)
+	}
+
+	host1Vars := `{
+    "ansible_host": "1.1.1.1",
+    "host_var1": {
+        "host_sub_var1": "host_sub_val1"
+    }
+}
+`
+
+	tests := []struct {
+		name    string
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{name: "inventory name", host: "host1.domain.local", want: host1Vars},
+		{name: "hostname", host: "host1", want: host1Vars},
+		{name: "other domain", host: "host1.domain.com", wantErr: true},
+		{name: "unknown host", host: "host10.domain.local", wantErr: true},
+		{name: "disabled host", host: "host3.domain.local", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := writeHostVars(context.Background(), DB, &b, tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeHostVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if b.String() != tt.want {
+				t.Errorf("writeHostVars() = %s, want %s", b.String(), tt.want)
 			}
 		})
 	}
 }
+
+func Test_inventoryScriptArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "admiral", args: []string{"/usr/bin/admiral", "view", "host"}, want: []string{"/usr/bin/admiral", "view",
+			"host"}},
+		{name: "list", args: []string{"/usr/local/bin/admiral-inventory", "--list"},
+			want: []string{"/usr/local/bin/admiral-inventory", "inventory", "--list"}},
+		{name: "host", args: []string{"admiral-inventory.exe", "--host", "host1"},
+			want: []string{"admiral-inventory.exe", "inventory", "--host", "host1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inventoryScriptArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inventoryScriptArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func inventoryBytes(ctx context.Context) ([]byte, error) {
+	var b bytes.Buffer
+	err := writeInventory(ctx, DB, &b)
+
+	return b.Bytes(), err
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// jsonIndent is the indentation of the json output
+const jsonIndent = "    "
+
+// jsonWriter write a json document member by member, indented like json.MarshalIndent, so large documents are
+// written without being held in memory. The first error is kept and returned by Flush
+type jsonWriter struct {
+	w *bufio.Writer
+	// empty hold the open objects and arrays, true until their first member is written
+	empty []bool
+	err   error
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (jw *jsonWriter) write(s string) {
+	if jw.err == nil {
+		_, jw.err = jw.w.WriteString(s)
+	}
+}
+
+// next start a new member of the innermost object or array, with key when it is an object
+func (jw *jsonWriter) next(key string) {
+	if n := len(jw.empty); n > 0 {
+		if !jw.empty[n-1] {
+			jw.write(",")
+		}
+
+		jw.empty[n-1] = false
+		jw.write("\n" + strings.Repeat(jsonIndent, n))
+	}
+
+	if key != "" {
+		b, err := json.Marshal(key)
+		if err != nil && jw.err == nil {
+			jw.err = err
+		}
+
+		jw.write(string(b) + ": ")
+	}
+}
+
+// Open start an object or array, by its opening delimiter, as member key of the innermost object or as
+// element of the innermost array when key is empty
+func (jw *jsonWriter) Open(key, delim string) {
+	jw.next(key)
+	jw.write(delim)
+	jw.empty = append(jw.empty, true)
+}
+
+// Close end the innermost object or array by its closing delimiter
+func (jw *jsonWriter) Close(delim string) {
+	n := len(jw.empty) - 1
+	if !jw.empty[n] {
+		jw.write("\n" + strings.Repeat(jsonIndent, n))
+	}
+
+	jw.empty = jw.empty[:n]
+	jw.write(delim)
+}
+
+// Value write v as member key of the innermost object or as element of the innermost array when key is empty
+func (jw *jsonWriter) Value(key string, v interface{}) {
+	jw.next(key)
+
+	b, err := json.MarshalIndent(v, strings.Repeat(jsonIndent, len(jw.empty)), jsonIndent)
+	if err != nil && jw.err == nil {
+		jw.err = err
+	}
+
+	jw.write(string(b))
+}
+
+// Flush write the buffered output and return the first error
+func (jw *jsonWriter) Flush() error {
+	if jw.err != nil {
+		return jw.err
+	}
+
+	return jw.w.Flush()
+}
@@ -0,0 +1,44 @@
+// nolint:
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_jsonWriter(t *testing.T) {
+	value := map[string]interface{}{"a": []interface{}{1, "<b>"}, "empty": map[string]interface{}{}}
+
+	var got bytes.Buffer
+
+	jw := newJSONWriter(&got)
+	jw.Open("", "{")
+	jw.Open("empty", "[")
+	jw.Close("]")
+	jw.Open("list", "[")
+	jw.Value("", 1)
+	jw.Value("", value)
+	jw.Close("]")
+	jw.Value("value", value)
+	jw.Close("}")
+
+	if err := jw.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// the document is written the same as by json.MarshalIndent
+	want, _ := json.MarshalIndent(map[string]interface{}{"empty": []interface{}{}, "value": value,
+		"list": []interface{}{1, value}}, "", "    ")
+
+	if got.String() != string(want) {
+		t.Errorf("jsonWriter wrote %s, want %s", got.String(), want)
+	}
+
+	jw = newJSONWriter(&got)
+	jw.Value("", func() {})
+
+	if err := jw.Flush(); err == nil {
+		t.Errorf("Flush() error = nil, want marshal error")
+	}
+}
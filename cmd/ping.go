@@ -21,7 +21,7 @@ var ping = &cobra.Command{
 }
 
 func pingFunc(cmd *cobra.Command, args []string) {
-	host, err := viewHostByHostname(args[0])
+	host, err := viewHostByHostname(cmd.Context(), args[0])
 	if err != nil {
 		log.Fatal(err)
 	}
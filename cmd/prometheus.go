@@ -1,70 +1,180 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
+var (
+	promInterface string
+	promListen    string
+	promMaxAge    time.Duration
+)
+
 func init() {
 	rootCmd.AddCommand(genPromSDFile)
+
+	genPromSDFile.Flags().StringVarP(&promInterface, "interface", "i", "",
+		"target the host address with this name, hosts without it are targeted by their fqdn")
+	genPromSDFile.Flags().StringVarP(&promListen, "listen", "l", "",
+		"serve the targets for prometheus http_sd_configs on this address at /prometheus, e.g. :9273")
+	genPromSDFile.Flags().DurationVar(&promMaxAge, "max-age", 0,
+		"time the served targets may be cached, when 0 they are revalidated by their ETag")
 }
 
 var genPromSDFile = &cobra.Command{
 	Use:     "prometheus",
 	Aliases: []string{"prom"},
 	Short:   "Output prometheus compatible SD file structure",
-	Example: "admiral prometheus > prometheus_file_sd.json",
-	Run:     genPromSDFileFunc,
+	Example: "admiral prometheus > prometheus_file_sd.json\nadmiral prometheus --interface management\n" +
+		"admiral prometheus --listen :9273",
+	Run: genPromSDFileFunc,
 }
 
 func genPromSDFileFunc(cmd *cobra.Command, args []string) {
-	prom, err := genPrometheusSDFile()
-	if err != nil {
+	if promListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/prometheus", prometheusHandler(DB, promInterface, promMaxAge))
+
+		log.Printf("serving the prometheus targets on %v/prometheus", promListen)
+
+		err := listenAndServe(cmd.Context(), promListen, basicAuth(Conf.Prometheus.User, Conf.Prometheus.Password,
+			mux))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := writePrometheusSDFile(cmd.Context(), DB, os.Stdout, promInterface, nil); err != nil {
 		log.Fatal(err)
 	}
+}
+
+// prometheusHandler serve the prometheus SD file of db in the http_sd format. The group query parameters limit
+// the hosts to the members of any of the groups, the interface query parameter replace iface. The targets are
+// built in memory to be tagged by their ETag, a request with a matching If-None-Match is answered with 304 Not
+// Modified. Responses may be cached for maxAge, revalidated on every request when 0
+func prometheusHandler(db database.Querier, iface string, maxAge time.Duration) http.Handler {
+	cacheControl := "no-cache"
+	if maxAge > 0 {
+		cacheControl = fmt.Sprintf("max-age=%d", int(maxAge.Seconds()))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, apiError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("method %v not allowed",
+				r.Method)})
+
+			return
+		}
+
+		query := r.URL.Query()
+
+		targetIface := iface
+		if v := query.Get("interface"); v != "" {
+			targetIface = v
+		}
+
+		var b bytes.Buffer
+		if err := writePrometheusSDFile(r.Context(), db, &b, targetIface, query["group"]); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		sum := sha256.Sum256(b.Bytes())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b.Bytes()))
+	})
+}
+
+// inGroups return true if the host is a direct or inherited member of any of the groups
+func inGroups(host *datastructs.Host, groups []string) bool {
+	inherited := datastructs.GroupNames(strings.Split(host.InheritedGroups, ","))
+
+	for _, group := range groups {
+		if host.DirectGroups.Contains(group) || inherited.Contains(group) {
+			return true
+		}
+	}
 
-	fmt.Printf("%s", prom)
+	return false
 }
 
-func genPrometheusSDFile() (promSDFile []byte, err error) {
-	hosts, err := DB.GetHosts()
+// writePrometheusSDFile write the monitored hosts as prometheus SD file to w while they are read, hosts are
+// targeted by their address named iface or by their fqdn when iface is empty or the host has no such address.
+// When groups are given only the hosts member of any of them are written
+func writePrometheusSDFile(ctx context.Context, db database.Querier, w io.Writer, iface string,
+	groups []string) error {
+	allGroups, err := db.GetGroupsContext(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	groups, err := DB.GetGroups()
-	if err != nil {
-		return nil, err
+	addresses := map[int]datastructs.Addresses{}
+	if iface != "" {
+		if addresses, err = hostAddresses(ctx, db); err != nil {
+			return err
+		}
 	}
 
-	prom := []datastructs.Prometheus{}
-
-	for i := range hosts {
-		if hosts[i].Enabled && hosts[i].Monitored {
-			for j := range groups {
-				if groups[j].Name == hosts[i].DirectGroup {
-					if groups[j].Enabled && groups[j].Monitored {
-						pHost := datastructs.Prometheus{}
-						pHost.Targets = []string{hosts[i].Hostname + "." + hosts[i].Domain}
-						pHost.Labels.Group = hosts[i].DirectGroup
-						pHost.Labels.InheritedGroups = hosts[i].InheritedGroups
-						prom = append(prom, pHost)
-					} else {
-						break
-					}
-				}
+	jw := newJSONWriter(w)
+	jw.Open("", "[")
+
+	err = db.IterateHostsContext(ctx, datastructs.Page{}, func(host *datastructs.Host) error {
+		if !host.Enabled || !host.Monitored || (len(groups) > 0 && !inGroups(host, groups)) {
+			return nil
+		}
+
+		// hosts are monitored through their enabled and monitored direct groups
+		var monitoredGroups []string
+
+		for j := range allGroups {
+			if allGroups[j].Enabled && allGroups[j].Monitored && host.DirectGroups.Contains(allGroups[j].Name) {
+				monitoredGroups = append(monitoredGroups, allGroups[j].Name)
 			}
 		}
-	}
 
-	promSDFile, err = json.MarshalIndent(prom, "", "    ")
+		if len(monitoredGroups) == 0 {
+			return nil
+		}
+
+		pHost := datastructs.Prometheus{}
+		pHost.Targets = []string{host.Hostname + "." + host.Domain}
+
+		if address, ok := addresses[host.ID].Get(iface); ok && iface != "" {
+			pHost.Targets = []string{datastructs.FormatIP(address.Address)}
+		}
+
+		pHost.Labels.Group = datastructs.NewGroupNames(monitoredGroups).String()
+		pHost.Labels.InheritedGroups = host.InheritedGroups
+		jw.Value("", pHost)
+
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return promSDFile, err
+	jw.Close("]")
+
+	return jw.Flush()
 }
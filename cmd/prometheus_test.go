@@ -2,8 +2,17 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/datastructs"
 )
 
 var promSD = `[
@@ -55,14 +64,201 @@ func Test_genPrometheusSDFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPromSDFile, err := genPrometheusSDFile()
+			gotPromSDFile, err := prometheusSDFileBytes(context.Background(), "")
 			if (err != nil) != tt.wantErr {
-				t.Errorf("genPrometheusSDFile() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("writePrometheusSDFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(gotPromSDFile, tt.wantPromSDFile) {
-				t.Errorf("genPrometheusSDFile() = %s, want %s", gotPromSDFile, tt.wantPromSDFile)
+				t.Errorf("writePrometheusSDFile() = %s, want %s", gotPromSDFile, tt.wantPromSDFile)
 			}
 		})
 	}
 }
+
+func Test_genPrometheusSDFile_multipleGroups(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	// group4 is not a direct group of any host, disable its monitoring to exclude it from the label
+	group4 := testGroup4
+	group4.Monitored = false
+
+	if err := createGroup(context.Background(), DB, &group4); err != nil {
+		t.Fatal(err)
+	}
+
+	err := setHostGroups(context.Background(), DB, &testHost1, datastructs.GroupNames{"group1", "group2", "group4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := prometheusSDFileBytes(context.Background(), "")
+	if err != nil {
+		t.Fatalf("writePrometheusSDFile() error = %v", err)
+	}
+
+	if !strings.Contains(string(got), `"group": "group1,group2"`) {
+		t.Errorf("writePrometheusSDFile() = %s, want host1 group label group1,group2", got)
+	}
+}
+
+func Test_genPrometheusSDFile_interface(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	err := setHostAddresses(context.Background(), DB, &testHost1,
+		datastructs.Addresses{{Name: "management", Address: "10.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := prometheusSDFileBytes(context.Background(), "management")
+	if err != nil {
+		t.Fatalf("writePrometheusSDFile() error = %v", err)
+	}
+
+	var prom []datastructs.Prometheus
+	if err = json.Unmarshal(got, &prom); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(prom) != 3 || prom[0].Targets[0] != "10.0.0.1" || prom[1].Targets[0] != "host2.domain.local" {
+		t.Errorf("writePrometheusSDFile() = %s, want host1 targeted by its management address", got)
+	}
+}
+
+func Test_genPrometheusSDFile_IPv6Interface(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	err := setHostAddresses(context.Background(), DB, &testHost1,
+		datastructs.Addresses{{Name: "management", Address: "2001:db8::1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := prometheusSDFileBytes(context.Background(), "management")
+	if err != nil {
+		t.Fatalf("writePrometheusSDFile() error = %v", err)
+	}
+
+	if !strings.Contains(string(got), `"[2001:db8::1]"`) {
+		t.Errorf("writePrometheusSDFile() = %s, want IPv6 target in brackets", got)
+	}
+}
+
+func Test_prometheusHandler(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	handler := basicAuth("prometheus", "secret", prometheusHandler(DB, "", time.Minute))
+
+	// the ETag of the whole targets list, to be revalidated
+	req := httptest.NewRequest(http.MethodGet, "/prometheus", nil)
+	req.SetBasicAuth("prometheus", "secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+
+	tests := []struct {
+		name        string
+		method      string
+		query       string
+		password    string
+		ifNoneMatch string
+		wantStatus  int
+		wantTargets []string
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		{
+			name: "all targets", query: "", password: "secret", wantStatus: http.StatusOK, wantBody: promSD,
+			wantHeaders: map[string]string{"Content-Type": "application/json", "Cache-Control": "max-age=60"},
+		},
+		{
+			name: "inherited group", query: "?group=group4", password: "secret", wantStatus: http.StatusOK,
+			wantTargets: []string{"host3.domain.local"},
+		},
+		{
+			name: "any of the groups", query: "?group=group1&group=group2", password: "secret",
+			wantStatus: http.StatusOK, wantTargets: []string{"host1.domain.local", "host2.domain.local"},
+		},
+		{
+			name: "group without hosts", query: "?group=group10", password: "secret", wantStatus: http.StatusOK,
+			wantBody: "[]",
+		},
+		{
+			name: "not modified", query: "", password: "secret", ifNoneMatch: etag, wantStatus: http.StatusNotModified,
+		},
+		{
+			name: "wrong password", query: "", password: "guess", wantStatus: http.StatusUnauthorized,
+			wantHeaders: map[string]string{"WWW-Authenticate": `Basic realm="admiral"`},
+		},
+		{
+			name: "method not allowed", method: http.MethodPost, password: "secret",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "/prometheus"+tt.query, nil)
+			req.SetBasicAuth("prometheus", tt.password)
+
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("prometheusHandler() status = %v, want %v: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			for header, want := range tt.wantHeaders {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("prometheusHandler() header %v = %q, want %q", header, got, want)
+				}
+			}
+
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("prometheusHandler() = %s, want %s", rec.Body, tt.wantBody)
+			}
+
+			if tt.wantTargets == nil {
+				return
+			}
+
+			var prom []datastructs.Prometheus
+			if err := json.Unmarshal(rec.Body.Bytes(), &prom); err != nil {
+				t.Fatal(err)
+			}
+
+			var targets []string
+			for _, p := range prom {
+				targets = append(targets, p.Targets...)
+			}
+
+			if !reflect.DeepEqual(targets, tt.wantTargets) {
+				t.Errorf("prometheusHandler() targets = %v, want %v", targets, tt.wantTargets)
+			}
+		})
+	}
+}
+
+func prometheusSDFileBytes(ctx context.Context, iface string, groups ...string) ([]byte, error) {
+	var b bytes.Buffer
+	err := writePrometheusSDFile(ctx, DB, &b, iface, groups)
+
+	return b.Bytes(), err
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var (
+	restoreFile string
+	restoreWipe bool
+)
+
+func init() {
+	rootCmd.AddCommand(restore)
+	restore.Flags().StringVarP(&restoreFile, "file", "f", "",
+		"restore the inventory from a file written by admiral dump, same as the file argument")
+	restore.Flags().BoolVar(&restoreWipe, "wipe", false,
+		"replace the stored inventory, including the trash, with the dump")
+
+	restore.AddCommand(restoreHostVar)
+	restore.AddCommand(restoreGroupVar)
+}
+
+var restore = &cobra.Command{
+	Use:   "restore {file path} [--wipe]",
+	Short: "restore deleted record from the trash or the inventory from a dump",
+	Long: "restore deleted host or group from the trash together with its relationships, see `admiral trash list`." +
+		" Given a file written by `admiral dump`, restore the whole inventory in a single transaction. The" +
+		" inventory must hold no hosts or groups unless --wipe is passed. A dump file named like a subcommand" +
+		" is passed with --file",
+	Example: "admiral restore host host1\nadmiral restore backup.json\nadmiral restore backup.json --wipe",
+	Args: func(cmd *cobra.Command, args []string) error {
+		_, err := restorePath(args, restoreFile)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		path, _ := restorePath(args, restoreFile)
+
+		if err := restoreDumpCase(cmd.Context(), path, restoreWipe); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// restorePath return the dump file given either as argument or with --file
+func restorePath(args []string, file string) (string, error) {
+	switch {
+	case len(args) > 1:
+		return "", fmt.Errorf("accepts a single dump file, received %v", len(args))
+	case len(args) == 1 && file != "":
+		return "", fmt.Errorf("the dump file is given both as argument and with --file")
+	case len(args) == 1:
+		return args[0], nil
+	case file == "":
+		return "", fmt.Errorf("expecting the dump file to restore, e.g. admiral restore backup.json")
+	}
+
+	return file, nil
+}
+
+func restoreDumpCase(ctx context.Context, path string, wipe bool) error {
+	dump, err := readDump(path)
+	if err != nil {
+		return err
+	}
+
+	want := dumpCounts(&dump)
+
+	if wipe {
+		stored, err := countInventory(ctx, DB)
+		if err != nil {
+			return err
+		}
+
+		printInventoryCounts([]string{"Stored", "Dump"}, stored, want)
+		fmt.Println("the stored inventory, including the trash, will be replaced by the dump")
+	} else {
+		if err = checkEmpty(ctx, DB, "inventory"); err != nil {
+			return fmt.Errorf("%w, pass --wipe to replace it", err)
+		}
+
+		printInventoryCounts([]string{"Dump"}, want)
+	}
+
+	if !User.confirm() {
+		return fmt.Errorf("aborted")
+	}
+
+	return inTx(ctx, func(tx database.Querier) error {
+		if wipe {
+			if err := wipeInventory(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		if err := loadInventory(ctx, tx, &dump); err != nil {
+			return err
+		}
+
+		got, err := countInventory(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		printInventoryCounts([]string{"Dump", "Restored"}, want, got)
+
+		if got != want {
+			return fmt.Errorf("the number of restored records differ from the dump, the restore was rolled back")
+		}
+
+		return nil
+	})
+}
+
+// readDump read a dump file written by `admiral dump`, with the variables ready to be stored
+func readDump(path string) (dump datastructs.Dump, err error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return dump, err
+	}
+
+	if err = json.Unmarshal(file, &dump); err != nil {
+		return dump, err
+	}
+
+	if dump.Version == 0 {
+		return dump, fmt.Errorf("%v is not an admiral dump", path)
+	} else if dump.Version > datastructs.DumpVersion {
+		return dump, fmt.Errorf("%v is a version %v dump, this admiral read dumps up to version %v, please upgrade",
+			path, dump.Version, datastructs.DumpVersion)
+	}
+
+	for i := range dump.Groups {
+		if err = dump.Groups[i].MarshalVars(); err != nil {
+			return dump, err
+		}
+	}
+
+	for i := range dump.Hosts {
+		if err = dump.Hosts[i].MarshalVars(); err != nil {
+			return dump, err
+		}
+	}
+
+	return dump, nil
+}
+
+// wipeInventory delete all hosts and groups and purge them from the trash together with their relationships
+func wipeInventory(ctx context.Context, db database.Querier) error {
+	hosts, err := db.GetHostsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range hosts {
+		if _, err = db.DeleteHostContext(ctx, &hosts[i]); err != nil {
+			return err
+		}
+	}
+
+	groups, err := db.GetGroupsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range groups {
+		if _, err = db.DeleteGroupContext(ctx, &groups[i]); err != nil {
+			return err
+		}
+	}
+
+	if hosts, err = db.GetDeletedHostsContext(ctx); err != nil {
+		return err
+	}
+
+	for i := range hosts {
+		if _, err = db.PurgeHostContext(ctx, &hosts[i]); err != nil {
+			return err
+		}
+	}
+
+	if groups, err = db.GetDeletedGroupsContext(ctx); err != nil {
+		return err
+	}
+
+	for i := range groups {
+		if _, err = db.PurgeGroupContext(ctx, &groups[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var restoreHostVar = &cobra.Command{
+	Use:     "host hostname",
+	Short:   "restore deleted host",
+	Long:    "restore deleted host together with its group memberships",
+	Example: "admiral restore host host1",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := restoreHostCase(cmd.Context(), args); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func restoreHostCase(ctx context.Context, args []string) error {
+	host, err := deletedHost(ctx, DB, args[0])
+	if err != nil {
+		return err
+	}
+
+	printTrash([]datastructs.Host{host}, nil)
+
+	if !User.confirm() {
+		return fmt.Errorf("aborted")
+	}
+
+	var affected int64
+
+	err = inTx(ctx, func(tx database.Querier) (err error) {
+		affected, err = restoreHost(ctx, tx, &host)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("lines restored %v\n", affected)
+
+	return nil
+}
+
+// deletedHost return the host with the exact hostname from the trash
+func deletedHost(ctx context.Context, db database.Querier, hostname string) (host datastructs.Host, err error) {
+	hosts, err := db.GetDeletedHostsContext(ctx)
+	if err != nil {
+		return host, err
+	}
+
+	for i := range hosts {
+		if hosts[i].Hostname == hostname {
+			return hosts[i], nil
+		}
+	}
+
+	return host, fmt.Errorf("no host matched request")
+}
+
+func restoreHost(ctx context.Context, db database.Querier, host *datastructs.Host) (affected int64, err error) {
+	affected, err = db.RestoreHostContext(ctx, host)
+	if err != nil {
+		return affected, err
+	} else if affected == 0 {
+		return affected, fmt.Errorf("no record matched")
+	}
+
+	return affected, nil
+}
+
+var restoreGroupVar = &cobra.Command{
+	Use:     "group 'group name'",
+	Short:   "restore deleted group",
+	Long:    "restore deleted group together with its hosts memberships and child-group relationships",
+	Example: "admiral restore group group1",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := restoreGroupCase(cmd.Context(), args); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func restoreGroupCase(ctx context.Context, args []string) error {
+	group, err := deletedGroup(ctx, DB, args[0])
+	if err != nil {
+		return err
+	}
+
+	printTrash(nil, []datastructs.Group{group})
+
+	if !User.confirm() {
+		return fmt.Errorf("aborted")
+	}
+
+	var affected int64
+
+	err = inTx(ctx, func(tx database.Querier) (err error) {
+		affected, err = restoreGroup(ctx, tx, &group)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("lines restored %v\n", affected)
+
+	return nil
+}
+
+// deletedGroup return the group with the exact name from the trash
+func deletedGroup(ctx context.Context, db database.Querier, name string) (group datastructs.Group, err error) {
+	groups, err := db.GetDeletedGroupsContext(ctx)
+	if err != nil {
+		return group, err
+	}
+
+	for i := range groups {
+		if groups[i].Name == name {
+			return groups[i], nil
+		}
+	}
+
+	return group, fmt.Errorf("no group matched request")
+}
+
+func restoreGroup(ctx context.Context, db database.Querier, group *datastructs.Group) (affected int64, err error) {
+	affected, err = db.RestoreGroupContext(ctx, group)
+	if err != nil {
+		return affected, err
+	} else if affected == 0 {
+		return affected, fmt.Errorf("no record matched")
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func init() {
+	rootCmd.AddCommand(revertVar)
+}
+
+var revertVar = &cobra.Command{
+	Use:   "revert change-id",
+	Short: "revert a change recorded in the audit log",
+	Long: "restore the host, group or relationship to its state before the change, use `admiral history` to find" +
+		" the change ID. Reverting the deletion of a host or group restores it from the trash",
+	Example: "admiral history host host1\nadmiral revert 42",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := revertCase(cmd.Context(), args); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func revertCase(ctx context.Context, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid change id %q", args[0])
+	}
+
+	change, err := DB.SelectChangeContext(ctx, id)
+	if err != nil {
+		return err
+	} else if change.ID == 0 {
+		return fmt.Errorf("no change matched request")
+	}
+
+	printChanges([]datastructs.Change{change})
+
+	if !User.confirm() {
+		return fmt.Errorf("aborted")
+	}
+
+	err = inTx(ctx, func(tx database.Querier) error {
+		return revertChange(ctx, tx, &change)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("change reverted %v\n", change.ID)
+
+	return nil
+}
+
+func revertChange(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	if change.Action == datastructs.ActionPurge {
+		return fmt.Errorf("%v %v was purged from the trash and cannot be restored", change.Entity, change.Name)
+	}
+
+	switch change.Entity {
+	case datastructs.EntityHost:
+		return revertHost(ctx, db, change)
+	case datastructs.EntityGroup:
+		return revertGroup(ctx, db, change)
+	case datastructs.EntityChildGroup:
+		return revertChildGroup(ctx, db, change)
+	case datastructs.EntityHostGroup:
+		return revertHostGroups(ctx, db, change)
+	case datastructs.EntityAddress:
+		return revertAddresses(ctx, db, change)
+	default:
+		return fmt.Errorf("cannot revert change of unknown entity %q", change.Entity)
+	}
+}
+
+// revertHost delete a host created by the change or restore its previous state, restoring it from the trash if needed
+func revertHost(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	if change.Before == "" {
+		host, err := db.SelectHostContext(ctx, change.Name)
+		if err != nil || host.ID == 0 {
+			return err
+		}
+
+		_, err = deleteHost(ctx, db, &host)
+
+		return err
+	}
+
+	if deleted, err := deletedHost(ctx, db, change.Name); err == nil {
+		if _, err = restoreHost(ctx, db, &deleted); err != nil {
+			return err
+		}
+	}
+
+	var host datastructs.Host
+
+	if err := json.Unmarshal([]byte(change.Before), &host); err != nil {
+		return err
+	}
+
+	if err := host.MarshalVars(); err != nil {
+		return err
+	}
+
+	// the previous state replace the stored one, whatever revision it was recorded at
+	host.Revision = 0
+
+	err := createHost(ctx, db, &host)
+	if err != nil && err.Error() != "no lines affected" {
+		return err
+	}
+
+	return nil
+}
+
+// revertGroup delete a group created by the change or restore its previous state, restoring it from the trash if needed
+func revertGroup(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	if change.Before == "" {
+		group, err := db.SelectGroupContext(ctx, change.Name)
+		if err != nil || group.ID == 0 {
+			return err
+		}
+
+		_, err = deleteGroup(ctx, db, &group)
+
+		return err
+	}
+
+	if deleted, err := deletedGroup(ctx, db, change.Name); err == nil {
+		if _, err = restoreGroup(ctx, db, &deleted); err != nil {
+			return err
+		}
+	}
+
+	var group datastructs.Group
+
+	if err := json.Unmarshal([]byte(change.Before), &group); err != nil {
+		return err
+	}
+
+	if err := group.MarshalVars(); err != nil {
+		return err
+	}
+
+	// the previous state replace the stored one, whatever revision it was recorded at
+	group.Revision = 0
+
+	err := createGroup(ctx, db, &group)
+	if err != nil && err.Error() != "no lines affected" {
+		return err
+	}
+
+	return nil
+}
+
+// revertChildGroup delete a relationship created by the change or restore a deleted one
+func revertChildGroup(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	childGroups, err := db.SelectChildGroupContext(ctx, change.Name, change.Related)
+	if err != nil {
+		return err
+	}
+
+	if change.Before == "" {
+		if len(childGroups) == 0 {
+			return nil
+		}
+
+		_, err = deleteChildGroup(ctx, db, &childGroups[0])
+
+		return err
+	} else if len(childGroups) > 0 {
+		return nil
+	}
+
+	child, err := viewGroupByName(ctx, db, change.Name)
+	if err != nil {
+		return err
+	}
+
+	parent, err := viewGroupByName(ctx, db, change.Related)
+	if err != nil {
+		return err
+	}
+
+	return createChildGroup(ctx, db, &parent, &child)
+}
+
+// revertHostGroups restore the host group memberships to their state before the change
+func revertHostGroups(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	var before []datastructs.HostGroup
+
+	if change.Before != "" {
+		if err := json.Unmarshal([]byte(change.Before), &before); err != nil {
+			return err
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, hg := range before {
+		wanted[hg.Group] = true
+	}
+
+	current, err := db.SelectHostGroupContext(ctx, change.Name)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+
+	for i := range current {
+		if wanted[current[i].Group] {
+			existing[current[i].Group] = true
+			continue
+		}
+
+		if _, err = deleteHostGroup(ctx, db, &current[i]); err != nil {
+			return err
+		}
+	}
+
+	if len(existing) == len(wanted) {
+		return nil
+	}
+
+	host, err := db.SelectHostContext(ctx, change.Name)
+	if err != nil {
+		return err
+	} else if host.ID == 0 {
+		return fmt.Errorf("host %v no longer exists, revert its deletion first", change.Name)
+	}
+
+	for _, hg := range before {
+		if existing[hg.Group] {
+			continue
+		}
+
+		group, err := viewGroupByName(ctx, db, hg.Group)
+		if err != nil {
+			return err
+		}
+
+		if err = createHostGroup(ctx, db, &host, &group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revertAddresses set the host addresses to the addresses it had before the change
+func revertAddresses(ctx context.Context, db database.Querier, change *datastructs.Change) error {
+	before := datastructs.Addresses{}
+
+	if change.Before != "" {
+		if err := json.Unmarshal([]byte(change.Before), &before); err != nil {
+			return err
+		}
+	}
+
+	host, err := db.SelectHostContext(ctx, change.Name)
+	if err != nil {
+		return err
+	} else if host.ID == 0 {
+		return fmt.Errorf("host %v no longer exists, revert its deletion first", change.Name)
+	}
+
+	return setHostAddresses(ctx, db, &host, before)
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/via-justa/admiral/config"
 	"github.com/via-justa/admiral/database"
@@ -52,17 +58,100 @@ func init() {
 func Execute() {
 	var err error
 
+	os.Args = inventoryScriptArgs(os.Args)
+
 	Conf = config.NewConfig()
 	User = newUser()
 
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	// shell completion cannot ask the user, an unknown ssh proxy then fails the completion
+	if os.Args[1] != cobra.ShellCompRequestCmd && os.Args[1] != cobra.ShellCompNoDescRequestCmd {
+		database.SSHPrompter = sshPrompter{}
+	}
+
 	if os.Args[1] != "docs" && os.Args[1] != "completion" {
-		DB, err = database.Connect(Conf)
+		DB, err = database.ConnectContext(ctx, Conf)
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		if os.Args[1] != "db" {
+			warnPendingMigrations()
+		}
+	}
+
+	err = rootCmd.ExecuteContext(ctx)
+
+	if DB != nil {
+		DB.Close() // nolint: errcheck,gosec
 	}
 
-	if err = rootCmd.Execute(); err != nil {
+	cancel()
+
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	} else if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// exitCodeError is returned by the commands ending with the exit code of the process they ran, Execute exits
+// with the same code once the database is closed
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %v", e.code)
+}
+
+// interruptContext return a context canceled on the first Ctrl-C, aborting the running database
+// calls and rolling back open transactions. A second Ctrl-C terminates admiral right away, e.g.
+// while it waits for user input
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-interrupt:
+			log.Println("interrupted, press Ctrl-C again to exit immediately")
+		case <-ctx.Done():
+		}
+
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// inTx run fn in a single database transaction. The changes are committed only if fn
+// returns without error so a failing command leaves the database untouched
+func inTx(ctx context.Context, fn func(tx database.Querier) error) error {
+	return inTxOf(ctx, DB, fn)
+}
+
+// inTxOf is inTx on the given database
+func inTxOf(ctx context.Context, db database.DBInterface, fn func(tx database.Querier) error) error {
+	tx, err := db.BeginContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		// a canceled context already rolled the transaction back
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			log.Printf("rollback failed: %v", rbErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
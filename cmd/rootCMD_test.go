@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func Test_inTx(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		fnErr     error
+		wantErr   bool
+		wantSaved bool
+	}{
+		{
+			name:      "commit on success",
+			ctx:       context.Background(),
+			fnErr:     nil,
+			wantErr:   false,
+			wantSaved: true,
+		},
+		{
+			name:      "rollback on error",
+			ctx:       context.Background(),
+			fnErr:     fmt.Errorf("failed"),
+			wantErr:   true,
+			wantSaved: false,
+		},
+		{
+			name:      "canceled context",
+			ctx:       canceled,
+			fnErr:     nil,
+			wantErr:   true,
+			wantSaved: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := datastructs.Group{Name: "tx-group", Variables: "{}", Enabled: true, Monitored: true}
+
+			err := inTx(tt.ctx, func(tx database.Querier) error {
+				if err := createGroup(tt.ctx, tx, &group); err != nil {
+					return err
+				}
+
+				return tt.fnErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("inTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, _ := DB.SelectGroup(group.Name)
+			if (got.ID != 0) != tt.wantSaved {
+				t.Errorf("inTx() saved = %v, want %v", got.ID != 0, tt.wantSaved)
+			}
+
+			_, _ = DB.DeleteGroup(&got)
+		})
+	}
+}
@@ -0,0 +1,974 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var listenAddr string
+
+const (
+	// shutdownTimeout bound the wait for the running requests once the server is stopped
+	shutdownTimeout = 10 * time.Second
+	// maxBodySize is the largest accepted request body
+	maxBodySize = 1 << 20
+)
+
+func init() {
+	rootCmd.AddCommand(serve)
+
+	serve.Flags().StringVarP(&listenAddr, "listen", "l", "localhost:8080", "address the API listens on")
+}
+
+var serve = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the inventory over an HTTP JSON API",
+	Long: "Serve the hosts, groups, child-group relationships and host-group memberships over an HTTP JSON API," +
+		" along with the ansible inventory and the prometheus SD file. The database functions are served as well" +
+		" to the admiral clients configured with the remote section. Requests must carry one of the [serve.tokens]" +
+		" as bearer token when any is configured, the database functions are refused without tokens. The server" +
+		" stops on Ctrl-C or SIGTERM once the running requests are done",
+	Example: "admiral serve\nadmiral serve --listen :8080\ncurl localhost:8080/hosts/host1",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Printf("serving the inventory API on %v", listenAddr)
+
+		if err := listenAndServe(cmd.Context(), listenAddr, tokenAuth(Conf.Serve.Tokens, newAPI(DB))); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// listenAndServe serve handler on addr until ctx is done, the running requests are then given
+// shutdownTimeout to complete
+func listenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           logRequests(handler),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+// statusRecorder keep the response status for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests log every request with its response status and duration
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		log.Printf("%v %v %v %d %v", r.RemoteAddr, r.Method, r.URL.RequestURI(), rec.status,
+			time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// basicAuth require the basic auth user and password on every request, no authentication is required when user
+// is empty
+func basicAuth(user, password string, next http.Handler) http.Handler {
+	if user == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admiral"`)
+			writeError(w, apiError{status: http.StatusUnauthorized, err: errors.New("unauthorized")})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenAuth require a bearer token of tokens on every request, the changes made by the request are recorded in
+// the audit log as made by the name of the token. No authentication is required when there are no tokens, the
+// database functions of the remote backend are then refused as they give full write access to the inventory
+func tokenAuth(tokens map[string]string, next http.Handler) http.Handler {
+	if len(tokens) == 0 {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, database.RPCPath) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admiral"`)
+				writeError(w, apiError{status: http.StatusUnauthorized,
+					err: errors.New("the remote backend requires the server to configure [serve.tokens]")})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") {
+			given := []byte(strings.TrimPrefix(auth, "Bearer "))
+
+			for name, token := range tokens {
+				if token != "" && subtle.ConstantTimeCompare(given, []byte(token)) == 1 {
+					next.ServeHTTP(w, r.WithContext(database.WithActor(r.Context(), name)))
+					return
+				}
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admiral"`)
+		writeError(w, apiError{status: http.StatusUnauthorized, err: errors.New("unauthorized")})
+	})
+}
+
+// apiError is an error returned to the client with its http status
+type apiError struct {
+	status int
+	err    error
+}
+
+func (e apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e apiError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(format string, a ...interface{}) error {
+	return apiError{status: http.StatusBadRequest, err: fmt.Errorf(format, a...)}
+}
+
+func notFound(format string, a ...interface{}) error {
+	return apiError{status: http.StatusNotFound, err: fmt.Errorf(format, a...)}
+}
+
+func conflict(format string, a ...interface{}) error {
+	return apiError{status: http.StatusConflict, err: fmt.Errorf(format, a...)}
+}
+
+// errorStatus return the http status of err, internal server error unless it is an apiError or a revision conflict
+func errorStatus(err error) int {
+	var apiErr apiError
+
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.status
+	case errors.Is(err, datastructs.ErrRevisionConflict):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// route is an API endpoint, matched by the request method and the number of path elements after the resource
+type route struct {
+	method string
+	args   int
+	fn     func(r *http.Request, args []string) (status int, v interface{}, err error)
+}
+
+// api serve the inventory of db
+type api struct {
+	db  database.DBInterface
+	mux *http.ServeMux
+}
+
+// newAPI return the handler of the inventory API:
+//
+//	GET, POST         /hosts                    list (?limit=, ?offset=, ?where=) or create hosts
+//	GET, PUT, DELETE  /hosts/{hostname}
+//	GET, POST         /groups                   list (?limit=, ?offset=, ?where=) or create groups
+//	GET, PUT, DELETE  /groups/{name}
+//	GET, POST         /children                 list or create child-group relationships
+//	GET, DELETE       /children/{child}/{parent}
+//	GET, POST         /hostgroups               list or create host-group memberships
+//	GET, PUT          /hostgroups/{hostname}    list or replace the groups of the host
+//	DELETE            /hostgroups/{hostname}/{group}
+//	GET               /inventory                the ansible inventory
+//	GET               /prometheus               the prometheus targets (?interface=, ?group=)
+//	POST              /rpc/{function}           the database functions of the remote backend
+func newAPI(db database.DBInterface) http.Handler {
+	a := &api{db: db, mux: http.NewServeMux()}
+
+	a.handle("/hosts",
+		route{http.MethodGet, 0, a.listHosts},
+		route{http.MethodPost, 0, a.createHost},
+		route{http.MethodGet, 1, a.getHost},
+		route{http.MethodPut, 1, a.updateHost},
+		route{http.MethodDelete, 1, a.deleteHost})
+	a.handle("/groups",
+		route{http.MethodGet, 0, a.listGroups},
+		route{http.MethodPost, 0, a.createGroup},
+		route{http.MethodGet, 1, a.getGroup},
+		route{http.MethodPut, 1, a.updateGroup},
+		route{http.MethodDelete, 1, a.deleteGroup})
+	a.handle("/children",
+		route{http.MethodGet, 0, a.listChildGroups},
+		route{http.MethodPost, 0, a.createChildGroup},
+		route{http.MethodGet, 2, a.getChildGroup},
+		route{http.MethodDelete, 2, a.deleteChildGroup})
+	a.handle("/hostgroups",
+		route{http.MethodGet, 0, a.listHostGroups},
+		route{http.MethodPost, 0, a.createHostGroup},
+		route{http.MethodGet, 1, a.getHostGroups},
+		route{http.MethodPut, 1, a.updateHostGroups},
+		route{http.MethodDelete, 2, a.deleteHostGroup})
+
+	a.mux.HandleFunc("/inventory", func(w http.ResponseWriter, r *http.Request) {
+		a.stream(w, r, func() error {
+			return writeInventory(r.Context(), a.db, w)
+		})
+	})
+	a.mux.Handle("/prometheus", prometheusHandler(db, "", 0))
+	a.mux.Handle(database.RPCPath, database.RPCHandler(db))
+
+	return a.mux
+}
+
+// handle register the routes of the resource, the path elements after the resource are passed to the route
+func (a *api) handle(resource string, routes ...route) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var args []string
+		if p := strings.Trim(strings.TrimPrefix(r.URL.Path, resource), "/"); p != "" {
+			args = strings.Split(p, "/")
+		}
+
+		var allowed []string
+
+		for _, rt := range routes {
+			if rt.args != len(args) {
+				continue
+			}
+
+			if rt.method != r.Method {
+				allowed = append(allowed, rt.method)
+				continue
+			}
+
+			status, v, err := rt.fn(r, args)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			writeJSON(w, status, v)
+
+			return
+		}
+
+		if len(allowed) == 0 {
+			writeError(w, notFound("%v not found", r.URL.Path))
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, apiError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("method %v not allowed",
+			r.Method)})
+	}
+
+	a.mux.HandleFunc(resource, handler)
+	a.mux.HandleFunc(resource+"/", handler)
+}
+
+// stream write the document written by fn. Errors after the response started cannot change its status, the
+// document is then left incomplete and the error logged
+func (a *api) stream(w http.ResponseWriter, r *http.Request, fn func() error) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, apiError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("method %v not allowed",
+			r.Method)})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := fn(); err != nil {
+		log.Printf("%v %v: %v", r.Method, r.URL.RequestURI(), err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(b, '\n')) // nolint: errcheck,gosec
+}
+
+// writeError write err as {"error": "message"}
+func writeError(w http.ResponseWriter, err error) {
+	b, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errorStatus(err))
+	w.Write(append(b, '\n')) // nolint: errcheck,gosec
+}
+
+// decodeBody decode the json request body into v, fields missing from the body keep their value in v
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize)).Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+// decodeUpdate decode the request body onto the stored record v, the fields missing from the body keep their
+// stored value. json merges an object into a map, so the fields of reset present in the body are reset first
+// and replace the stored value
+func decodeUpdate(r *http.Request, v interface{}, reset map[string]func()) error {
+	b, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	if err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+
+	for field := range fields {
+		if fn, ok := reset[field]; ok {
+			fn()
+		}
+	}
+
+	if err = json.Unmarshal(b, v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+// queryFilter return the page and filter of the limit, offset and where query parameters, the filter is validated
+// against fields
+func queryFilter(r *http.Request, fields []string) (page datastructs.Page, filter datastructs.Filter, err error) {
+	query := r.URL.Query()
+
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{{"limit", &page.Limit}, {"offset", &page.Offset}} {
+		if v := query.Get(p.name); v != "" {
+			if *p.value, err = strconv.Atoi(v); err != nil {
+				return page, filter, badRequest("invalid %v %q", p.name, v)
+			}
+		}
+	}
+
+	if err = page.Validate(); err != nil {
+		return page, filter, badRequest("%v", err)
+	}
+
+	if filter, err = datastructs.ParseFilter(query.Get("where")); err != nil {
+		return page, filter, badRequest("%v", err)
+	}
+
+	if err = filter.Validate(fields); err != nil {
+		return page, filter, badRequest("%v", err)
+	}
+
+	return page, filter, nil
+}
+
+// hosts
+
+// storedHost return the host with its addresses and variables, not found error if there is no such host
+func (a *api) storedHost(ctx context.Context, db database.Querier, hostname string) (host datastructs.Host,
+	err error) {
+	host, err = db.SelectHostContext(ctx, hostname)
+	if err != nil {
+		return host, err
+	} else if host.Hostname == "" {
+		return host, notFound("host %v does not exists", hostname)
+	}
+
+	if host.Addresses, err = db.SelectAddressesContext(ctx, hostname); err != nil {
+		return host, err
+	}
+
+	if host.Addresses == nil {
+		host.Addresses = datastructs.Addresses{}
+	}
+
+	return host, host.UnmarshalVars()
+}
+
+func (a *api) listHosts(r *http.Request, args []string) (int, interface{}, error) {
+	page, filter, err := queryFilter(r, datastructs.HostFilterFields)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	hosts := datastructs.Hosts{}
+
+	if filter.IsZero() {
+		err = a.db.IterateHostsContext(r.Context(), page, func(host *datastructs.Host) error {
+			hosts = append(hosts, *host)
+			return nil
+		})
+	} else {
+		var filtered datastructs.Hosts
+
+		filtered, err = a.db.FilterHostsContext(r.Context(), filter)
+		if err == nil {
+			err = filtered.Sort("hostname")
+
+			start, end := page.Bounds(len(filtered))
+			hosts = append(hosts, filtered[start:end]...)
+		}
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err = loadAddresses(r.Context(), a.db, hosts); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range hosts {
+		if err = hosts[i].UnmarshalVars(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return http.StatusOK, hosts, nil
+}
+
+func (a *api) getHost(r *http.Request, args []string) (int, interface{}, error) {
+	host, err := a.storedHost(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, host, nil
+}
+
+// validHost return bad request error if the host cannot be stored
+func validHost(host *datastructs.Host) error {
+	if host.Hostname == "" || host.Host == "" {
+		return badRequest("missing mandatory field ip or hostname")
+	}
+
+	if _, err := datastructs.ParseIP(host.Host); err != nil {
+		return badRequest("%v", err)
+	}
+
+	if err := host.Addresses.Validate(); err != nil {
+		return badRequest("%v", err)
+	}
+
+	return nil
+}
+
+// writeHost store the host with its addresses and direct groups and return the stored host
+func (a *api) writeHost(ctx context.Context, host *datastructs.Host) (stored datastructs.Host, err error) {
+	if err = host.MarshalVars(); err != nil {
+		return stored, err
+	}
+
+	host.DirectGroups = datastructs.NewGroupNames(host.DirectGroups)
+
+	err = inTxOf(ctx, a.db, func(tx database.Querier) error {
+		for _, name := range host.DirectGroups {
+			if group, err := tx.SelectGroupContext(ctx, name); err != nil {
+				return err
+			} else if group.ID == 0 {
+				return badRequest("group %v does not exists", name)
+			}
+		}
+
+		hosts := datastructs.Hosts{*host}
+		if err := confirmedHosts(ctx, tx, &hosts); err != nil {
+			return err
+		}
+
+		stored, err = a.storedHost(ctx, tx, host.Hostname)
+
+		return err
+	})
+
+	return stored, err
+}
+
+func (a *api) createHost(r *http.Request, args []string) (int, interface{}, error) {
+	host := Conf.NewDefaultHost()
+	if err := host.UnmarshalVars(); err != nil {
+		return 0, nil, err
+	}
+
+	if err := decodeBody(r, &host); err != nil {
+		return 0, nil, err
+	}
+
+	if err := validHost(&host); err != nil {
+		return 0, nil, err
+	}
+
+	if existing, err := a.db.SelectHostContext(r.Context(), host.Hostname); err != nil {
+		return 0, nil, err
+	} else if existing.Hostname != "" {
+		return 0, nil, conflict("host %v already exists", host.Hostname)
+	}
+
+	stored, err := a.writeHost(r.Context(), &host)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, stored, nil
+}
+
+// updateHost apply the fields of the request body to the host, a body revision other than the stored one is
+// refused with conflict status
+func (a *api) updateHost(r *http.Request, args []string) (int, interface{}, error) {
+	host, err := a.storedHost(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = decodeUpdate(r, &host, map[string]func(){
+		"variables":     func() { host.PrettyVariables = nil },
+		"direct_groups": func() { host.DirectGroups = nil },
+		"direct_group":  func() { host.DirectGroups = nil },
+		"addresses":     func() { host.Addresses = nil },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if host.Hostname != args[0] {
+		return 0, nil, badRequest("hostname cannot be changed, create a new host instead")
+	}
+
+	if err = validHost(&host); err != nil {
+		return 0, nil, err
+	}
+
+	stored, err := a.writeHost(r.Context(), &host)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, stored, nil
+}
+
+func (a *api) deleteHost(r *http.Request, args []string) (int, interface{}, error) {
+	host, err := a.storedHost(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		_, err := deleteHost(r.Context(), tx, &host)
+		return err
+	})
+
+	return http.StatusNoContent, nil, err
+}
+
+// groups
+
+// storedGroup return the group with its variables, not found error if there is no such group
+func (a *api) storedGroup(ctx context.Context, db database.Querier, name string) (group datastructs.Group,
+	err error) {
+	group, err = db.SelectGroupContext(ctx, name)
+	if err != nil {
+		return group, err
+	} else if group.ID == 0 {
+		return group, notFound("group %v does not exists", name)
+	}
+
+	return group, group.UnmarshalVars()
+}
+
+func (a *api) listGroups(r *http.Request, args []string) (int, interface{}, error) {
+	page, filter, err := queryFilter(r, datastructs.GroupFilterFields)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	groups := datastructs.Groups{}
+
+	if filter.IsZero() {
+		err = a.db.IterateGroupsContext(r.Context(), page, func(group *datastructs.Group) error {
+			groups = append(groups, *group)
+			return nil
+		})
+	} else {
+		var filtered datastructs.Groups
+
+		filtered, err = a.db.FilterGroupsContext(r.Context(), filter)
+		if err == nil {
+			err = filtered.Sort("name")
+
+			start, end := page.Bounds(len(filtered))
+			groups = append(groups, filtered[start:end]...)
+		}
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := range groups {
+		if err = groups[i].UnmarshalVars(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return http.StatusOK, groups, nil
+}
+
+func (a *api) getGroup(r *http.Request, args []string) (int, interface{}, error) {
+	group, err := a.storedGroup(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, group, nil
+}
+
+// writeGroup store the group and return the stored group
+func (a *api) writeGroup(ctx context.Context, group *datastructs.Group) (stored datastructs.Group, err error) {
+	if err = group.MarshalVars(); err != nil {
+		return stored, err
+	}
+
+	err = inTxOf(ctx, a.db, func(tx database.Querier) error {
+		if err := createGroup(ctx, tx, group); err != nil {
+			return err
+		}
+
+		stored, err = a.storedGroup(ctx, tx, group.Name)
+
+		return err
+	})
+
+	return stored, err
+}
+
+func (a *api) createGroup(r *http.Request, args []string) (int, interface{}, error) {
+	group := Conf.NewDefaultGroup()
+	if err := group.UnmarshalVars(); err != nil {
+		return 0, nil, err
+	}
+
+	if err := decodeBody(r, &group); err != nil {
+		return 0, nil, err
+	}
+
+	if group.Name == "" {
+		return 0, nil, badRequest("missing mandatory field name")
+	}
+
+	if existing, err := a.db.SelectGroupContext(r.Context(), group.Name); err != nil {
+		return 0, nil, err
+	} else if existing.ID != 0 {
+		return 0, nil, conflict("group %v already exists", group.Name)
+	}
+
+	stored, err := a.writeGroup(r.Context(), &group)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, stored, nil
+}
+
+// updateGroup apply the fields of the request body to the group, revisions are handled like in updateHost
+func (a *api) updateGroup(r *http.Request, args []string) (int, interface{}, error) {
+	group, err := a.storedGroup(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = decodeUpdate(r, &group, map[string]func(){
+		"variables": func() { group.PrettyVariables = nil },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if group.Name != args[0] {
+		return 0, nil, badRequest("group name cannot be changed, create a new group instead")
+	}
+
+	stored, err := a.writeGroup(r.Context(), &group)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, stored, nil
+}
+
+func (a *api) deleteGroup(r *http.Request, args []string) (int, interface{}, error) {
+	group, err := a.storedGroup(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		_, err := deleteGroup(r.Context(), tx, &group)
+		return err
+	})
+
+	return http.StatusNoContent, nil, err
+}
+
+// child groups
+
+func (a *api) listChildGroups(r *http.Request, args []string) (int, interface{}, error) {
+	childGroups, err := a.db.GetChildGroupsContext(r.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, append(datastructs.ChildGroups{}, childGroups...), nil
+}
+
+// storedChildGroup return the relationship of the child and parent groups, not found error if there is none
+func (a *api) storedChildGroup(ctx context.Context, child, parent string) (childGroup datastructs.ChildGroup,
+	err error) {
+	childGroups, err := a.db.SelectChildGroupContext(ctx, child, parent)
+	if err != nil {
+		return childGroup, err
+	} else if len(childGroups) == 0 {
+		return childGroup, notFound("group %v is not a child of %v", child, parent)
+	}
+
+	return childGroups[0], nil
+}
+
+func (a *api) getChildGroup(r *http.Request, args []string) (int, interface{}, error) {
+	childGroup, err := a.storedChildGroup(r.Context(), args[0], args[1])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, childGroup, nil
+}
+
+func (a *api) createChildGroup(r *http.Request, args []string) (int, interface{}, error) {
+	var body datastructs.ChildGroup
+	if err := decodeBody(r, &body); err != nil {
+		return 0, nil, err
+	}
+
+	if body.Child == "" || body.Parent == "" {
+		return 0, nil, badRequest("missing mandatory field child or parent")
+	}
+
+	child, err := viewGroupByName(r.Context(), a.db, body.Child)
+	if err != nil {
+		return 0, nil, badRequest("group %v does not exists", body.Child)
+	}
+
+	parent, err := viewGroupByName(r.Context(), a.db, body.Parent)
+	if err != nil {
+		return 0, nil, badRequest("group %v does not exists", body.Parent)
+	}
+
+	if _, err = a.storedChildGroup(r.Context(), child.Name, parent.Name); err == nil {
+		return 0, nil, conflict("group %v is already a child of %v", child.Name, parent.Name)
+	}
+
+	if child.ID == parent.ID || isRelationshipLoop(&parent, &child) {
+		return 0, nil, badRequest("group %v cannot be a child of %v, it would create a relationship loop",
+			child.Name, parent.Name)
+	}
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		return createChildGroup(r.Context(), tx, &parent, &child)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	childGroup, err := a.storedChildGroup(r.Context(), child.Name, parent.Name)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, childGroup, nil
+}
+
+func (a *api) deleteChildGroup(r *http.Request, args []string) (int, interface{}, error) {
+	childGroup, err := a.storedChildGroup(r.Context(), args[0], args[1])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		_, err := deleteChildGroup(r.Context(), tx, &childGroup)
+		return err
+	})
+
+	return http.StatusNoContent, nil, err
+}
+
+// host groups
+
+func (a *api) listHostGroups(r *http.Request, args []string) (int, interface{}, error) {
+	hostGroups, err := a.db.GetHostGroupsContext(r.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, append([]datastructs.HostGroup{}, hostGroups...), nil
+}
+
+func (a *api) getHostGroups(r *http.Request, args []string) (int, interface{}, error) {
+	if _, err := a.storedHost(r.Context(), a.db, args[0]); err != nil {
+		return 0, nil, err
+	}
+
+	hostGroups, err := a.db.SelectHostGroupContext(r.Context(), args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, append([]datastructs.HostGroup{}, hostGroups...), nil
+}
+
+func (a *api) createHostGroup(r *http.Request, args []string) (int, interface{}, error) {
+	var body datastructs.HostGroup
+	if err := decodeBody(r, &body); err != nil {
+		return 0, nil, err
+	}
+
+	host, err := a.storedHost(r.Context(), a.db, body.Host)
+	if err != nil {
+		return 0, nil, badRequest("%v", err)
+	}
+
+	group, err := a.storedGroup(r.Context(), a.db, body.Group)
+	if err != nil {
+		return 0, nil, badRequest("%v", err)
+	}
+
+	if host.DirectGroups.Contains(group.Name) {
+		return 0, nil, conflict("host %v is already a member of %v", host.Hostname, group.Name)
+	}
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		return createHostGroup(r.Context(), tx, &host, &group)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, datastructs.HostGroup{Host: host.Hostname, HostID: host.ID, Group: group.Name,
+		GroupID: group.ID}, nil
+}
+
+// updateHostGroups replace the groups of the host by the list of group names in the request body
+func (a *api) updateHostGroups(r *http.Request, args []string) (int, interface{}, error) {
+	host, err := a.storedHost(r.Context(), a.db, args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var groups []string
+	if err = decodeBody(r, &groups); err != nil {
+		return 0, nil, err
+	}
+
+	var hostGroups []datastructs.HostGroup
+
+	err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+		for _, name := range groups {
+			if _, err := a.storedGroup(r.Context(), tx, name); err != nil {
+				return badRequest("%v", err)
+			}
+		}
+
+		if err := setHostGroups(r.Context(), tx, &host, datastructs.NewGroupNames(groups)); err != nil {
+			return err
+		}
+
+		hostGroups, err = tx.SelectHostGroupContext(r.Context(), host.Hostname)
+
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, append([]datastructs.HostGroup{}, hostGroups...), nil
+}
+
+func (a *api) deleteHostGroup(r *http.Request, args []string) (int, interface{}, error) {
+	hostGroups, err := a.db.SelectHostGroupContext(r.Context(), args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := range hostGroups {
+		if hostGroups[i].Group != args[1] {
+			continue
+		}
+
+		err = inTxOf(r.Context(), a.db, func(tx database.Querier) error {
+			_, err := deleteHostGroup(r.Context(), tx, &hostGroups[i])
+			return err
+		})
+
+		return http.StatusNoContent, nil, err
+	}
+
+	return 0, nil, notFound("host %v is not a member of %v", args[0], args[1])
+}
@@ -0,0 +1,402 @@
+// nolint
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func Test_api(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	srv := httptest.NewServer(newAPI(testDB))
+	defer srv.Close()
+
+	// the requests run in order against the same database
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name: "list hosts", method: http.MethodGet, path: "/hosts", wantStatus: http.StatusOK,
+			wantContain: []string{`"hostname": "host1"`, `"hostname": "host2"`, `"hostname": "host3"`},
+		},
+		{
+			name: "page of hosts", method: http.MethodGet, path: "/hosts?limit=1&offset=1", wantStatus: http.StatusOK,
+			wantContain: []string{`"hostname": "host2"`}, wantMissing: []string{"host1", "host3"},
+		},
+		{
+			name: "filter hosts", method: http.MethodGet, path: "/hosts?where=ip=3.3.3.3", wantStatus: http.StatusOK,
+			wantContain: []string{`"hostname": "host3"`}, wantMissing: []string{"host1", "host2"},
+		},
+		{
+			name: "invalid filter", method: http.MethodGet, path: "/hosts?where=size=1", wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "negative limit", method: http.MethodGet, path: "/hosts?limit=-1", wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "get host", method: http.MethodGet, path: "/hosts/host1", wantStatus: http.StatusOK,
+			wantContain: []string{`"ip": "1.1.1.1"`, `"host_sub_var1": "host_sub_val1"`, `"addresses": []`},
+		},
+		{
+			name: "get missing host", method: http.MethodGet, path: "/hosts/host10", wantStatus: http.StatusNotFound,
+			wantContain: []string{`"error":"host host10 does not exists"`},
+		},
+		{
+			name: "create host", method: http.MethodPost, path: "/hosts", wantStatus: http.StatusCreated,
+			body: `{"hostname": "host10", "ip": "10.10.10.10", "variables": {"var10": "val10"},
+				"direct_groups": ["group1"]}`,
+			wantContain: []string{`"domain": "domain.local"`, `"var10": "val10"`, `"group1"`, `"revision": 2`},
+		},
+		{
+			name: "create existing host", method: http.MethodPost, path: "/hosts", wantStatus: http.StatusConflict,
+			body: `{"hostname": "host10", "ip": "10.10.10.10"}`,
+		},
+		{
+			name: "create host with invalid ip", method: http.MethodPost, path: "/hosts",
+			body: `{"hostname": "host11", "ip": "10.10.10"}`, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "create host in missing group", method: http.MethodPost, path: "/hosts",
+			body:       `{"hostname": "host11", "ip": "11.11.11.11", "direct_groups": ["group11"]}`,
+			wantStatus: http.StatusBadRequest, wantContain: []string{"group group11 does not exists"},
+		},
+		{
+			name: "create host with invalid body", method: http.MethodPost, path: "/hosts", body: `{"hostname":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update host", method: http.MethodPut, path: "/hosts/host10", body: `{"monitor": false}`,
+			wantStatus: http.StatusOK, wantContain: []string{`"monitor": false`, `"var10": "val10"`, `"revision": 3`},
+		},
+		{
+			name: "update host from stale revision", method: http.MethodPut, path: "/hosts/host10",
+			body: `{"enable": false, "revision": 1}`, wantStatus: http.StatusConflict,
+		},
+		{
+			name: "replace host variables", method: http.MethodPut, path: "/hosts/host10",
+			body: `{"variables": {"var11": "val11"}}`, wantStatus: http.StatusOK,
+			wantContain: []string{`"var11": "val11"`, `"monitor": false`, `"group1"`}, wantMissing: []string{`"var10"`},
+		},
+		{
+			name: "rename host", method: http.MethodPut, path: "/hosts/host10", body: `{"hostname": "host11"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "delete host", method: http.MethodDelete, path: "/hosts/host10", wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "get deleted host", method: http.MethodGet, path: "/hosts/host10", wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "method not allowed", method: http.MethodPatch, path: "/hosts/host1",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "unknown path", method: http.MethodGet, path: "/hosts/host1/groups", wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "list groups", method: http.MethodGet, path: "/groups", wantStatus: http.StatusOK,
+			wantContain: []string{`"name": "group1"`, `"name": "group5"`},
+		},
+		{
+			name: "create group", method: http.MethodPost, path: "/groups", wantStatus: http.StatusCreated,
+			body: `{"name": "group10", "monitor": false}`, wantContain: []string{`"enable": true`, `"monitor": false`},
+		},
+		{
+			name: "create existing group", method: http.MethodPost, path: "/groups", body: `{"name": "group10"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "create group without name", method: http.MethodPost, path: "/groups", body: `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update group", method: http.MethodPut, path: "/groups/group10",
+			body: `{"variables": {"var10": "val10"}}`, wantStatus: http.StatusOK,
+			wantContain: []string{`"var10": "val10"`, `"monitor": false`},
+		},
+		{
+			name: "remove group variables", method: http.MethodPut, path: "/groups/group10", body: `{"variables": {}}`,
+			wantStatus: http.StatusOK, wantContain: []string{`"variables": {}`}, wantMissing: []string{`"var10"`},
+		},
+		{
+			name: "delete group", method: http.MethodDelete, path: "/groups/group10", wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "delete missing group", method: http.MethodDelete, path: "/groups/group10",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "list children", method: http.MethodGet, path: "/children", wantStatus: http.StatusOK,
+			wantContain: []string{`"child": "group3"`, `"child": "group4"`},
+		},
+		{
+			name: "get child", method: http.MethodGet, path: "/children/group3/group4", wantStatus: http.StatusOK,
+			wantContain: []string{`"parent": "group4"`},
+		},
+		{
+			name: "create child", method: http.MethodPost, path: "/children", wantStatus: http.StatusCreated,
+			body: `{"child": "group1", "parent": "group2"}`, wantContain: []string{`"child_id": 1`, `"parent_id": 2`},
+		},
+		{
+			name: "create existing child", method: http.MethodPost, path: "/children", wantStatus: http.StatusConflict,
+			body: `{"child": "group1", "parent": "group2"}`,
+		},
+		{
+			name: "create child loop", method: http.MethodPost, path: "/children", wantStatus: http.StatusBadRequest,
+			body: `{"child": "group5", "parent": "group3"}`,
+		},
+		{
+			name: "delete child", method: http.MethodDelete, path: "/children/group1/group2",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "delete missing child", method: http.MethodDelete, path: "/children/group1/group2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "list host groups", method: http.MethodGet, path: "/hostgroups", wantStatus: http.StatusOK,
+			wantContain: []string{`"host": "host1"`, `"host": "host2"`, `"host": "host3"`},
+		},
+		{
+			name: "replace host groups", method: http.MethodPut, path: "/hostgroups/host1",
+			body: `["group2", "group1"]`, wantStatus: http.StatusOK,
+			wantContain: []string{`"group": "group1"`, `"group": "group2"`},
+		},
+		{
+			name: "get host groups", method: http.MethodGet, path: "/hostgroups/host1", wantStatus: http.StatusOK,
+			wantContain: []string{`"group": "group1"`, `"group": "group2"`},
+		},
+		{
+			name: "create host group", method: http.MethodPost, path: "/hostgroups", wantStatus: http.StatusCreated,
+			body: `{"host": "host2", "group": "group3"}`, wantContain: []string{`"group_id": 3`},
+		},
+		{
+			name: "create existing host group", method: http.MethodPost, path: "/hostgroups",
+			body: `{"host": "host2", "group": "group3"}`, wantStatus: http.StatusConflict,
+		},
+		{
+			name: "delete host group", method: http.MethodDelete, path: "/hostgroups/host2/group3",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "delete missing host group", method: http.MethodDelete, path: "/hostgroups/host2/group3",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("%v %v status = %v, want %v: %s", tt.method, tt.path, resp.StatusCode, tt.wantStatus, body)
+			}
+
+			for _, want := range tt.wantContain {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("%v %v = %s, want it to contain %v", tt.method, tt.path, body, want)
+				}
+			}
+
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(string(body), missing) {
+					t.Errorf("%v %v = %s, want it without %v", tt.method, tt.path, body, missing)
+				}
+			}
+		})
+	}
+}
+
+func Test_api_exports(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	srv := httptest.NewServer(newAPI(testDB))
+	defer srv.Close()
+
+	prometheus, err := prometheusSDFileBytes(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "inventory", path: "/inventory", want: inv},
+		{name: "prometheus", path: "/prometheus", want: string(prometheus)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+				t.Fatalf("GET %v = %v %v, want 200 application/json", tt.path, resp.StatusCode,
+					resp.Header.Get("Content-Type"))
+			}
+
+			if string(body) != tt.want {
+				t.Errorf("GET %v = %s, want %s", tt.path, body, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tokenAuth(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	srv := httptest.NewServer(tokenAuth(map[string]string{"ci": "secret"}, newAPI(testDB)))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		token      string
+		group      string
+		wantStatus int
+	}{
+		{name: "without token", group: "group10", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "guess", group: "group10", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", group: "group10", wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/groups", strings.NewReader(`{"name": "`+tt.group+`"}`))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("POST /groups status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	for _, token := range []string{"", "guess"} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+database.RPCPath+"SelectHostContext",
+			strings.NewReader(""))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("POST %v with token %q status = %v, want %v", req.URL.Path, token, resp.StatusCode,
+				http.StatusUnauthorized)
+		}
+	}
+
+	// the remote backend authenticate with the token and its changes are recorded as made by the token name
+	remote, err := database.Connect(&config.Config{Remote: config.RemoteConfig{URL: srv.URL, Token: "secret"}})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer remote.Close()
+
+	if _, err = remote.InsertGroup(&datastructs.Group{Name: "group11", Variables: "{}"}); err != nil {
+		t.Fatalf("InsertGroup() error = %v", err)
+	}
+
+	for _, group := range []string{"group10", "group11"} {
+		changes, err := testDB.SelectChanges(datastructs.EntityGroup, group)
+		if err != nil || len(changes) != 1 || changes[0].Actor != "ci" {
+			t.Errorf("SelectChanges(%v) = %+v, %v, want one change by ci", group, changes, err)
+		}
+	}
+
+	if _, err = database.Connect(&config.Config{Remote: config.RemoteConfig{URL: srv.URL}}); err == nil {
+		t.Errorf("Connect() without token error = nil, want unauthorized")
+	}
+}
+
+func Test_tokenAuth_withoutTokens(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	srv := httptest.NewServer(tokenAuth(nil, newAPI(testDB)))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "api", method: http.MethodGet, path: "/hosts/host1", wantStatus: http.StatusOK},
+		{name: "rpc", method: http.MethodPost, path: database.RPCPath + "Ping", wantStatus: http.StatusUnauthorized},
+		{name: "rpc write", method: http.MethodPost, path: database.RPCPath + "DeleteHostContext",
+			wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("%v %v status = %v, want %v", tt.method, tt.path, resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	// the remote backend is refused
+	remote, err := database.Connect(&config.Config{Remote: config.RemoteConfig{URL: srv.URL}})
+	if err == nil {
+		defer remote.Close()
+
+		if _, err = remote.SelectHost("host1"); err == nil {
+			t.Errorf("SelectHost() error = nil, want error for server without tokens")
+		}
+	}
+}
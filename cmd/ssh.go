@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"strings"
@@ -26,14 +26,15 @@ var sshVar = &cobra.Command{
 	Args:               cobra.MinimumNArgs(1),
 	DisableFlagParsing: true,
 	ValidArgsFunction:  hostsArgsFunc,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := sshFunc(args); err != nil {
-			log.Fatal(err)
-		}
+	// the errors are printed by Execute, the ssh exit code is not an error to print
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sshFunc(cmd.Context(), args)
 	},
 }
 
-func sshFunc(args []string) (err error) {
+func sshFunc(ctx context.Context, args []string) (err error) {
 	var user string
 
 	var host datastructs.Host
@@ -42,13 +43,13 @@ func sshFunc(args []string) (err error) {
 		login := strings.Split(args[0], "@")
 		user = login[0]
 
-		host, err = viewHostByHostname(login[1])
+		host, err = viewHostByHostname(ctx, login[1])
 		if err != nil {
 			return err
 		}
 	} else {
 		user = Conf.SSH.User
-		host, err = viewHostByHostname(args[0])
+		host, err = viewHostByHostname(ctx, args[0])
 		if err != nil {
 			return err
 		}
@@ -65,7 +66,8 @@ func sshFunc(args []string) (err error) {
 
 	// set proxy
 	if Conf.SSH.Proxy && Conf.SSHProxy.Host != fmt.Sprintf("%v.%v", host.Hostname, host.Domain) {
-		sshArgs = append(sshArgs, "-J", fmt.Sprintf("%v@%v:%v", Conf.SSHProxy.User, Conf.SSHProxy.Host, Conf.SSHProxy.Port))
+		sshArgs = append(sshArgs, "-J", fmt.Sprintf("%v@%v:%v", Conf.SSHProxy.User,
+			datastructs.FormatIP(Conf.SSHProxy.Host), Conf.SSHProxy.Port))
 	}
 
 	// StrictHostKeyChecking, (won't apply on the proxy-jump host)
@@ -91,16 +93,19 @@ func sshFunc(args []string) (err error) {
 
 	err = cmd.Run()
 	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok { // nolint: errorlint
-			fmt.Sprintln(exitError.ExitCode())
+		// exit with the remote command exit code
+		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() > 0 { // nolint: errorlint
+			return &exitCodeError{code: exitError.ExitCode()}
 		}
+
+		return err
 	}
 
 	return nil
 }
 
-func viewHostByHostname(hostname string) (host datastructs.Host, err error) {
-	host, err = DB.SelectHost(hostname)
+func viewHostByHostname(ctx context.Context, hostname string) (host datastructs.Host, err error) {
+	host, err = DB.SelectHostContext(ctx, hostname)
 	if err != nil {
 		return host, err
 	} else if host.Hostname == "" {
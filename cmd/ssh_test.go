@@ -0,0 +1,63 @@
+// nolint
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_sshFunc(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	// a fake ssh exiting with the code given as the remote command
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte("#!/bin/sh\neval exit \\${$#}\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"host1", "0"},
+			wantCode: 0,
+			wantErr:  false,
+		},
+		{
+			name:     "remote exit code",
+			args:     []string{"host1", "3"},
+			wantCode: 3,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown host",
+			args:     []string{"no-such-host", "0"},
+			wantCode: 0,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sshFunc(context.Background(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sshFunc() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var exitErr *exitCodeError
+			if errors.As(err, &exitErr) != (tt.wantCode != 0) || (exitErr != nil && exitErr.code != tt.wantCode) {
+				t.Errorf("sshFunc() error = %v, want exit code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
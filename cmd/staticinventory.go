@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+	"gopkg.in/yaml.v2"
+)
+
+// inventoryFormats are the output formats of the inventory command
+var inventoryFormats = []string{"json", "ini", "yaml"}
+
+// writeInventoryFormat write the entire inventory to w in the named format
+func writeInventoryFormat(ctx context.Context, db database.Querier, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return writeInventory(ctx, db, w)
+	case "ini":
+		return writeINIInventory(ctx, db, w)
+	case "yaml", "yml":
+		return writeYAMLInventory(ctx, db, w)
+	}
+
+	return fmt.Errorf("unknown inventory format %v, expecting one of %v", format, strings.Join(inventoryFormats, ", "))
+}
+
+// writeINIInventory write the entire inventory as ansible INI inventory. The hosts are written first with their
+// variables, the groups follow with their hosts, children and variables sections. Ansible refuses children that
+// are not declared, the disabled child groups are declared empty at the end as they are in the json inventory
+func writeINIInventory(ctx context.Context, db database.Querier, w io.Writer) error {
+	// the first write error is kept by bw and returned by Flush
+	bw := bufio.NewWriter(w)
+
+	declared := make(map[string]bool)
+	referenced := make(map[string]bool)
+
+	err := walkInventory(ctx, db, func(name string, vars datastructs.InventoryVars) error {
+		fmt.Fprint(bw, name)
+
+		for _, key := range sortedKeys(vars) {
+			value, err := iniValue(vars[key])
+			if err != nil {
+				return fmt.Errorf("host %v variable %v: %w", name, key, err)
+			}
+
+			fmt.Fprint(bw, " "+key+"="+shellQuote(value))
+		}
+
+		fmt.Fprint(bw, "\n")
+
+		return nil
+	}, func(name string, data datastructs.InventoryGroupsData) error {
+		declared[name] = true
+
+		// a group is declared by its hosts or children section, ansible refuse a variables section alone
+		if len(data.Hosts) > 0 || len(data.Children) == 0 {
+			fmt.Fprintf(bw, "\n[%v]\n", name)
+
+			for _, host := range data.Hosts {
+				fmt.Fprint(bw, host+"\n")
+			}
+		}
+
+		if len(data.Children) > 0 {
+			fmt.Fprintf(bw, "\n[%v:children]\n", name)
+
+			for _, child := range data.Children {
+				referenced[child] = true
+
+				fmt.Fprint(bw, child+"\n")
+			}
+		}
+
+		if len(data.Vars) > 0 {
+			fmt.Fprintf(bw, "\n[%v:vars]\n", name)
+
+			for _, key := range sortedKeys(data.Vars) {
+				value, err := iniValue(data.Vars[key])
+				if err != nil {
+					return fmt.Errorf("group %v variable %v: %w", name, key, err)
+				}
+
+				fmt.Fprint(bw, key+"="+value+"\n")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	undeclared := make([]string, 0, len(referenced))
+
+	for child := range referenced {
+		if !declared[child] {
+			undeclared = append(undeclared, child)
+		}
+	}
+
+	sort.Strings(undeclared)
+
+	for _, child := range undeclared {
+		fmt.Fprintf(bw, "\n[%v]\n", child)
+	}
+
+	return bw.Flush()
+}
+
+// bareINIValue match the strings ansible read back as the same string when written without quotes, the values
+// are evaluated as python literals
+var bareINIValue = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.:/@-]*|[0-9]+(\.[0-9]+){2,})$`)
+
+// pythonConstant match the strings starting with the python constants, e.g. True-1j is a complex number
+var pythonConstant = regexp.MustCompile(`^(True|False|None)([^A-Za-z0-9_]|$)`)
+
+// iniValue return the json variable value as python literal, as ansible evaluate the INI inventory values.
+// Plain strings are written without quotes
+func iniValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok && bareINIValue.MatchString(s) && !pythonConstant.MatchString(s) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	if err := writePythonLiteral(&b, v); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// writePythonLiteral write the json value v as python literal, json differ from it only by its constants
+func writePythonLiteral(b *strings.Builder, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("None")
+	case bool:
+		if v {
+			b.WriteString("True")
+		} else {
+			b.WriteString("False")
+		}
+	case string, float64, json.Number:
+		var buf bytes.Buffer
+
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+
+		b.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	case []interface{}:
+		b.WriteString("[")
+
+		for i, e := range v {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			if err := writePythonLiteral(b, e); err != nil {
+				return err
+			}
+		}
+
+		b.WriteString("]")
+	case map[string]interface{}:
+		b.WriteString("{")
+
+		for i, key := range sortedKeys(v) {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			if err := writePythonLiteral(b, key); err != nil {
+				return err
+			}
+
+			b.WriteString(": ")
+
+			if err := writePythonLiteral(b, v[key]); err != nil {
+				return err
+			}
+		}
+
+		b.WriteString("}")
+	default:
+		// other go values, e.g. the addresses variable, are written as their json value
+		j, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		var value interface{}
+		if err = json.Unmarshal(j, &value); err != nil {
+			return err
+		}
+
+		return writePythonLiteral(b, value)
+	}
+
+	return nil
+}
+
+// shellQuote quote s as a single shell word when it holds characters splitting or ending the INI host line
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\#") {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// sortedKeys return the keys of the variables in order
+func sortedKeys(vars map[string]interface{}) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// yamlGroup is a group of the YAML inventory, its hosts and children are maps of empty values
+type yamlGroup struct {
+	Hosts    map[string]struct{}       `yaml:"hosts,omitempty"`
+	Children map[string]struct{}       `yaml:"children,omitempty"`
+	Vars     datastructs.InventoryVars `yaml:"vars,omitempty"`
+}
+
+// writeYAMLInventory write the entire inventory as ansible YAML inventory. The hosts are written under all.hosts
+// with their variables, the groups under all.children with the names of their hosts and children
+func writeYAMLInventory(ctx context.Context, db database.Querier, w io.Writer) error {
+	// the first write error is kept by bw and returned by Flush
+	bw := bufio.NewWriter(w)
+
+	// the all group and its sections are written before their first member
+	var section string
+
+	member := func(name string, key string, v interface{}) error {
+		b, err := yaml.Marshal(map[string]interface{}{key: v})
+		if err != nil {
+			return err
+		}
+
+		if section == "" {
+			fmt.Fprint(bw, "all:\n")
+		}
+
+		if section != name {
+			fmt.Fprint(bw, "  "+name+":\n")
+			section = name
+		}
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n") {
+			fmt.Fprint(bw, "    "+line)
+		}
+
+		fmt.Fprint(bw, "\n")
+
+		return nil
+	}
+
+	err := walkInventory(ctx, db, func(name string, vars datastructs.InventoryVars) error {
+		if vars == nil {
+			vars = datastructs.InventoryVars{}
+		}
+
+		return member("hosts", name, vars)
+	}, func(name string, data datastructs.InventoryGroupsData) error {
+		group := yamlGroup{Hosts: map[string]struct{}{}, Children: map[string]struct{}{}, Vars: data.Vars}
+
+		for _, host := range data.Hosts {
+			group.Hosts[host] = struct{}{}
+		}
+
+		for _, child := range data.Children {
+			group.Children[child] = struct{}{}
+		}
+
+		return member("children", name, group)
+	})
+	if err != nil {
+		return err
+	}
+
+	if section == "" {
+		fmt.Fprint(bw, "all: {}\n")
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,208 @@
+// nolint
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var iniInv = `host1.domain.local ansible_host=1.1.1.1 host_var1='{"host_sub_var1": "host_sub_val1"}'
+host2.domain.local ansible_host=2.2.2.2 host_var2=host_val2
+host3.domain.local ansible_host=3.3.3.3 host_var3=host_val3
+
+[group1]
+host1.domain.local
+
+[group1:vars]
+group_var1={"group_sub_var1": "group_sub_val1"}
+
+[group2]
+host2.domain.local
+
+[group2:vars]
+group_var2=group_val2
+
+[group3]
+host3.domain.local
+
+[group3:vars]
+group_var3=group_val3
+
+[group4:children]
+group3
+
+[group4:vars]
+group_var4=group_val4
+
+[group5:children]
+group4
+
+[group5:vars]
+group_var5=group_val5
+`
+
+var yamlInv = `all:
+  hosts:
+    host1.domain.local:
+      ansible_host: 1.1.1.1
+      host_var1:
+        host_sub_var1: host_sub_val1
+    host2.domain.local:
+      ansible_host: 2.2.2.2
+      host_var2: host_val2
+    host3.domain.local:
+      ansible_host: 3.3.3.3
+      host_var3: host_val3
+  children:
+    group1:
+      hosts:
+        host1.domain.local: {}
+      vars:
+        group_var1:
+          group_sub_var1: group_sub_val1
+    group2:
+      hosts:
+        host2.domain.local: {}
+      vars:
+        group_var2: group_val2
+    group3:
+      hosts:
+        host3.domain.local: {}
+      vars:
+        group_var3: group_val3
+    group4:
+      children:
+        group3: {}
+      vars:
+        group_var4: group_val4
+    group5:
+      children:
+        group4: {}
+      vars:
+        group_var5: group_val5
+`
+
+func Test_writeInventoryFormat(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{name: "json", format: "json", want: inv},
+		{name: "ini", format: "ini", want: iniInv},
+		{name: "yaml", format: "yaml", want: yamlInv},
+		{name: "unknown format", format: "toml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := writeInventoryFormat(context.Background(), DB, &b, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeInventoryFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if b.String() != tt.want {
+				t.Errorf("writeInventoryFormat() = %s, want %s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeINIInventory_declaredChildren(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	for _, group := range []datastructs.Group{
+		{Name: "group10", Variables: `{"var10": 10}`, Enabled: true},
+		{Name: "group11", Variables: "{}", Enabled: false},
+	} {
+		group := group
+		if _, err := DB.InsertGroup(&group); err != nil {
+			t.Fatal(err)
+		}
+
+		parent, _ := DB.SelectGroup("group1")
+		child, _ := DB.SelectGroup(group.Name)
+
+		if _, err := DB.InsertChildGroup(&datastructs.ChildGroup{Parent: parent.Name, ParentID: parent.ID,
+			Child: child.Name, ChildID: child.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := writeINIInventory(context.Background(), DB, &b); err != nil {
+		t.Fatalf("writeINIInventory() error = %v", err)
+	}
+
+	// ansible refuse children without hosts or children section, the disabled group is declared empty
+	for _, want := range []string{"[group1:children]\ngroup10\ngroup11\n", "[group10]\n\n[group10:vars]\nvar10=10\n",
+		"\n[group11]\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("writeINIInventory() = %s, want it to contain %q", b.String(), want)
+		}
+	}
+}
+
+func Test_iniValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "plain string", value: "web01.example.com", want: "web01.example.com"},
+		{name: "ip", value: "10.0.0.1", want: "10.0.0.1"},
+		{name: "numeric string", value: "123", want: `"123"`},
+		{name: "python constant string", value: "True", want: `"True"`},
+		{name: "string with spaces", value: "a b", want: `"a b"`},
+		{name: "empty string", value: "", want: `""`},
+		{name: "number", value: 1.5, want: "1.5"},
+		{name: "bool", value: false, want: "False"},
+		{name: "null", value: nil, want: "None"},
+		{name: "list", value: []interface{}{1.0, "x", true}, want: `[1, "x", True]`},
+		{name: "map", value: map[string]interface{}{"b": nil, "a": "<x>"}, want: `{"a": "<x>", "b": None}`},
+		{name: "go map", value: map[string]string{"management": "10.0.0.1"}, want: `{"management": "10.0.0.1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := iniValue(tt.value)
+			if err != nil {
+				t.Fatalf("iniValue() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("iniValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shellQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "host_val", want: "host_val"},
+		{value: `"a b"`, want: `'"a b"'`},
+		{value: `"it's"`, want: `'"it'"'"'s"'`},
+		{value: `"#x"`, want: `'"#x"'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := shellQuote(tt.value); got != tt.want {
+				t.Errorf("shellQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
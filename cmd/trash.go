@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var olderThan string
+
+func init() {
+	rootCmd.AddCommand(trash)
+
+	trash.AddCommand(trashListVar)
+	trash.AddCommand(trashPurgeVar)
+
+	trashPurgeVar.Flags().StringVar(&olderThan, "older-than", "30d",
+		"purge only records deleted longer ago than the duration (e.g. 30d, 12h)")
+}
+
+var trash = &cobra.Command{
+	Use:       "trash",
+	ValidArgs: []string{"list", "purge"},
+	Short:     "manage deleted hosts and groups",
+	Long: "deleted hosts and groups are moved to the trash and hidden from view, inventory and prometheus output." +
+		" Use `admiral restore` to bring them back with their relationships",
+}
+
+var trashListVar = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list the hosts and groups in the trash",
+	Example: "admiral trash list",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		hosts, groups, err := listTrash(cmd.Context())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printTrash(hosts, groups)
+	},
+}
+
+var trashPurgeVar = &cobra.Command{
+	Use:     "purge",
+	Short:   "permanently delete hosts and groups from the trash",
+	Long:    "permanently delete the hosts and groups deleted longer ago than --older-than, purged records cannot be restored",
+	Example: "admiral trash purge --older-than 30d\nadmiral trash purge --older-than 0d",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := purgeTrashCase(cmd.Context(), time.Now(), olderThan); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func listTrash(ctx context.Context) (hosts []datastructs.Host, groups []datastructs.Group, err error) {
+	hosts, err = DB.GetDeletedHostsContext(ctx)
+	if err != nil {
+		return hosts, groups, err
+	}
+
+	groups, err = DB.GetDeletedGroupsContext(ctx)
+
+	return hosts, groups, err
+}
+
+func purgeTrashCase(ctx context.Context, now time.Time, olderThan string) error {
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return err
+	}
+
+	hosts, groups, err := listTrash(ctx)
+	if err != nil {
+		return err
+	}
+
+	hosts, groups, err = deletedBefore(now.Add(-age), hosts, groups)
+	if err != nil {
+		return err
+	}
+
+	if len(hosts) == 0 && len(groups) == 0 {
+		return fmt.Errorf("no record matched request")
+	}
+
+	printTrash(hosts, groups)
+
+	if !User.confirm() {
+		return fmt.Errorf("aborted")
+	}
+
+	var affected int64
+
+	err = inTx(ctx, func(tx database.Querier) error {
+		for i := range hosts {
+			a, err := tx.PurgeHostContext(ctx, &hosts[i])
+			if err != nil {
+				return err
+			}
+
+			affected += a
+		}
+
+		for i := range groups {
+			a, err := tx.PurgeGroupContext(ctx, &groups[i])
+			if err != nil {
+				return err
+			}
+
+			affected += a
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("lines purged %v\n", affected)
+
+	return nil
+}
+
+// deletedBefore return the hosts and groups moved to the trash at or before t
+func deletedBefore(t time.Time, hosts []datastructs.Host, groups []datastructs.Group) (
+	matchedHosts []datastructs.Host, matchedGroups []datastructs.Group, err error) {
+	for i := range hosts {
+		deleted, err := time.Parse(time.RFC3339, hosts[i].Deleted)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !deleted.After(t) {
+			matchedHosts = append(matchedHosts, hosts[i])
+		}
+	}
+
+	for i := range groups {
+		deleted, err := time.Parse(time.RFC3339, groups[i].Deleted)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !deleted.After(t) {
+			matchedGroups = append(matchedGroups, groups[i])
+		}
+	}
+
+	return matchedHosts, matchedGroups, nil
+}
@@ -0,0 +1,152 @@
+// nolint
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_purgeTrashCase(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	if err := deleteHostCase(ctx, []string{"host1"}); err != nil {
+		t.Fatalf("deleteHostCase() error = %v", err)
+	}
+
+	if err := deleteGroupCase(ctx, []string{"group1"}); err != nil {
+		t.Fatalf("deleteGroupCase() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		olderThan string
+		wantLeft  int
+		wantErr   bool
+	}{
+		{
+			name:      "nothing old enough",
+			now:       time.Now(),
+			olderThan: "30d",
+			wantLeft:  2,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid duration",
+			now:       time.Now(),
+			olderThan: "month",
+			wantLeft:  2,
+			wantErr:   true,
+		},
+		{
+			name:      "purge all",
+			now:       time.Now().Add(31 * 24 * time.Hour),
+			olderThan: "30d",
+			wantLeft:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := purgeTrashCase(ctx, tt.now, tt.olderThan); (err != nil) != tt.wantErr {
+				t.Errorf("purgeTrashCase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			hosts, groups, err := listTrash(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(hosts)+len(groups) != tt.wantLeft {
+				t.Errorf("purgeTrashCase() left %v, %v in the trash, want %v records", hosts, groups, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func Test_restoreHostCase(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	if err := deleteHostCase(ctx, []string{"host1"}); err != nil {
+		t.Fatalf("deleteHostCase() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "restore host1",
+			args: []string{"host1"},
+		},
+		{
+			name:    "host not in the trash",
+			args:    []string{"host1"},
+			wantErr: true,
+		},
+		{
+			name:    "partial hostname",
+			args:    []string{"host"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := restoreHostCase(ctx, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("restoreHostCase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if hostGroups, _ := DB.SelectHostGroup("host1"); len(hostGroups) != 1 || hostGroups[0].Group != "group1" {
+		t.Errorf("restoreHostCase() memberships = %v, want group1", hostGroups)
+	}
+}
+
+func Test_restoreGroupCase(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	if err := deleteGroupCase(ctx, []string{"group4"}); err != nil {
+		t.Fatalf("deleteGroupCase() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "restore group4",
+			args: []string{"group4"},
+		},
+		{
+			name:    "group not in the trash",
+			args:    []string{"group4"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := restoreGroupCase(ctx, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("restoreGroupCase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if childGroups, _ := DB.SelectChildGroup("group3", "group4"); len(childGroups) != 1 {
+		t.Errorf("restoreGroupCase() child groups = %v, want group3 child of group4", childGroups)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/tatsushid/go-prettytable"
 	"github.com/via-justa/admiral/datastructs"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const separator = " |"
@@ -24,6 +25,8 @@ func printHosts(hosts []datastructs.Host) {
 		{Header: "Monitored", MinWidth: 12},
 		{Header: "Direct Group", MinWidth: 12},
 		{Header: "Inherited Groups", MinWidth: 12},
+		{Header: "Created", MinWidth: 12},
+		{Header: "Updated", MinWidth: 12},
 	}...)
 	if err != nil {
 		log.Fatal(err)
@@ -33,7 +36,8 @@ func printHosts(hosts []datastructs.Host) {
 
 	for i := range hosts {
 		err = tbl.AddRow(hosts[i].Host, hosts[i].Hostname, hosts[i].Domain, hosts[i].Enabled,
-			hosts[i].Monitored, hosts[i].DirectGroup, hosts[i].InheritedGroups)
+			hosts[i].Monitored, hosts[i].DirectGroups.String(), hosts[i].InheritedGroups,
+			hosts[i].Created, hosts[i].Updated)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -50,6 +54,8 @@ func printGroups(groups []datastructs.Group) {
 		{Header: "Monitored", MinWidth: 12},
 		{Header: "Children count", MinWidth: 12},
 		{Header: "Hosts count", MinWidth: 12},
+		{Header: "Created", MinWidth: 12},
+		{Header: "Updated", MinWidth: 12},
 	}...)
 	if err != nil {
 		log.Fatal(err)
@@ -58,7 +64,8 @@ func printGroups(groups []datastructs.Group) {
 	tbl.Separator = separator
 
 	for _, group := range groups {
-		err = tbl.AddRow(group.Name, group.Enabled, group.Monitored, group.NumChildren, group.NumHosts)
+		err = tbl.AddRow(group.Name, group.Enabled, group.Monitored, group.NumChildren, group.NumHosts,
+			group.Created, group.Updated)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -117,6 +124,129 @@ func printHostGroups(hostGroups []datastructs.HostGroup) {
 	tbl.Print()
 }
 
+func printMigrations(migrations []datastructs.Migration) {
+	tbl, err := prettytable.NewTable([]prettytable.Column{
+		{Header: "Version", MinWidth: 12},
+		{Header: "Description", MinWidth: 12},
+		{Header: "Applied At", MinWidth: 12},
+	}...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tbl.Separator = separator
+
+	for _, m := range migrations {
+		appliedAt := m.AppliedAt
+		if appliedAt == "" {
+			appliedAt = "pending"
+		}
+
+		err = tbl.AddRow(m.Version, m.Description, appliedAt)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// nolint: errcheck,gosec
+	tbl.Print()
+}
+
+// printInventoryCounts print the number of records of each kind, a column per counts
+func printInventoryCounts(headers []string, counts ...inventoryCounts) {
+	columns := []prettytable.Column{{Header: "Records", MinWidth: 12}}
+	for _, header := range headers {
+		columns = append(columns, prettytable.Column{Header: header, MinWidth: 12})
+	}
+
+	tbl, err := prettytable.NewTable(columns...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tbl.Separator = separator
+
+	rows := []struct {
+		name  string
+		count func(c inventoryCounts) int
+	}{
+		{"groups", func(c inventoryCounts) int { return c.Groups }},
+		{"child groups", func(c inventoryCounts) int { return c.ChildGroups }},
+		{"hosts", func(c inventoryCounts) int { return c.Hosts }},
+		{"host groups", func(c inventoryCounts) int { return c.HostGroups }},
+		{"addresses", func(c inventoryCounts) int { return c.Addresses }},
+	}
+
+	for _, row := range rows {
+		values := []interface{}{row.name}
+		for _, c := range counts {
+			values = append(values, row.count(c))
+		}
+
+		if err = tbl.AddRow(values...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// nolint: errcheck,gosec
+	tbl.Print()
+}
+
+func printChanges(changes []datastructs.Change) {
+	tbl, err := prettytable.NewTable([]prettytable.Column{
+		{Header: "ID", MinWidth: 6},
+		{Header: "Changed At", MinWidth: 12},
+		{Header: "Actor", MinWidth: 12},
+		{Header: "Action", MinWidth: 12},
+		{Header: "Entity", MinWidth: 12},
+		{Header: "Name", MinWidth: 12},
+		{Header: "Related", MinWidth: 12},
+	}...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tbl.Separator = separator
+
+	for _, c := range changes {
+		err = tbl.AddRow(c.ID, c.ChangedAt, c.Actor, c.Action, c.Entity, c.Name, c.Related)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// nolint: errcheck,gosec
+	tbl.Print()
+}
+
+func printTrash(hosts []datastructs.Host, groups []datastructs.Group) {
+	tbl, err := prettytable.NewTable([]prettytable.Column{
+		{Header: "Entity", MinWidth: 12},
+		{Header: "Name", MinWidth: 12},
+		{Header: "Deleted", MinWidth: 12},
+	}...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tbl.Separator = separator
+
+	for _, h := range hosts {
+		if err = tbl.AddRow(datastructs.EntityHost, h.Hostname, h.Deleted); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, g := range groups {
+		if err = tbl.AddRow(datastructs.EntityGroup, g.Name, g.Deleted); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// nolint: errcheck,gosec
+	tbl.Print()
+}
+
 const defaultEditor = "vim"
 
 func getPreferredEditorFromEnvironment() string {
@@ -227,3 +357,38 @@ func newUser() userInt {
 	u := user{}
 	return u
 }
+
+// sshPrompter ask on the terminal for the ssh proxy key passphrase and to trust the proxy host key. The questions
+// are written to stderr so they are not mixed with the command output
+type sshPrompter struct{}
+
+func (sshPrompter) TrustHostKey(host, keyType, fingerprint string) bool {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "The authenticity of the ssh proxy %v can't be established.\n"+
+		"Its %v key fingerprint is %v.\n"+
+		"Trust the key and add it to the known hosts [y/n]: ", host, keyType, fingerprint)
+
+	res, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	res = strings.ToLower(strings.TrimSpace(res))
+
+	return res == "y" || res == "yes"
+}
+
+func (sshPrompter) Passphrase(keyPath string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, fmt.Errorf("cannot ask for the passphrase, stdin is not a terminal")
+	}
+
+	fmt.Fprintf(os.Stderr, "Enter passphrase for key %v: ", keyPath)
+	defer fmt.Fprintln(os.Stderr)
+
+	return terminal.ReadPassword(fd)
+}
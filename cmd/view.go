@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
@@ -13,6 +18,13 @@ var viewAsJSON bool
 var sortH string
 var sortG string
 var sortC string
+var changedSince string
+var createdBefore string
+var whereFilter string
+var varFilters []string
+var hasVars []string
+var viewLimit int
+var viewOffset int
 
 func init() {
 	rootCmd.AddCommand(view)
@@ -21,10 +33,34 @@ func init() {
 	viewHostVar.Flags().BoolVarP(&viewAsJSON, "json", "j", false, "view in json format (present vars)")
 	viewHostVar.Flags().StringVarP(&sortH, "sort-by", "s", "hostname", "sort output by value of requested column."+
 		" Allowed values are hostname, ip, domain")
+	viewHostVar.Flags().StringVar(&changedSince, "changed-since", "", "view only hosts created or modified within"+
+		" the given duration, e.g. 90m, 24h or 7d")
+	viewHostVar.Flags().StringVar(&createdBefore, "created-before", "", "view only hosts created before the given"+
+		" date, e.g. 2020-12-01 or 2020-12-01T10:00:00Z")
+	viewHostVar.Flags().StringVarP(&whereFilter, "where", "w", "", "view only hosts matching the filter, e.g."+
+		" 'domain=prod.local and monitored=false'. Fields are "+strings.Join(datastructs.HostFilterFields, ", "))
+	viewHostVar.Flags().StringArrayVar(&varFilters, "var", nil, "view only hosts with the variable at the dot"+
+		" separated path matching, e.g. 'os.family=debian'. Can be repeated")
+	viewHostVar.Flags().StringArrayVar(&hasVars, "has-var", nil, "view only hosts with the variable at the dot"+
+		" separated path set, e.g. 'os.family'. Can be repeated")
+	viewHostVar.Flags().IntVar(&viewLimit, "limit", 0, "view at most the given number of hosts, after sorting")
+	viewHostVar.Flags().IntVar(&viewOffset, "offset", 0, "skip the given number of sorted hosts")
 	view.AddCommand(viewGroupVar)
 	viewGroupVar.Flags().BoolVarP(&viewAsJSON, "json", "j", false, "view in json format (present vars)")
 	viewGroupVar.Flags().StringVarP(&sortG, "sort-by", "s", "name", "sort output by value of requested column."+
 		" Allowed values are name, children-count, hosts-count")
+	viewGroupVar.Flags().StringVar(&changedSince, "changed-since", "", "view only groups created or modified within"+
+		" the given duration, e.g. 90m, 24h or 7d")
+	viewGroupVar.Flags().StringVar(&createdBefore, "created-before", "", "view only groups created before the given"+
+		" date, e.g. 2020-12-01 or 2020-12-01T10:00:00Z")
+	viewGroupVar.Flags().StringVarP(&whereFilter, "where", "w", "", "view only groups matching the filter, e.g."+
+		" 'name^=web and enabled=true'. Fields are "+strings.Join(datastructs.GroupFilterFields, ", "))
+	viewGroupVar.Flags().StringArrayVar(&varFilters, "var", nil, "view only groups with the variable at the dot"+
+		" separated path matching, e.g. 'env=prod'. Can be repeated")
+	viewGroupVar.Flags().StringArrayVar(&hasVars, "has-var", nil, "view only groups with the variable at the dot"+
+		" separated path set, e.g. 'env'. Can be repeated")
+	viewGroupVar.Flags().IntVar(&viewLimit, "limit", 0, "view at most the given number of groups, after sorting")
+	viewGroupVar.Flags().IntVar(&viewOffset, "offset", 0, "skip the given number of sorted groups")
 	view.AddCommand(viewChildVar)
 	viewChildVar.Flags().StringVarP(&sortC, "sort-by", "s", "parent", "sort output by value of requested column."+
 		" Allowed values are parent, child")
@@ -43,40 +79,86 @@ var viewHostVar = &cobra.Command{
 	Use:   "host [hostname | 'host fqdn']",
 	Short: "view existing host",
 	Long: "view existing host by substring of hostname or IP or view all records when no argument passed." +
-		"pass the flag `-j,--json` to view the host in json structure with host variables",
-	Example:           "admiral view host\nadmiral view host host1\nadmiral view host host1 -j",
+		"pass the flag `-j,--json` to view the host in json structure with host variables." +
+		" `-w,--where` filter the hosts by field matches combined with `and` and `or`: `field=value` match exactly," +
+		" `field^=value` by prefix, `field*=pattern` by glob pattern and `field~=regex` by regular expression." +
+		" Prefix, glob and regular expression matches are case insensitive. Variables are matched with the field" +
+		" `var.path.to.key` in `--where` or with `--var 'path.to.key=value'`, `--has-var path.to.key` match" +
+		" the hosts with the variable set. Only the hosts own variables are matched, not the inherited ones",
+	Example: "admiral view host\nadmiral view host host1\nadmiral view host host1 -j\n" +
+		"admiral view host --where 'domain=prod.local and monitored=false'\n" +
+		"admiral view host --where 'group=web or (inherited_group^=dc1 and hostname~=^db[0-9]+$)'\n" +
+		"admiral view host --var 'os.family=debian' --has-var backup.schedule\n" +
+		"admiral view host --limit 50 --offset 100",
 	ValidArgsFunction: hostsArgsFunc,
 	Args:              cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		viewHost(args)
+		viewHost(cmd.Context(), args)
 	},
 }
 
-func viewHost(args []string) {
+func viewHost(ctx context.Context, args []string) {
 	var hosts datastructs.Hosts
 
 	var err error
 
-	switch len(args) {
-	case 0:
-		hosts, err = listHosts()
+	filter, err := viewFilter(whereFilter, varFilters, hasVars)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	page := datastructs.Page{Limit: viewLimit, Offset: viewOffset}
+	if err = page.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// all hosts in hostname order are paged while read from the database, other listings once filtered and sorted
+	dbPaged := filter.IsZero() && len(args) == 0 && changedSince == "" && createdBefore == "" && sortH == "hostname"
+
+	switch {
+	case !filter.IsZero():
+		hosts, err = whereHosts(ctx, DB, filter, args)
 		if err != nil {
 			log.Fatal(err)
 		}
-	case 1:
-		hosts, err = scanHosts(args[0])
+	case dbPaged:
+		hosts, err = listHosts(ctx, page)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case len(args) == 0:
+		hosts, err = listHosts(ctx, datastructs.Page{})
+		if err != nil {
+			log.Fatal(err)
+		}
+	case len(args) == 1:
+		hosts, err = scanHosts(ctx, DB, args[0])
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 
+	hosts, err = filterHosts(hosts, changedSince, createdBefore)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = hosts.Sort(sortH)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if !dbPaged {
+		start, end := page.Bounds(len(hosts))
+		hosts = hosts[start:end]
+	}
+
 	if viewAsJSON {
 		if len(hosts) > 0 {
+			if err = loadAddresses(ctx, DB, hosts); err != nil {
+				log.Fatal(err)
+			}
+
 			for i := range hosts {
 				_ = hosts[i].UnmarshalVars()
 			}
@@ -92,8 +174,18 @@ func viewHost(args []string) {
 	}
 }
 
-func listHosts() (hosts []datastructs.Host, err error) {
-	hosts, err = DB.GetHosts()
+// listHosts return the page of all hosts ordered by hostname
+func listHosts(ctx context.Context, page datastructs.Page) (hosts []datastructs.Host, err error) {
+	err = DB.IterateHostsContext(ctx, page, func(host *datastructs.Host) error {
+		hosts = append(hosts, *host)
+		return nil
+	})
+
+	return hosts, err
+}
+
+func scanHosts(ctx context.Context, db database.Querier, val string) (hosts []datastructs.Host, err error) {
+	hosts, err = db.ScanHostsContext(ctx, val)
 	if err != nil {
 		return hosts, err
 	}
@@ -101,13 +193,73 @@ func listHosts() (hosts []datastructs.Host, err error) {
 	return hosts, nil
 }
 
-func scanHosts(val string) (hosts []datastructs.Host, err error) {
-	hosts, err = DB.ScanHosts(val)
+// viewFilter return the filter matching the where filter and every var match and has var path
+func viewFilter(where string, vars, hasVars []string) (filter datastructs.Filter, err error) {
+	if filter, err = datastructs.ParseFilter(where); err != nil {
+		return filter, err
+	}
+
+	filters := []datastructs.Filter{}
+	if !filter.IsZero() {
+		filters = append(filters, filter)
+	}
+
+	for _, v := range vars {
+		f, err := datastructs.ParseFilter(datastructs.VarPrefix + v)
+		if err != nil || f.Field == "" {
+			return filter, fmt.Errorf("invalid --var %q, expected path.to.key followed by =, ^=, *= or ~= and"+
+				" the value", v)
+		}
+
+		filters = append(filters, f)
+	}
+
+	for _, path := range hasVars {
+		filters = append(filters, datastructs.Filter{Field: datastructs.HasVarField, Match: datastructs.MatchExact,
+			Value: path})
+	}
+
+	switch len(filters) {
+	case 0:
+		return datastructs.Filter{}, nil
+	case 1:
+		return filters[0], nil
+	}
+
+	return datastructs.Filter{And: filters}, nil
+}
+
+// whereHosts return the hosts matching the filter and, when given, the substring of hostname or IP in args
+func whereHosts(ctx context.Context, db database.Querier, filter datastructs.Filter, args []string) (
+	hosts []datastructs.Host, err error) {
+	if len(args) == 1 {
+		filter = datastructs.Filter{And: []datastructs.Filter{filter, {Or: []datastructs.Filter{
+			{Field: "hostname", Match: datastructs.MatchGlob, Value: "*" + args[0] + "*"},
+			{Field: "ip", Match: datastructs.MatchGlob, Value: "*" + args[0] + "*"},
+		}}}}
+	}
+
+	return db.FilterHostsContext(ctx, filter)
+}
+
+// loadAddresses set the addresses of every host, hosts without addresses get an empty list
+func loadAddresses(ctx context.Context, db database.Querier, hosts datastructs.Hosts) error {
+	addresses, err := db.GetAddressesContext(ctx)
 	if err != nil {
-		return hosts, err
+		return err
 	}
 
-	return hosts, nil
+	for i := range hosts {
+		hosts[i].Addresses = datastructs.Addresses{}
+
+		for j := range addresses {
+			if addresses[j].HostID == hosts[i].ID {
+				hosts[i].Addresses = append(hosts[i].Addresses, addresses[j])
+			}
+		}
+	}
+
+	return nil
 }
 
 var viewHostGroupVar = &cobra.Command{
@@ -118,23 +270,23 @@ var viewHostGroupVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		viewHostGroup(args)
+		viewHostGroup(cmd.Context(), args)
 	},
 }
 
-func viewHostGroup(args []string) {
+func viewHostGroup(ctx context.Context, args []string) {
 	var hgs []datastructs.HostGroup
 
 	var err error
 
 	switch len(args) {
 	case 0:
-		hgs, err = listHostGroups()
+		hgs, err = listHostGroups(ctx)
 		if err != nil {
 			log.Fatal(err)
 		}
 	case 1:
-		hgs, err = scanHostGroups(args[0])
+		hgs, err = scanHostGroups(ctx, args[0])
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -143,8 +295,8 @@ func viewHostGroup(args []string) {
 	printHostGroups(hgs)
 }
 
-func listHostGroups() (hg []datastructs.HostGroup, err error) {
-	hg, err = DB.GetHostGroups()
+func listHostGroups(ctx context.Context) (hg []datastructs.HostGroup, err error) {
+	hg, err = DB.GetHostGroupsContext(ctx)
 	if err != nil {
 		return hg, err
 	}
@@ -152,8 +304,8 @@ func listHostGroups() (hg []datastructs.HostGroup, err error) {
 	return hg, nil
 }
 
-func scanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error) {
-	hostGroups, err = DB.ScanHostGroups(val)
+func scanHostGroups(ctx context.Context, val string) (hostGroups []datastructs.HostGroup, err error) {
+	hostGroups, err = DB.ScanHostGroupsContext(ctx, val)
 	if err != nil {
 		return hostGroups, err
 	}
@@ -165,38 +317,74 @@ var viewGroupVar = &cobra.Command{
 	Use:   "group ['group name']",
 	Short: "view existing group",
 	Long: "view existing group by substring of group name or view all records when no argument passed" +
-		"pass the flag `-j,--json` to view the group in json structure with group variables",
-	Example:           "admiral view group\nadmiral view group group1\nadmiral view group group1 -j",
+		"pass the flag `-j,--json` to view the group in json structure with group variables." +
+		" `-w,--where`, `--var` and `--has-var` filter the groups the same way as `admiral view host`",
+	Example: "admiral view group\nadmiral view group group1\nadmiral view group group1 -j\n" +
+		"admiral view group --where 'name^=web and monitored=false'\nadmiral view group --has-var env\n" +
+		"admiral view group --limit 20",
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		viewGroup(args)
+		viewGroup(cmd.Context(), args)
 	},
 }
 
-func viewGroup(args []string) {
+func viewGroup(ctx context.Context, args []string) {
 	var groups datastructs.Groups
 
 	var err error
 
-	switch len(args) {
-	case 0:
-		groups, err = listGroups()
+	filter, err := viewFilter(whereFilter, varFilters, hasVars)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	page := datastructs.Page{Limit: viewLimit, Offset: viewOffset}
+	if err = page.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// all groups in name order are paged while read from the database, other listings once filtered and sorted
+	dbPaged := filter.IsZero() && len(args) == 0 && changedSince == "" && createdBefore == "" && sortG == "name"
+
+	switch {
+	case !filter.IsZero():
+		groups, err = whereGroups(ctx, DB, filter, args)
 		if err != nil {
 			log.Fatal(err)
 		}
-	case 1:
-		groups, err = scanGroups(args[0])
+	case dbPaged:
+		groups, err = listGroups(ctx, page)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case len(args) == 0:
+		groups, err = listGroups(ctx, datastructs.Page{})
+		if err != nil {
+			log.Fatal(err)
+		}
+	case len(args) == 1:
+		groups, err = scanGroups(ctx, args[0])
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 
+	groups, err = filterGroups(groups, changedSince, createdBefore)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = groups.Sort(sortG)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if !dbPaged {
+		start, end := page.Bounds(len(groups))
+		groups = groups[start:end]
+	}
+
 	if viewAsJSON {
 		if len(groups) > 0 {
 			for i := range groups {
@@ -213,8 +401,19 @@ func viewGroup(args []string) {
 	}
 }
 
-func viewGroupByName(name string) (group datastructs.Group, err error) {
-	group, err = DB.SelectGroup(name)
+// whereGroups return the groups matching the filter and, when given, the substring of group name in args
+func whereGroups(ctx context.Context, db database.Querier, filter datastructs.Filter, args []string) (
+	groups []datastructs.Group, err error) {
+	if len(args) == 1 {
+		filter = datastructs.Filter{And: []datastructs.Filter{filter,
+			{Field: "name", Match: datastructs.MatchGlob, Value: "*" + args[0] + "*"}}}
+	}
+
+	return db.FilterGroupsContext(ctx, filter)
+}
+
+func viewGroupByName(ctx context.Context, db database.Querier, name string) (group datastructs.Group, err error) {
+	group, err = db.SelectGroupContext(ctx, name)
 	if err != nil {
 		return group, err
 	} else if group.ID == 0 {
@@ -224,17 +423,18 @@ func viewGroupByName(name string) (group datastructs.Group, err error) {
 	return group, nil
 }
 
-func listGroups() (groups []datastructs.Group, err error) {
-	groups, err = DB.GetGroups()
-	if err != nil {
-		return groups, err
-	}
+// listGroups return the page of all groups ordered by name
+func listGroups(ctx context.Context, page datastructs.Page) (groups []datastructs.Group, err error) {
+	err = DB.IterateGroupsContext(ctx, page, func(group *datastructs.Group) error {
+		groups = append(groups, *group)
+		return nil
+	})
 
-	return groups, nil
+	return groups, err
 }
 
-func scanGroups(val string) (groups []datastructs.Group, err error) {
-	groups, err = DB.ScanGroups(val)
+func scanGroups(ctx context.Context, val string) (groups []datastructs.Group, err error) {
+	groups, err = DB.ScanGroupsContext(ctx, val)
 	if err != nil {
 		return groups, err
 	}
@@ -251,23 +451,23 @@ var viewChildVar = &cobra.Command{
 	ValidArgsFunction: groupsArgsFunc,
 	Args:              cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		viewChild(args)
+		viewChild(cmd.Context(), args)
 	},
 }
 
-func viewChild(args []string) {
+func viewChild(ctx context.Context, args []string) {
 	var childGroups datastructs.ChildGroups
 
 	var err error
 
 	switch len(args) {
 	case 0:
-		childGroups, err = listChildGroups()
+		childGroups, err = listChildGroups(ctx)
 		if err != nil {
 			log.Fatal(err)
 		}
 	case 1:
-		childGroups, err = scanChildGroups(args[0])
+		childGroups, err = scanChildGroups(ctx, args[0])
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -281,8 +481,8 @@ func viewChild(args []string) {
 	printChildGroups(childGroups)
 }
 
-func listChildGroups() (childGroups []datastructs.ChildGroup, err error) {
-	childGroups, err = DB.GetChildGroups()
+func listChildGroups(ctx context.Context) (childGroups []datastructs.ChildGroup, err error) {
+	childGroups, err = DB.GetChildGroupsContext(ctx)
 	if err != nil {
 		return childGroups, err
 	}
@@ -290,8 +490,8 @@ func listChildGroups() (childGroups []datastructs.ChildGroup, err error) {
 	return childGroups, nil
 }
 
-func viewChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error) {
-	childGroups, err = DB.SelectChildGroup(child, parent)
+func viewChildGroup(ctx context.Context, child, parent string) (childGroups []datastructs.ChildGroup, err error) {
+	childGroups, err = DB.SelectChildGroupContext(ctx, child, parent)
 	if err != nil {
 		return childGroups, err
 	} else if childGroups == nil {
@@ -301,11 +501,120 @@ func viewChildGroup(child, parent string) (childGroups []datastructs.ChildGroup,
 	return childGroups, nil
 }
 
-func scanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error) {
-	childGroups, err = DB.ScanChildGroups(val)
+func scanChildGroups(ctx context.Context, val string) (childGroups []datastructs.ChildGroup, err error) {
+	childGroups, err = DB.ScanChildGroupsContext(ctx, val)
 	if err != nil {
 		return childGroups, err
 	}
 
 	return childGroups, nil
 }
+
+// timeFilter match records by their created and updated timestamps, zero values match everything
+type timeFilter struct {
+	changedSince  time.Time
+	createdBefore time.Time
+}
+
+// newTimeFilter parse the `--changed-since` duration relative to now and the `--created-before` date
+func newTimeFilter(now time.Time, changedSince, createdBefore string) (f timeFilter, err error) {
+	if changedSince != "" {
+		age, err := parseAge(changedSince)
+		if err != nil {
+			return f, err
+		}
+
+		f.changedSince = now.Add(-age)
+	}
+
+	if createdBefore != "" {
+		f.createdBefore, err = parseDate(createdBefore)
+		if err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
+
+func (f timeFilter) match(created, updated string) bool {
+	if !f.changedSince.IsZero() {
+		t, err := time.Parse(time.RFC3339, updated)
+		if err != nil || t.Before(f.changedSince) {
+			return false
+		}
+	}
+
+	if !f.createdBefore.IsZero() {
+		t, err := time.Parse(time.RFC3339, created)
+		if err != nil || !t.Before(f.createdBefore) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseAge parse a duration accepting days (e.g. 7d) on top of the units supported by time.ParseDuration
+func parseAge(val string) (time.Duration, error) {
+	if strings.HasSuffix(val, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(val, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid duration %q", val)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(val)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid duration %q", val)
+	}
+
+	return age, nil
+}
+
+// parseDate parse date in the format YYYY-MM-DD (UTC) or RFC3339
+func parseDate(val string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", val); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q, expecting YYYY-MM-DD or RFC3339", val)
+	}
+
+	return t, nil
+}
+
+func filterHosts(hosts datastructs.Hosts, changedSince, createdBefore string) (filtered datastructs.Hosts, err error) {
+	f, err := newTimeFilter(time.Now(), changedSince, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hosts {
+		if f.match(hosts[i].Created, hosts[i].Updated) {
+			filtered = append(filtered, hosts[i])
+		}
+	}
+
+	return filtered, nil
+}
+
+func filterGroups(groups datastructs.Groups, changedSince, createdBefore string) (
+	filtered datastructs.Groups, err error) {
+	f, err := newTimeFilter(time.Now(), changedSince, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if f.match(groups[i].Created, groups[i].Updated) {
+			filtered = append(filtered, groups[i])
+		}
+	}
+
+	return filtered, nil
+}
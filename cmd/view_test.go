@@ -2,9 +2,12 @@
 package cmd
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/via-justa/admiral/database"
 	"github.com/via-justa/admiral/datastructs"
 )
 
@@ -16,12 +19,12 @@ func Example_viewHost() {
 
 	viewAsJSON = false
 
-	viewHost([]string{})
+	viewHost(context.Background(), []string{})
 	// Output:
-	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups
-	// 1.1.1.1      |host1        |domain.local |true         |true         |group1       |
-	// 2.2.2.2      |host2        |domain.local |true         |true         |group2       |
-	// 3.3.3.3      |host3        |domain.local |true         |true         |group3       |group4,group5
+	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups |Created              |Updated
+	// 1.1.1.1      |host1        |domain.local |true         |true         |group1       |                 |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
+	// 2.2.2.2      |host2        |domain.local |true         |true         |group2       |                 |2020-11-01T10:00:00Z |2020-12-01T10:00:00Z
+	// 3.3.3.3      |host3        |domain.local |true         |true         |group3       |group4,group5    |2020-12-01T10:00:00Z |2020-12-01T10:00:00Z
 }
 
 // admiral view host host1
@@ -32,10 +35,10 @@ func Example_viewSingleHost() {
 
 	viewAsJSON = false
 
-	viewHost([]string{"host1"})
+	viewHost(context.Background(), []string{"host1"})
 	// Output:
-	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups
-	// 1.1.1.1      |host1        |domain.local |true         |true         |group1       |
+	// IP           |Hostname     |domain       |Enabled      |Monitored    |Direct Group |Inherited Groups |Created              |Updated
+	// 1.1.1.1      |host1        |domain.local |true         |true         |group1       |                 |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
 }
 
 // admiral view host host1 -j
@@ -46,7 +49,7 @@ func Example_viewSingleHostAsJSON() {
 
 	viewAsJSON = true
 
-	viewHost([]string{"host1"})
+	viewHost(context.Background(), []string{"host1"})
 	// Output:
 	// 	[
 	//     {
@@ -60,7 +63,13 @@ func Example_viewSingleHostAsJSON() {
 	//         },
 	//         "enable": true,
 	//         "monitor": true,
-	//         "direct_group": "group1"
+	//         "direct_groups": [
+	//             "group1"
+	//         ],
+	//         "addresses": [],
+	//         "created": "2020-11-01T10:00:00Z",
+	//         "updated": "2020-11-01T10:00:00Z",
+	//         "revision": 1
 	//     }
 	// ]
 }
@@ -72,6 +81,7 @@ func Test_listHosts(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		page      datastructs.Page
 		wantHosts []datastructs.Host
 		wantErr   bool
 	}{
@@ -80,10 +90,19 @@ func Test_listHosts(t *testing.T) {
 			wantHosts: []datastructs.Host{testHost1, testHost2, testHost3},
 			wantErr:   false,
 		},
+		{
+			name:      "Page of hosts",
+			page:      datastructs.Page{Limit: 2, Offset: 1},
+			wantHosts: []datastructs.Host{testHost2, testHost3},
+		},
+		{
+			name: "Offset past the hosts",
+			page: datastructs.Page{Offset: 3},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHosts, err := listHosts()
+			gotHosts, err := listHosts(context.Background(), tt.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listHosts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -144,7 +163,7 @@ func Test_scanHosts(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHosts, err := scanHosts(tt.args.val)
+			gotHosts, err := scanHosts(context.Background(), DB, tt.args.val)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("scanHosts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -162,7 +181,7 @@ func Example_viewHostGroup() {
 
 	defer testDB.Close()
 
-	viewHostGroup([]string{})
+	viewHostGroup(context.Background(), []string{})
 	// Output:
 	// Group        | Group ID     | Hostname     | Host ID
 	// group1       | 1            | host1        | 1
@@ -176,11 +195,91 @@ func Example_viewSingleHostGroup() {
 
 	defer testDB.Close()
 
-	viewHostGroup([]string{"group1"})
+	viewHostGroup(context.Background(), []string{"group1"})
 	// Output:
 	// Group        | Group ID     | Hostname     | Host ID
 	// group1       | 1            | host1        | 1
 }
+func Test_whereHosts(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	tests := []struct {
+		name      string
+		where     string
+		vars      []string
+		hasVars   []string
+		args      []string
+		wantHosts []datastructs.Host
+		wantErr   bool
+	}{
+		{
+			name:      "inherited group",
+			where:     "inherited_group=group5 or group=group1",
+			wantHosts: []datastructs.Host{testHost1, testHost3},
+		},
+		{
+			name:      "filter and substring",
+			where:     "domain=domain.local and monitored=true",
+			args:      []string{"2.2"},
+			wantHosts: []datastructs.Host{testHost2},
+		},
+		{
+			name:  "no match",
+			where: "monitored=false",
+		},
+		{
+			name:    "unknown field",
+			where:   "name=host1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter",
+			where:   "hostname=host1 and",
+			wantErr: true,
+		},
+		{
+			name:      "var",
+			vars:      []string{"host_var1.host_sub_var1=host_sub_val1"},
+			wantHosts: []datastructs.Host{testHost1},
+		},
+		{
+			name:      "has var and where",
+			where:     "domain=domain.local",
+			hasVars:   []string{"host_var3"},
+			wantHosts: []datastructs.Host{testHost3},
+		},
+		{
+			name:    "var and has var",
+			vars:    []string{"host_var2~=^host"},
+			hasVars: []string{"host_var1"},
+		},
+		{
+			name:    "var without match",
+			vars:    []string{"host_var1"},
+			wantErr: true,
+		},
+		{
+			name:    "var with or",
+			vars:    []string{"host_var1=a or host_var2=b"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHosts, err := viewFilterHosts(context.Background(), testDB, tt.where, tt.vars, tt.hasVars, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("whereHosts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotHosts, tt.wantHosts) {
+				t.Errorf("whereHosts() = %v, want %v", gotHosts, tt.wantHosts)
+			}
+		})
+	}
+}
+
 func Test_listHostGroups(t *testing.T) {
 	testDB := prepEnv()
 
@@ -199,7 +298,7 @@ func Test_listHostGroups(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHg, err := listHostGroups()
+			gotHg, err := listHostGroups(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listHostGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -260,7 +359,7 @@ func Test_scanHostGroups(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotHostGroups, err := scanHostGroups(tt.args.val)
+			gotHostGroups, err := scanHostGroups(context.Background(), tt.args.val)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("scanHostGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -280,14 +379,14 @@ func Example_viewGroup() {
 
 	viewAsJSON = false
 
-	viewGroup([]string{})
+	viewGroup(context.Background(), []string{})
 	// Output:
-	// name         |Enabled      |Monitored    |Children count |Hosts count
-	// group1       |true         |true         |0              |1
-	// group2       |true         |true         |0              |1
-	// group3       |true         |true         |0              |1
-	// group4       |true         |true         |1              |0
-	// group5       |true         |true         |2              |0
+	// name         |Enabled      |Monitored    |Children count |Hosts count  |Created              |Updated
+	// group1       |true         |true         |0              |1            |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
+	// group2       |true         |true         |0              |1            |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
+	// group3       |true         |true         |0              |1            |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
+	// group4       |true         |true         |1              |0            |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
+	// group5       |true         |true         |2              |0            |2020-12-01T10:00:00Z |2020-12-01T10:00:00Z
 }
 
 // admiral view group group1
@@ -298,10 +397,10 @@ func Example_viewSingleGroup() {
 
 	viewAsJSON = false
 
-	viewGroup([]string{"group1"})
+	viewGroup(context.Background(), []string{"group1"})
 	// Output:
-	// name         |Enabled      |Monitored    |Children count |Hosts count
-	// group1       |true         |true         |0              |1
+	// name         |Enabled      |Monitored    |Children count |Hosts count  |Created              |Updated
+	// group1       |true         |true         |0              |1            |2020-11-01T10:00:00Z |2020-11-01T10:00:00Z
 }
 
 // admiral view group group1 -j
@@ -312,7 +411,7 @@ func Example_viewSingleGroupAsJSON() {
 
 	viewAsJSON = true
 
-	viewGroup([]string{"group1"})
+	viewGroup(context.Background(), []string{"group1"})
 	// Output:
 	// [
 	//     {
@@ -323,7 +422,10 @@ func Example_viewSingleGroupAsJSON() {
 	//             }
 	//         },
 	//         "enable": true,
-	//         "monitor": true
+	//         "monitor": true,
+	//         "created": "2020-11-01T10:00:00Z",
+	//         "updated": "2020-11-01T10:00:00Z",
+	//         "revision": 1
 	//     }
 	// ]
 }
@@ -369,7 +471,7 @@ func Test_viewGroupByName(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotGroup, err := viewGroupByName(tt.args.name)
+			gotGroup, err := viewGroupByName(context.Background(), DB, tt.args.name)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("viewGroupByName() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -388,6 +490,7 @@ func Test_listGroups(t *testing.T) {
 
 	tests := []struct {
 		name       string
+		page       datastructs.Page
 		wantGroups []datastructs.Group
 		wantErr    bool
 	}{
@@ -396,10 +499,15 @@ func Test_listGroups(t *testing.T) {
 			wantGroups: []datastructs.Group{testGroup1, testGroup2, testGroup3, testGroup4, testGroup5},
 			wantErr:    false,
 		},
+		{
+			name:       "Page of groups",
+			page:       datastructs.Page{Limit: 2},
+			wantGroups: []datastructs.Group{testGroup1, testGroup2},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotGroups, err := listGroups()
+			gotGroups, err := listGroups(context.Background(), tt.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -460,7 +568,7 @@ func Test_scanGroups(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotGroups, err := scanGroups(tt.args.val)
+			gotGroups, err := scanGroups(context.Background(), tt.args.val)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("scanGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -478,7 +586,7 @@ func Example_viewChildGroup() {
 
 	defer testDB.Close()
 
-	viewChild([]string{})
+	viewChild(context.Background(), []string{})
 	// Output:
 	// Parent       |Parent ID    |Child        |Child ID
 	// group4       |4            |group3       |3
@@ -491,12 +599,58 @@ func Example_viewSingleChildGroup() {
 
 	defer testDB.Close()
 
-	viewChild([]string{"group5"})
+	viewChild(context.Background(), []string{"group5"})
 	// Output:
 	// Parent       |Parent ID    |Child        |Child ID
 	// group5       |5            |group4       |4
 }
 
+func Test_whereGroups(t *testing.T) {
+	testDB := prepEnv()
+
+	defer testDB.Close()
+
+	tests := []struct {
+		name       string
+		where      string
+		vars       []string
+		hasVars    []string
+		args       []string
+		wantGroups []datastructs.Group
+		wantErr    bool
+	}{
+		{
+			name:       "name glob",
+			where:      "name*=group[12] or name*=group?",
+			args:       []string{"1"},
+			wantGroups: []datastructs.Group{testGroup1},
+		},
+		{
+			name:    "host field",
+			where:   "hostname=host1",
+			wantErr: true,
+		},
+		{
+			name:       "var and has var",
+			vars:       []string{"group_var4^=GROUP_"},
+			hasVars:    []string{"group_var4"},
+			wantGroups: []datastructs.Group{testGroup4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotGroups, err := viewFilterGroups(context.Background(), testDB, tt.where, tt.vars, tt.hasVars, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("whereGroups() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotGroups, tt.wantGroups) {
+				t.Errorf("whereGroups() = %v, want %v", gotGroups, tt.wantGroups)
+			}
+		})
+	}
+}
+
 func Test_listChildGroups(t *testing.T) {
 	testDB := prepEnv()
 
@@ -515,7 +669,7 @@ func Test_listChildGroups(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotChildGroups, err := listChildGroups()
+			gotChildGroups, err := listChildGroups(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listChildGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -563,7 +717,7 @@ func Test_viewChildGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotChildGroups, err := viewChildGroup(tt.args.child, tt.args.parent)
+			gotChildGroups, err := viewChildGroup(context.Background(), tt.args.child, tt.args.parent)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("viewChildGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -624,7 +778,7 @@ func Test_scanChildGroups(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotChildGroups, err := scanChildGroups(tt.args.val)
+			gotChildGroups, err := scanChildGroups(context.Background(), tt.args.val)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("scanChildGroups() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -635,3 +789,109 @@ func Test_scanChildGroups(t *testing.T) {
 		})
 	}
 }
+
+func Test_timeFilter(t *testing.T) {
+	now := time.Date(2020, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		changedSince  string
+		createdBefore string
+		wantMatch     []string
+		wantErr       bool
+	}{
+		{
+			name:      "no filter",
+			wantMatch: []string{"host1", "host2", "host3"},
+		},
+		{
+			name:         "changed in the last 24 hours",
+			changedSince: "24h",
+			wantMatch:    []string{"host2", "host3"},
+		},
+		{
+			name:         "changed in the last 7 days",
+			changedSince: "7d",
+			wantMatch:    []string{"host2", "host3"},
+		},
+		{
+			name:         "changed in the last hour",
+			changedSince: "1h",
+		},
+		{
+			name:          "created before date",
+			createdBefore: "2020-12-01",
+			wantMatch:     []string{"host1", "host2"},
+		},
+		{
+			name:          "created before timestamp and changed since",
+			changedSince:  "31d",
+			createdBefore: "2020-12-01T10:00:01Z",
+			wantMatch:     []string{"host1", "host2", "host3"},
+		},
+		{
+			name:         "invalid duration",
+			changedSince: "yesterday",
+			wantErr:      true,
+		},
+		{
+			name:          "invalid date",
+			createdBefore: "01/12/2020",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newTimeFilter(now, tt.changedSince, tt.createdBefore)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTimeFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var gotMatch []string
+
+			for _, h := range []datastructs.Host{testHost1, testHost2, testHost3} {
+				if f.match(h.Created, h.Updated) {
+					gotMatch = append(gotMatch, h.Hostname)
+				}
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(gotMatch, tt.wantMatch) {
+				t.Errorf("timeFilter.match() = %v, want %v", gotMatch, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func Test_filterGroups(t *testing.T) {
+	groups := datastructs.Groups{testGroup1, testGroup5}
+
+	got, err := filterGroups(groups, "", "2020-12-01")
+	if err != nil {
+		t.Fatalf("filterGroups() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, datastructs.Groups{testGroup1}) {
+		t.Errorf("filterGroups() = %v, want %v", got, datastructs.Groups{testGroup1})
+	}
+}
+
+func viewFilterHosts(ctx context.Context, db database.Querier, where string, vars, hasVars, args []string) (
+	[]datastructs.Host, error) {
+	filter, err := viewFilter(where, vars, hasVars)
+	if err != nil {
+		return nil, err
+	}
+
+	return whereHosts(ctx, db, filter, args)
+}
+
+func viewFilterGroups(ctx context.Context, db database.Querier, where string, vars, hasVars, args []string) (
+	[]datastructs.Group, error) {
+	filter, err := viewFilter(where, vars, hasVars)
+	if err != nil {
+		return nil, err
+	}
+
+	return whereGroups(ctx, db, filter, args)
+}
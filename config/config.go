@@ -1,12 +1,23 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"os/user"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/via-justa/admiral/datastructs"
 )
 
+// DefaultTimeout is used to bound connecting to and querying the database when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultKeepAlive is the interval of the ssh proxy keepalive requests when no interval is configured
+const DefaultKeepAlive = 30 * time.Second
+
 // MariaDBConfig MariaDB specific configurations
 type MariaDBConfig struct {
 	User     string
@@ -14,6 +25,34 @@ type MariaDBConfig struct {
 	Host     string
 	Port     int
 	DB       string
+	Socket   string        // Unix socket path, used instead of host and port
+	Timeout  time.Duration // Connect and query timeout, e.g. "10s"
+	// MySQL option file, e.g. "~/.my.cnf", its [client] settings are used for the settings not set here
+	OptionFile      string        `toml:"option-file" mapstructure:"option-file"`
+	MaxOpenConns    int           `toml:"max-open-conns" mapstructure:"max-open-conns"`       // Zero is unlimited
+	MaxIdleConns    int           `toml:"max-idle-conns" mapstructure:"max-idle-conns"`       // Zero keep 2
+	ConnMaxLifetime time.Duration `toml:"conn-max-lifetime" mapstructure:"conn-max-lifetime"` // Zero is unlimited
+	TLS             MariaDBTLS    `toml:"tls" mapstructure:"tls"`
+}
+
+// MariaDBTLS MariaDB TLS settings, TLS is used when any of them is set
+type MariaDBTLS struct {
+	CA         string // PEM file of the certificate authorities verifying the server, defaults to the system ones
+	Cert       string // PEM file of the client certificate, set together with Key
+	Key        string
+	ServerName string `toml:"server-name" mapstructure:"server-name"` // Verified server name, defaults to Host
+	SkipVerify bool   `toml:"skip-verify" mapstructure:"skip-verify"` // Accept any server certificate
+}
+
+// PostgresConfig PostgreSQL specific configurations
+type PostgresConfig struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	DB       string
+	SSLMode  string        `toml:"ssl-mode" mapstructure:"ssl-mode"`
+	Timeout  time.Duration // Connect and query timeout, e.g. "10s"
 }
 
 // SSHProxy SSH settings to proxy commends thru
@@ -23,6 +62,10 @@ type SSHProxy struct {
 	Host     string
 	Port     int
 	Password string
+	// File of the trusted host keys, defaults to ~/.ssh/known_hosts
+	KnownHosts string `toml:"known-hosts" mapstructure:"known-hosts"`
+	// Interval of the keepalive requests of the database tunnel, defaults to 30s, negative disable them
+	KeepAlive time.Duration `toml:"keep-alive" mapstructure:"keep-alive"`
 }
 
 // SSH settings for ssh command
@@ -37,8 +80,22 @@ type SSH struct {
 
 // SQLiteConfig SQLite specific configurations
 type SQLiteConfig struct {
-	Path   string
-	Memory bool // Used for tests, if set to true the database will run in memory and be discarded after each run.
+	Path    string
+	Memory  bool          // Used for tests, if set to true the database will run in memory and be discarded after each run.
+	Timeout time.Duration // Query and lock wait timeout, e.g. "10s"
+}
+
+// FilesConfig YAML files directory specific configurations
+type FilesConfig struct {
+	Path  string // Directory holding the hosts and groups files, created if missing
+	Audit string // Audit log file, default <Path>.audit_log.yaml next to the directory
+}
+
+// RemoteConfig admiral API server used instead of a database, see `admiral serve`
+type RemoteConfig struct {
+	URL     string        // Base URL of the server, e.g. "https://admiral.example.com"
+	Token   string        // API token, one of the server [serve.tokens]
+	Timeout time.Duration // Request timeout, e.g. "10s"
 }
 
 // DefaultsConfig specific hosts and groups default configurations
@@ -48,13 +105,37 @@ type DefaultsConfig struct {
 	Enabled   bool
 }
 
+// PrometheusConfig settings of the prometheus targets served by `admiral prometheus --listen`
+type PrometheusConfig struct {
+	User     string // Basic auth user, the targets are served without authentication when empty
+	Password string
+}
+
+// ServeConfig settings of `admiral serve`
+type ServeConfig struct {
+	// API tokens by the name recorded in the audit log as the author of the changes made with them, the API is
+	// served without authentication when there are no tokens, the remote clients are then refused
+	Tokens map[string]string
+}
+
+// AuditConfig audit log settings
+type AuditConfig struct {
+	Actor string // Name recorded as the author of changes, defaults to the OS user
+}
+
 // Config database configuration for admiral client
 type Config struct {
-	SQLite   SQLiteConfig   `toml:"sqlite" mapstructure:"sqlite"`
-	MariaDB  MariaDBConfig  `toml:"mariadb" mapstructure:"mariadb"`
-	Defaults DefaultsConfig `toml:"defaults" mapstructure:"defaults"`
-	SSHProxy SSHProxy       `toml:"ssh-proxy" mapstructure:"ssh-proxy"`
-	SSH      SSH            `toml:"ssh" mapstructure:"ssh"`
+	SQLite     SQLiteConfig     `toml:"sqlite" mapstructure:"sqlite"`
+	MariaDB    MariaDBConfig    `toml:"mariadb" mapstructure:"mariadb"`
+	Postgres   PostgresConfig   `toml:"postgres" mapstructure:"postgres"`
+	Files      FilesConfig      `toml:"files" mapstructure:"files"`
+	Remote     RemoteConfig     `toml:"remote" mapstructure:"remote"`
+	Defaults   DefaultsConfig   `toml:"defaults" mapstructure:"defaults"`
+	SSHProxy   SSHProxy         `toml:"ssh-proxy" mapstructure:"ssh-proxy"`
+	SSH        SSH              `toml:"ssh" mapstructure:"ssh"`
+	Audit      AuditConfig      `toml:"audit" mapstructure:"audit"`
+	Prometheus PrometheusConfig `toml:"prometheus" mapstructure:"prometheus"`
+	Serve      ServeConfig      `toml:"serve" mapstructure:"serve"`
 }
 
 // NewConfig initialize new configuration
@@ -94,6 +175,40 @@ func NewConfig() *Config {
 	return conf
 }
 
+// Backends are the names of the database backend sections of the config file
+var Backends = []string{"mariadb", "postgres", "sqlite", "files", "remote"}
+
+// Backend return a copy of the config keeping only the named database backend section, it is used to
+// connect to a backend other than the one admiral use when the config file holds more than one
+func (conf *Config) Backend(name string) (*Config, error) {
+	backend := *conf
+	backend.MariaDB, backend.Postgres, backend.SQLite, backend.Files, backend.Remote = MariaDBConfig{},
+		PostgresConfig{}, SQLiteConfig{}, FilesConfig{}, RemoteConfig{}
+
+	var configured bool
+
+	switch name {
+	case "mariadb":
+		backend.MariaDB, configured = conf.MariaDB, conf.MariaDB != MariaDBConfig{}
+	case "postgres":
+		backend.Postgres, configured = conf.Postgres, conf.Postgres != PostgresConfig{}
+	case "sqlite":
+		backend.SQLite, configured = conf.SQLite, conf.SQLite != SQLiteConfig{}
+	case "files":
+		backend.Files, configured = conf.Files, conf.Files != FilesConfig{}
+	case "remote":
+		backend.Remote, configured = conf.Remote, conf.Remote != RemoteConfig{}
+	default:
+		return nil, fmt.Errorf("unknown backend %v, expecting one of %v", name, strings.Join(Backends, ", "))
+	}
+
+	if !configured {
+		return nil, fmt.Errorf("backend %v is not configured, add a [%v] section to the config file", name, name)
+	}
+
+	return &backend, nil
+}
+
 // NewDefaultHost return host with defaults from config
 func (conf *Config) NewDefaultHost() datastructs.Host {
 	return datastructs.Host{
@@ -112,3 +227,21 @@ func (conf *Config) NewDefaultGroup() datastructs.Group {
 		Variables: "{}",
 	}
 }
+
+// Actor return the name recorded in the audit log as the author of changes, the configured
+// actor or the OS user running admiral
+func (conf *Config) Actor() string {
+	if conf.Audit.Actor != "" {
+		return conf.Audit.Actor
+	}
+
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	if u := os.Getenv("USER"); u != "" {
+		return u
+	}
+
+	return "unknown"
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/via-justa/admiral/datastructs"
 )
@@ -20,6 +21,7 @@ var (
 		Host:     "localhost",
 		Port:     3306,
 		DB:       "ansible",
+		Timeout:  10 * time.Second,
 	}
 
 	testSQLiteConfig = SQLiteConfig{
@@ -109,3 +111,73 @@ func Test_config_newDefaultGroup(t *testing.T) {
 		})
 	}
 }
+
+func Test_config_Actor(t *testing.T) {
+	tests := []struct {
+		name  string
+		audit AuditConfig
+		want  string
+	}{
+		{
+			name:  "actor from config",
+			audit: AuditConfig{Actor: "jane"},
+			want:  "jane",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{
+				Audit: tt.audit,
+			}
+			if got := conf.Actor(); got != tt.want {
+				t.Errorf("config.Actor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&Config{}).Actor(); got == "" {
+		t.Errorf("config.Actor() = %v, want OS user", got)
+	}
+}
+
+func Test_config_Backend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name:    "configured backend",
+			backend: "sqlite",
+			want:    &Config{SQLite: testSQLiteConfig, Defaults: testDefaultConfig},
+		},
+		{
+			name:    "backend other than the one in use",
+			backend: "mariadb",
+			want:    &Config{MariaDB: testMariadbConfig, Defaults: testDefaultConfig},
+		},
+		{
+			name:    "not configured backend",
+			backend: "postgres",
+			wantErr: true,
+		},
+		{
+			name:    "unknown backend",
+			backend: "mysql",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testConfig.Backend(tt.backend)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("config.Backend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config.Backend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,816 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// actorKey is the context key of the actor set by WithActor
+type actorKey struct{}
+
+// WithActor return a copy of ctx recording the changes made with it in the audit log as made by actor instead of
+// the actor of the connection, e.g. the user of an API request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// auditStore is implemented by the backends to persist the audit log
+type auditStore interface {
+	InsertChangeContext(ctx context.Context, change *datastructs.Change) (id int64, err error)
+}
+
+// auditedDB record every change made through the database write functions in the audit log.
+// Writes made outside of a transaction run in their own transaction together with their audit record
+type auditedDB struct {
+	DBInterface
+	actor string
+}
+
+func (db auditedDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db auditedDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.DBInterface.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := tx.(auditStore)
+	if !ok {
+		tx.Rollback() // nolint: errcheck,gosec
+		return nil, fmt.Errorf("the configured database does not support the audit log")
+	}
+
+	return auditedTx{Tx: tx, store: store, actor: db.actor}, nil
+}
+
+// inTx run fn in a new audited transaction
+func (db auditedDB) inTx(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := db.BeginContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback() // nolint: errcheck,gosec
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db auditedDB) Migrate() (applied []datastructs.Migration, err error) {
+	m, ok := db.DBInterface.(Migrator)
+	if !ok {
+		return nil, ErrMigrationsUnsupported
+	}
+
+	return m.Migrate()
+}
+
+func (db auditedDB) MigrationStatus() (status []datastructs.Migration, err error) {
+	m, ok := db.DBInterface.(Migrator)
+	if !ok {
+		return nil, ErrMigrationsUnsupported
+	}
+
+	return m.MigrationStatus()
+}
+
+func (db auditedDB) InsertHost(host *datastructs.Host) (affected int64, err error) {
+	return db.InsertHostContext(context.Background(), host)
+}
+
+func (db auditedDB) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.InsertHostContext(ctx, host)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) DeleteHost(host *datastructs.Host) (affected int64, err error) {
+	return db.DeleteHostContext(context.Background(), host)
+}
+
+func (db auditedDB) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.DeleteHostContext(ctx, host)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) InsertGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.InsertGroupContext(context.Background(), group)
+}
+
+func (db auditedDB) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.InsertGroupContext(ctx, group)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.DeleteGroupContext(context.Background(), group)
+}
+
+func (db auditedDB) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.DeleteGroupContext(ctx, group)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) RestoreHost(host *datastructs.Host) (affected int64, err error) {
+	return db.RestoreHostContext(context.Background(), host)
+}
+
+func (db auditedDB) RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.RestoreHostContext(ctx, host)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) PurgeHost(host *datastructs.Host) (affected int64, err error) {
+	return db.PurgeHostContext(context.Background(), host)
+}
+
+func (db auditedDB) PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.PurgeHostContext(ctx, host)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) RestoreGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.RestoreGroupContext(context.Background(), group)
+}
+
+func (db auditedDB) RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.RestoreGroupContext(ctx, group)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) PurgeGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.PurgeGroupContext(context.Background(), group)
+}
+
+func (db auditedDB) PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.PurgeGroupContext(ctx, group)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+func (db auditedDB) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.InsertChildGroupContext(ctx, childGroup)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+func (db auditedDB) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.DeleteChildGroupContext(ctx, childGroup)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+func (db auditedDB) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.InsertHostGroupContext(ctx, hostGroup)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+func (db auditedDB) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.DeleteHostGroupContext(ctx, hostGroup)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) InsertAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.InsertAddressContext(context.Background(), address)
+}
+
+func (db auditedDB) InsertAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.InsertAddressContext(ctx, address)
+		return err
+	})
+
+	return affected, err
+}
+
+func (db auditedDB) DeleteAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.DeleteAddressContext(context.Background(), address)
+}
+
+func (db auditedDB) DeleteAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	err = db.inTx(ctx, func(tx Tx) (err error) {
+		affected, err = tx.DeleteAddressContext(ctx, address)
+		return err
+	})
+
+	return affected, err
+}
+
+// auditedTx record the changes made in a transaction in the audit log as part of the same transaction
+type auditedTx struct {
+	Tx
+	store auditStore
+	actor string
+}
+
+// record add change to the audit log and return its ID. Unless already set, the action is set from
+// the change before and after states, changes that did not modify the entity are not recorded
+func (tx auditedTx) record(ctx context.Context, change *datastructs.Change) (id int64, err error) {
+	switch {
+	case change.Before == change.After:
+		return 0, nil
+	case change.Action != "":
+	case change.Before == "":
+		change.Action = datastructs.ActionInsert
+	case change.After == "":
+		change.Action = datastructs.ActionDelete
+	default:
+		change.Action = datastructs.ActionUpdate
+	}
+
+	change.ChangedAt = time.Now().UTC().Format(time.RFC3339)
+	change.Actor = tx.actor
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		change.Actor = actor
+	}
+
+	return tx.store.InsertChangeContext(ctx, change)
+}
+
+// state return the JSON state recorded in the audit log
+func state(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (tx auditedTx) hostState(ctx context.Context, hostname string) (string, error) {
+	if hostname == "" {
+		return "", nil
+	}
+
+	host, err := tx.SelectHostContext(ctx, hostname)
+	if err != nil || host.ID == 0 {
+		return "", err
+	}
+
+	if err = host.UnmarshalVars(); err != nil {
+		return "", err
+	}
+
+	return state(host)
+}
+
+func (tx auditedTx) groupState(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	group, err := tx.SelectGroupContext(ctx, name)
+	if err != nil || group.ID == 0 {
+		return "", err
+	}
+
+	if err = group.UnmarshalVars(); err != nil {
+		return "", err
+	}
+
+	return state(group)
+}
+
+func (tx auditedTx) childGroupState(ctx context.Context, child, parent string) (string, error) {
+	if child == "" || parent == "" {
+		return "", nil
+	}
+
+	childGroups, err := tx.SelectChildGroupContext(ctx, child, parent)
+	if err != nil || len(childGroups) == 0 {
+		return "", err
+	}
+
+	return state(childGroups[0])
+}
+
+// hostGroupsState return the state of all the host group memberships
+func (tx auditedTx) hostGroupsState(ctx context.Context, hostname string) (string, error) {
+	if hostname == "" {
+		return "", nil
+	}
+
+	hostGroups, err := tx.SelectHostGroupContext(ctx, hostname)
+	if err != nil || len(hostGroups) == 0 {
+		return "", err
+	}
+
+	return state(hostGroups)
+}
+
+// addressesState return the state of all the host addresses
+func (tx auditedTx) addressesState(ctx context.Context, hostname string) (string, error) {
+	if hostname == "" {
+		return "", nil
+	}
+
+	addresses, err := tx.SelectAddressesContext(ctx, hostname)
+	if err != nil || len(addresses) == 0 {
+		return "", err
+	}
+
+	return state(addresses)
+}
+
+// hostname return name when set, otherwise the hostname of the host with the given ID
+func (tx auditedTx) hostname(ctx context.Context, id int, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	hosts, err := tx.GetHostsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range hosts {
+		if hosts[i].ID == id {
+			return hosts[i].Hostname, nil
+		}
+	}
+
+	return "", nil
+}
+
+// groupName return name when set, otherwise the name of the group with the given ID
+func (tx auditedTx) groupName(ctx context.Context, id int, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	groups, err := tx.GetGroupsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range groups {
+		if groups[i].ID == id {
+			return groups[i].Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (tx auditedTx) InsertHost(host *datastructs.Host) (affected int64, err error) {
+	return tx.InsertHostContext(context.Background(), host)
+}
+
+func (tx auditedTx) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	before, err := tx.hostState(ctx, host.Hostname)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.InsertHostContext(ctx, host)
+	if err != nil {
+		return affected, err
+	}
+
+	after, err := tx.hostState(ctx, host.Hostname)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityHost, Name: host.Hostname,
+		Before: before, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) DeleteHost(host *datastructs.Host) (affected int64, err error) {
+	return tx.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext record the host moving to the trash, its group memberships are kept for restoring it
+func (tx auditedTx) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	name, err := tx.hostname(ctx, host.ID, host.Hostname)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := tx.hostState(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.DeleteHostContext(ctx, host)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityHost, Name: name, Before: before})
+
+	return affected, err
+}
+
+func (tx auditedTx) InsertGroup(group *datastructs.Group) (affected int64, err error) {
+	return tx.InsertGroupContext(context.Background(), group)
+}
+
+func (tx auditedTx) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	before, err := tx.groupState(ctx, group.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.InsertGroupContext(ctx, group)
+	if err != nil {
+		return affected, err
+	}
+
+	after, err := tx.groupState(ctx, group.Name)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityGroup, Name: group.Name,
+		Before: before, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
+	return tx.DeleteGroupContext(context.Background(), group)
+}
+
+// DeleteGroupContext record the group moving to the trash, its relationships are kept for restoring it
+func (tx auditedTx) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	name, err := tx.groupName(ctx, group.ID, group.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := tx.groupState(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.DeleteGroupContext(ctx, group)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityGroup, Name: name, Before: before})
+
+	return affected, err
+}
+
+// deletedHost return the host with the given ID from the trash
+func (tx auditedTx) deletedHost(ctx context.Context, id int) (host datastructs.Host, err error) {
+	hosts, err := tx.GetDeletedHostsContext(ctx)
+	if err != nil {
+		return host, err
+	}
+
+	for i := range hosts {
+		if hosts[i].ID == id {
+			return hosts[i], hosts[i].UnmarshalVars()
+		}
+	}
+
+	return host, nil
+}
+
+// deletedGroup return the group with the given ID from the trash
+func (tx auditedTx) deletedGroup(ctx context.Context, id int) (group datastructs.Group, err error) {
+	groups, err := tx.GetDeletedGroupsContext(ctx)
+	if err != nil {
+		return group, err
+	}
+
+	for i := range groups {
+		if groups[i].ID == id {
+			return groups[i], groups[i].UnmarshalVars()
+		}
+	}
+
+	return group, nil
+}
+
+func (tx auditedTx) RestoreHost(host *datastructs.Host) (affected int64, err error) {
+	return tx.RestoreHostContext(context.Background(), host)
+}
+
+// RestoreHostContext record the host restored from the trash as inserted
+func (tx auditedTx) RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	deleted, err := tx.deletedHost(ctx, host.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.RestoreHostContext(ctx, host)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	after, err := tx.hostState(ctx, deleted.Hostname)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityHost, Name: deleted.Hostname, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) PurgeHost(host *datastructs.Host) (affected int64, err error) {
+	return tx.PurgeHostContext(context.Background(), host)
+}
+
+// PurgeHostContext record the host permanently deleted from the trash with its last known state
+func (tx auditedTx) PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	deleted, err := tx.deletedHost(ctx, host.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := state(deleted)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.PurgeHostContext(ctx, host)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityHost, Name: deleted.Hostname,
+		Action: datastructs.ActionPurge, Before: before})
+
+	return affected, err
+}
+
+func (tx auditedTx) RestoreGroup(group *datastructs.Group) (affected int64, err error) {
+	return tx.RestoreGroupContext(context.Background(), group)
+}
+
+// RestoreGroupContext record the group restored from the trash as inserted
+func (tx auditedTx) RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	deleted, err := tx.deletedGroup(ctx, group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.RestoreGroupContext(ctx, group)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	after, err := tx.groupState(ctx, deleted.Name)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityGroup, Name: deleted.Name, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) PurgeGroup(group *datastructs.Group) (affected int64, err error) {
+	return tx.PurgeGroupContext(context.Background(), group)
+}
+
+// PurgeGroupContext record the group permanently deleted from the trash with its last known state
+func (tx auditedTx) PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	deleted, err := tx.deletedGroup(ctx, group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := state(deleted)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = tx.Tx.PurgeGroupContext(ctx, group)
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityGroup, Name: deleted.Name,
+		Action: datastructs.ActionPurge, Before: before})
+
+	return affected, err
+}
+
+func (tx auditedTx) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return tx.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+func (tx auditedTx) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	return tx.writeChildGroup(ctx, childGroup, tx.Tx.InsertChildGroupContext)
+}
+
+func (tx auditedTx) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return tx.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+func (tx auditedTx) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	return tx.writeChildGroup(ctx, childGroup, tx.Tx.DeleteChildGroupContext)
+}
+
+// writeChildGroup record the child-group relationship state around write
+func (tx auditedTx) writeChildGroup(ctx context.Context, childGroup *datastructs.ChildGroup,
+	write func(context.Context, *datastructs.ChildGroup) (int64, error)) (affected int64, err error) {
+	child, err := tx.groupName(ctx, childGroup.ChildID, childGroup.Child)
+	if err != nil {
+		return 0, err
+	}
+
+	parent, err := tx.groupName(ctx, childGroup.ParentID, childGroup.Parent)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := tx.childGroupState(ctx, child, parent)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = write(ctx, childGroup)
+	if err != nil {
+		return affected, err
+	}
+
+	after, err := tx.childGroupState(ctx, child, parent)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityChildGroup, Name: child, Related: parent,
+		Before: before, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return tx.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+func (tx auditedTx) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	return tx.writeHostGroup(ctx, hostGroup, tx.Tx.InsertHostGroupContext)
+}
+
+func (tx auditedTx) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return tx.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+func (tx auditedTx) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	return tx.writeHostGroup(ctx, hostGroup, tx.Tx.DeleteHostGroupContext)
+}
+
+// writeHostGroup record the state of the host group memberships around write
+func (tx auditedTx) writeHostGroup(ctx context.Context, hostGroup *datastructs.HostGroup,
+	write func(context.Context, *datastructs.HostGroup) (int64, error)) (affected int64, err error) {
+	host, err := tx.hostname(ctx, hostGroup.HostID, hostGroup.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	group, err := tx.groupName(ctx, hostGroup.GroupID, hostGroup.Group)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := tx.hostGroupsState(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = write(ctx, hostGroup)
+	if err != nil {
+		return affected, err
+	}
+
+	after, err := tx.hostGroupsState(ctx, host)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityHostGroup, Name: host, Related: group,
+		Before: before, After: after})
+
+	return affected, err
+}
+
+func (tx auditedTx) InsertAddress(address *datastructs.Address) (affected int64, err error) {
+	return tx.InsertAddressContext(context.Background(), address)
+}
+
+func (tx auditedTx) InsertAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	return tx.writeAddress(ctx, address, tx.Tx.InsertAddressContext)
+}
+
+func (tx auditedTx) DeleteAddress(address *datastructs.Address) (affected int64, err error) {
+	return tx.DeleteAddressContext(context.Background(), address)
+}
+
+func (tx auditedTx) DeleteAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	return tx.writeAddress(ctx, address, tx.Tx.DeleteAddressContext)
+}
+
+// writeAddress record the state of the host addresses around write
+func (tx auditedTx) writeAddress(ctx context.Context, address *datastructs.Address,
+	write func(context.Context, *datastructs.Address) (int64, error)) (affected int64, err error) {
+	host, err := tx.hostname(ctx, address.HostID, address.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	before, err := tx.addressesState(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = write(ctx, address)
+	if err != nil {
+		return affected, err
+	}
+
+	after, err := tx.addressesState(ctx, host)
+	if err != nil {
+		return affected, err
+	}
+
+	_, err = tx.record(ctx, &datastructs.Change{Entity: datastructs.EntityAddress, Name: host, Related: address.Name,
+		Before: before, After: after})
+
+	return affected, err
+}
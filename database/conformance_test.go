@@ -0,0 +1,90 @@
+// nolint
+package database_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/database/internal/conformance"
+)
+
+// open return an Open connecting with conf to a migrated database holding the fixtures data
+func open(conf *config.Config) conformance.Open {
+	return func(t *testing.T) database.DBInterface {
+		db, err := database.Connect(conf)
+		if err != nil && os.Getenv("ADMIRAL_REQUIRE_DB") == "" {
+			t.Skipf("database is not available: %v", err)
+		} else if err != nil {
+			t.Fatalf("database is not available: %v", err)
+		}
+
+		// the files backend has no scheme to migrate
+		if migrator, ok := db.(database.Migrator); ok && conf.Files == (config.FilesConfig{}) {
+			if _, err = migrator.Migrate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err = db.PopulateTestData("../fixtures"); err != nil {
+			t.Fatal(err)
+		}
+
+		return db
+	}
+}
+
+func TestConformance_SQLite(t *testing.T) {
+	conformance.Run(t, open(&config.Config{
+		SQLite: config.SQLiteConfig{Path: "conformance.sqlite", Memory: true},
+	}))
+}
+
+func TestConformance_Files(t *testing.T) {
+	conformance.Run(t, open(&config.Config{
+		Files: config.FilesConfig{Path: t.TempDir()},
+	}))
+}
+
+// The remote suite run against an SQLite database served by RPCHandler
+func TestConformance_Remote(t *testing.T) {
+	server := open(&config.Config{
+		SQLite: config.SQLiteConfig{Path: "remote.sqlite", Memory: true},
+	})
+
+	conformance.Run(t, func(t *testing.T) database.DBInterface {
+		serverDB := server(t)
+		srv := httptest.NewServer(database.RPCHandler(serverDB))
+
+		t.Cleanup(func() {
+			srv.Close()
+			serverDB.Close()
+		})
+
+		db, err := database.Connect(&config.Config{Remote: config.RemoteConfig{URL: srv.URL}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return db
+	})
+}
+
+// The MariaDB and PostgreSQL suites run against the docker-compose databases and are skipped when they are not up,
+// unless ADMIRAL_REQUIRE_DB is set as in CI
+func TestConformance_MariaDB(t *testing.T) {
+	conformance.Run(t, open(&config.Config{
+		MariaDB: config.MariaDBConfig{User: "root", Password: "local", Host: "127.0.0.1", Port: 3306, DB: "ansible",
+			Timeout: 5 * time.Second},
+	}))
+}
+
+func TestConformance_Postgres(t *testing.T) {
+	conformance.Run(t, open(&config.Config{
+		Postgres: config.PostgresConfig{User: "postgres", Password: "local", Host: "127.0.0.1", Port: 5432,
+			DB: "ansible", SSLMode: "disable", Timeout: 5 * time.Second},
+	}))
+}
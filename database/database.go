@@ -1,55 +1,259 @@
 package database
 
 import (
+	"context"
+	"errors"
+
 	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database/internal/files"
 	"github.com/via-justa/admiral/database/internal/mariadb"
+	"github.com/via-justa/admiral/database/internal/postgres"
+	"github.com/via-justa/admiral/database/internal/remote"
 	"github.com/via-justa/admiral/database/internal/sqlite"
 	"github.com/via-justa/admiral/datastructs"
 )
 
-// DBInterface expose backend database functions
-type DBInterface interface {
+// Querier expose the read and write functions shared by the database connection and its transactions
+type Querier interface {
 	// hosts
 	SelectHost(hostname string) (returnedHost datastructs.Host, err error)
+	SelectHostContext(ctx context.Context, hostname string) (returnedHost datastructs.Host, err error)
 	GetHosts() (hosts []datastructs.Host, err error)
+	GetHostsContext(ctx context.Context) (hosts []datastructs.Host, err error)
 	InsertHost(host *datastructs.Host) (affected int64, err error)
+	InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error)
 	DeleteHost(host *datastructs.Host) (affected int64, err error)
+	DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error)
 	ScanHosts(val string) (hosts []datastructs.Host, err error)
+	ScanHostsContext(ctx context.Context, val string) (hosts []datastructs.Host, err error)
+	FilterHosts(filter datastructs.Filter) (hosts []datastructs.Host, err error)
+	FilterHostsContext(ctx context.Context, filter datastructs.Filter) (hosts []datastructs.Host, err error)
+	IterateHosts(page datastructs.Page, fn func(host *datastructs.Host) error) (err error)
+	IterateHostsContext(ctx context.Context, page datastructs.Page, fn func(host *datastructs.Host) error) (err error)
 	// groups
 	SelectGroup(name string) (returnedGroup datastructs.Group, err error)
+	SelectGroupContext(ctx context.Context, name string) (returnedGroup datastructs.Group, err error)
 	GetGroups() (groups []datastructs.Group, err error)
+	GetGroupsContext(ctx context.Context) (groups []datastructs.Group, err error)
 	InsertGroup(group *datastructs.Group) (affected int64, err error)
+	InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error)
 	DeleteGroup(group *datastructs.Group) (affected int64, err error)
+	DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error)
 	ScanGroups(val string) (groups []datastructs.Group, err error)
+	ScanGroupsContext(ctx context.Context, val string) (groups []datastructs.Group, err error)
+	FilterGroups(filter datastructs.Filter) (groups []datastructs.Group, err error)
+	FilterGroupsContext(ctx context.Context, filter datastructs.Filter) (groups []datastructs.Group, err error)
+	IterateGroups(page datastructs.Page, fn func(group *datastructs.Group) error) (err error)
+	IterateGroupsContext(ctx context.Context, page datastructs.Page, fn func(group *datastructs.Group) error) (
+		err error)
+	// trash
+	GetDeletedHosts() (hosts []datastructs.Host, err error)
+	GetDeletedHostsContext(ctx context.Context) (hosts []datastructs.Host, err error)
+	RestoreHost(host *datastructs.Host) (affected int64, err error)
+	RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error)
+	PurgeHost(host *datastructs.Host) (affected int64, err error)
+	PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error)
+	GetDeletedGroups() (groups []datastructs.Group, err error)
+	GetDeletedGroupsContext(ctx context.Context) (groups []datastructs.Group, err error)
+	RestoreGroup(group *datastructs.Group) (affected int64, err error)
+	RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error)
+	PurgeGroup(group *datastructs.Group) (affected int64, err error)
+	PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error)
 	// childGroups
 	SelectChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error)
+	SelectChildGroupContext(ctx context.Context, child, parent string) (childGroups []datastructs.ChildGroup, err error)
 	GetChildGroups() (childGroups []datastructs.ChildGroup, err error)
+	GetChildGroupsContext(ctx context.Context) (childGroups []datastructs.ChildGroup, err error)
 	InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error)
+	InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (affected int64, err error)
 	DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error)
+	DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (affected int64, err error)
 	ScanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error)
+	ScanChildGroupsContext(ctx context.Context, val string) (childGroups []datastructs.ChildGroup, err error)
 	// HOstGroups
 	SelectHostGroup(host string) (hostGroups []datastructs.HostGroup, err error)
+	SelectHostGroupContext(ctx context.Context, host string) (hostGroups []datastructs.HostGroup, err error)
 	GetHostGroups() (hostGroups []datastructs.HostGroup, err error)
+	GetHostGroupsContext(ctx context.Context) (hostGroups []datastructs.HostGroup, err error)
 	InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error)
+	InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (affected int64, err error)
 	DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error)
+	DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (affected int64, err error)
 	ScanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error)
+	ScanHostGroupsContext(ctx context.Context, val string) (hostGroups []datastructs.HostGroup, err error)
+	// addresses
+	SelectAddresses(host string) (addresses datastructs.Addresses, err error)
+	SelectAddressesContext(ctx context.Context, host string) (addresses datastructs.Addresses, err error)
+	GetAddresses() (addresses datastructs.Addresses, err error)
+	GetAddressesContext(ctx context.Context) (addresses datastructs.Addresses, err error)
+	InsertAddress(address *datastructs.Address) (affected int64, err error)
+	InsertAddressContext(ctx context.Context, address *datastructs.Address) (affected int64, err error)
+	DeleteAddress(address *datastructs.Address) (affected int64, err error)
+	DeleteAddressContext(ctx context.Context, address *datastructs.Address) (affected int64, err error)
+}
+
+// Tx is a database transaction, changes made through it are applied only once committed
+type Tx interface {
+	Querier
+	Commit() (err error)
+	Rollback() (err error)
+}
+
+// DBInterface expose backend database functions
+type DBInterface interface {
+	Querier
+	// Begin start a new transaction
+	Begin() (tx Tx, err error)
+	// BeginContext start a new transaction that is rolled back if ctx is done before it is committed
+	BeginContext(ctx context.Context) (tx Tx, err error)
+	// audit log
+	SelectChanges(entity, name string) (changes []datastructs.Change, err error)
+	SelectChangesContext(ctx context.Context, entity, name string) (changes []datastructs.Change, err error)
+	SelectChange(id int64) (change datastructs.Change, err error)
+	SelectChangeContext(ctx context.Context, id int64) (change datastructs.Change, err error)
 	// Demo Data
 	PopulateTestData(fixturesPath string) (err error)
 	Close() (err error)
 }
 
+// ErrMigrationsUnsupported is returned by the Migrator functions of backends without versioned database schema
+var ErrMigrationsUnsupported = errors.New("the configured database does not support scheme migrations")
+
+// Migrator is implemented by backends with versioned database schema
+type Migrator interface {
+	Migrate() (applied []datastructs.Migration, err error)
+	MigrationStatus() (status []datastructs.Migration, err error)
+}
+
+// Prompter ask the user for the ssh proxy key passphrase and to trust the unknown ssh proxy host key
+type Prompter = mariadb.Prompter
+
+// SSHPrompter is asked while connecting through the ssh proxy, when nil the connection fails on a passphrase
+// protected key file and an unknown host key
+var SSHPrompter Prompter
+
 // Connect return database connection from config
 func Connect(conf *config.Config) (db DBInterface, err error) {
+	return ConnectContext(context.Background(), conf)
+}
+
+// ConnectContext is Connect with a context bounding the connection attempt.
+// Changes made through the returned connection are recorded in the audit log, by the server for remote connections
+func ConnectContext(ctx context.Context, conf *config.Config) (db DBInterface, err error) {
+	db, err = connectBackend(ctx, conf)
+	if err != nil || db == nil {
+		return db, err
+	}
+
+	if _, ok := db.(remoteDB); ok {
+		return db, nil
+	}
+
+	return auditedDB{DBInterface: db, actor: conf.Actor()}, nil
+}
+
+func connectBackend(ctx context.Context, conf *config.Config) (db DBInterface, err error) {
 	switch {
+	case conf.Remote != config.RemoteConfig{}:
+		r, err := remote.ConnectContext(ctx, conf.Remote)
+		return remoteDB{r}, err
 	case conf.MariaDB != config.MariaDBConfig{}:
 		if (conf.SSHProxy != config.SSHProxy{}) {
-			return mariadb.ProxyConnect(conf)
+			m, err := mariadb.ProxyConnectContext(ctx, conf, SSHPrompter)
+			return mariadbDB{m}, err
 		}
 
-		return mariadb.Connect(conf.MariaDB)
+		m, err := mariadb.ConnectContext(ctx, conf.MariaDB)
+
+		return mariadbDB{m}, err
+	case conf.Postgres != config.PostgresConfig{}:
+		p, err := postgres.ConnectContext(ctx, conf.Postgres)
+		return postgresDB{p}, err
 	case conf.SQLite != config.SQLiteConfig{}:
-		return sqlite.Connect(&conf.SQLite)
+		s, err := sqlite.ConnectContext(ctx, &conf.SQLite)
+		return sqliteDB{s}, err
+	case conf.Files != config.FilesConfig{}:
+		f, err := files.ConnectContext(ctx, conf.Files)
+		return filesDB{f}, err
 	}
 
 	return db, err
 }
+
+// the backends cannot return the Tx interface without importing this package,
+// the wrappers below adapt their Begin functions to DBInterface
+
+type mariadbDB struct{ *mariadb.Database }
+
+func (db mariadbDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db mariadbDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.Database.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+type postgresDB struct{ *postgres.Database }
+
+func (db postgresDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db postgresDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.Database.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+type sqliteDB struct{ *sqlite.Database }
+
+func (db sqliteDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db sqliteDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.Database.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+type filesDB struct{ *files.Database }
+
+func (db filesDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db filesDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.Database.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+type remoteDB struct{ *remote.Database }
+
+func (db remoteDB) Begin() (Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+func (db remoteDB) BeginContext(ctx context.Context) (Tx, error) {
+	tx, err := db.Database.BeginContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,823 @@
+// Package conformance hold the scenarios every database backend must pass to be used by admiral.
+// The scenarios run against the fixtures data (fixtures/02_test_data.sql, fixtures/inventory for the files backend):
+//
+// hosts host1 (1.1.1.1), host2 (2.2.2.2) and host3 (3.3.3.3), each a direct member of the group with
+// the same number, and groups group1 to group5 where group3 is a child of group4 and group4 is a child
+// of group5
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// Open return a connection to a database holding only the fixtures data. It is called for every
+// scenario and should skip t when the backend is not available
+type Open func(t *testing.T) database.DBInterface
+
+type scenario struct {
+	name string
+	run  func(t *testing.T, db database.DBInterface)
+}
+
+var scenarios = []scenario{
+	{name: "hosts", run: hosts},
+	{name: "groups", run: groups},
+	{name: "scan", run: scan},
+	{name: "filter", run: filter},
+	{name: "iterate", run: iterate},
+	{name: "relationships", run: relationships},
+	{name: "inherited groups", run: inheritedGroups},
+	{name: "trash cascades", run: trashCascades},
+	{name: "addresses", run: addresses},
+	{name: "transactions", run: transactions},
+	{name: "revisions", run: revisions},
+	{name: "audit log", run: auditLog},
+}
+
+// Run run all the scenarios, each against a new database returned by open
+func Run(t *testing.T, open Open) {
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.name, func(t *testing.T) {
+			db := open(t)
+
+			defer db.Close()
+
+			s.run(t, db)
+		})
+	}
+}
+
+func hosts(t *testing.T, db database.DBInterface) {
+	host1, err := db.SelectHost("host1")
+	if err != nil {
+		t.Fatalf("SelectHost() error = %v", err)
+	}
+
+	if host1.Host != "1.1.1.1" || host1.Domain != "domain.local" || host1.Created == "" ||
+		!reflect.DeepEqual(host1.DirectGroups, datastructs.GroupNames{"group1"}) ||
+		!sameJSON(host1.Variables, `{"host_var1": {"host_sub_var1": "host_sub_val1"}}`) {
+		t.Errorf("SelectHost() = %+v, want host1 fixture", host1)
+	}
+
+	if got, err := db.SelectHost("host10"); err != nil || got.ID != 0 {
+		t.Errorf("SelectHost() = %+v, %v, want empty host without error for none-existing host", got, err)
+	}
+
+	if _, err = db.SelectHost(""); err == nil {
+		t.Errorf("SelectHost() error = nil, want error for empty hostname")
+	}
+
+	all, err := db.GetHosts()
+	if err != nil {
+		t.Fatalf("GetHosts() error = %v", err)
+	}
+
+	assertNames(t, "GetHosts()", hostnames(all), "host1", "host2", "host3")
+
+	host10 := datastructs.Host{Host: "10.10.10.10", Hostname: "host10", Domain: "domain.local", Variables: "{}",
+		Enabled: true, Monitored: true}
+	if affected, err := db.InsertHost(&host10); err != nil || affected != 1 {
+		t.Fatalf("InsertHost() = %v, %v, want 1 for new host", affected, err)
+	}
+
+	if got, _ := db.SelectHost("host10"); got.ID == 0 || got.Created == "" || got.Updated == "" {
+		t.Errorf("SelectHost() = %+v, want inserted host10 with timestamps", got)
+	}
+
+	host1.Enabled = false
+	if affected, err := db.InsertHost(&host1); err != nil || affected == 0 {
+		t.Fatalf("InsertHost() = %v, %v, want existing host updated", affected, err)
+	}
+
+	if got, _ := db.SelectHost("host1"); got.ID != host1.ID || got.Enabled {
+		t.Errorf("SelectHost() = %+v, want host1 updated in place", got)
+	}
+
+	host10, _ = db.SelectHost("host10")
+	if affected, err := db.DeleteHost(&host10); err != nil || affected != 1 {
+		t.Errorf("DeleteHost() = %v, %v, want 1", affected, err)
+	}
+
+	if affected, err := db.DeleteHost(&host10); err != nil || affected != 0 {
+		t.Errorf("DeleteHost() = %v, %v, want 0 for deleted host", affected, err)
+	}
+
+	if got, _ := db.SelectHost("host10"); got.ID != 0 {
+		t.Errorf("SelectHost() = %+v, want deleted host hidden", got)
+	}
+}
+
+func groups(t *testing.T, db database.DBInterface) {
+	group5, err := db.SelectGroup("group5")
+	if err != nil {
+		t.Fatalf("SelectGroup() error = %v", err)
+	}
+
+	if group5.NumChildren != 2 || !sameJSON(group5.Variables, `{"group_var5": "group_val5"}`) {
+		t.Errorf("SelectGroup() = %+v, want group5 fixture", group5)
+	}
+
+	assertNames(t, "SelectGroup() child groups", split(group5.ChildGroups), "group3", "group4")
+
+	if got, _ := db.SelectGroup("group1"); got.NumHosts != 1 {
+		t.Errorf("SelectGroup() = %+v, want group1 with 1 host", got)
+	}
+
+	if got, err := db.SelectGroup("group10"); err != nil || got.ID != 0 {
+		t.Errorf("SelectGroup() = %+v, %v, want empty group without error for none-existing group", got, err)
+	}
+
+	if _, err = db.SelectGroup(""); err == nil {
+		t.Errorf("SelectGroup() error = nil, want error for empty name")
+	}
+
+	all, err := db.GetGroups()
+	if err != nil {
+		t.Fatalf("GetGroups() error = %v", err)
+	}
+
+	assertNames(t, "GetGroups()", groupNames(all), "group1", "group2", "group3", "group4", "group5")
+
+	group10 := datastructs.Group{Name: "group10", Variables: "{}", Enabled: true, Monitored: true}
+	if affected, err := db.InsertGroup(&group10); err != nil || affected != 1 {
+		t.Fatalf("InsertGroup() = %v, %v, want 1 for new group", affected, err)
+	}
+
+	group5.Monitored = false
+	if affected, err := db.InsertGroup(&group5); err != nil || affected == 0 {
+		t.Fatalf("InsertGroup() = %v, %v, want existing group updated", affected, err)
+	}
+
+	if got, _ := db.SelectGroup("group5"); got.ID != group5.ID || got.Monitored {
+		t.Errorf("SelectGroup() = %+v, want group5 updated in place", got)
+	}
+
+	group10, _ = db.SelectGroup("group10")
+	if affected, err := db.DeleteGroup(&group10); err != nil || affected != 1 {
+		t.Errorf("DeleteGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if affected, err := db.DeleteGroup(&group10); err != nil || affected != 0 {
+		t.Errorf("DeleteGroup() = %v, %v, want 0 for deleted group", affected, err)
+	}
+}
+
+func scan(t *testing.T, db database.DBInterface) {
+	tests := []struct {
+		name string
+		scan func(val string) ([]string, error)
+		val  string
+		want []string
+	}{
+		{name: "ScanHosts() hostname", scan: scanHosts(db), val: "host", want: []string{"host1", "host2", "host3"}},
+		{name: "ScanHosts() ip", scan: scanHosts(db), val: "2.2", want: []string{"host2"}},
+		{name: "ScanHosts() none", scan: scanHosts(db), val: "host10"},
+		{name: "ScanGroups()", scan: scanGroups(db), val: "group", want: []string{"group1", "group2", "group3",
+			"group4", "group5"}},
+		{name: "ScanGroups() none", scan: scanGroups(db), val: "group10"},
+		{name: "ScanChildGroups() parent or child", scan: scanChildGroups(db), val: "group4",
+			want: []string{"group3>group4", "group4>group5"}},
+		{name: "ScanHostGroups() group", scan: scanHostGroups(db), val: "group1", want: []string{"host1>group1"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.scan(tt.val)
+		if err != nil {
+			t.Errorf("%v error = %v", tt.name, err)
+			continue
+		}
+
+		assertNames(t, tt.name, got, tt.want...)
+
+		if _, err = tt.scan(""); err == nil {
+			t.Errorf("%v error = nil, want error for empty value", tt.name)
+		}
+	}
+
+	// scanned groups hold the same relationships data as selected groups
+	scanned, _ := db.ScanGroups("group5")
+	if len(scanned) != 1 || scanned[0].ChildGroups == "" {
+		t.Errorf("ScanGroups() = %+v, want group5 with its child groups", scanned)
+	}
+}
+
+func filter(t *testing.T, db database.DBInterface) {
+	host2, _ := db.SelectHost("host2")
+	host2.Monitored, host2.Domain = false, "prod.local"
+	host2.Variables = `{"host_var2": "host_val2", "port": 22, "managed": true, "os": {"family": "Debian"}}`
+
+	if _, err := db.InsertHost(&host2); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "", want: []string{"host1", "host2", "host3"}},
+		{filter: "domain=prod.local and monitored=false", want: []string{"host2"}},
+		{filter: "domain=prod.local and monitored=true"},
+		{filter: "hostname=host1 or ip=3.3.3.3", want: []string{"host1", "host3"}},
+		{filter: "hostname=HOST1"},
+		{filter: "hostname^=HOST", want: []string{"host1", "host2", "host3"}},
+		// prefix, glob and regex matches ignore the case
+		{filter: "domain*=*PROD.Local", want: []string{"host2"}},
+		{filter: "hostname~=^HoSt[13]$", want: []string{"host1", "host3"}},
+		{filter: "group*=GROUP?", want: []string{"host1", "host2", "host3"}},
+		{filter: "var.os.family*=*BIAN or var.host_var3~=^HOST_VAL", want: []string{"host2", "host3"}},
+		{filter: "ip*=?.?.?.2", want: []string{"host2"}},
+		{filter: "domain*=*.local and hostname*=host[1]"},
+		{filter: "hostname~='^host[13]$'", want: []string{"host1", "host3"}},
+		{filter: "group=group3", want: []string{"host3"}},
+		{filter: "group^=group", want: []string{"host1", "host2", "host3"}},
+		{filter: "inherited_group=group5", want: []string{"host3"}},
+		{filter: "inherited_group=group3"},
+		{filter: "(group=group1 or inherited_group~=^group4$) and enabled=true", want: []string{"host1", "host3"}},
+		// LIKE wildcards in the value are matched literally
+		{filter: "hostname^=host_"},
+		{filter: "hostname*=host%"},
+		{filter: "var.host_var1.host_sub_var1=host_sub_val1", want: []string{"host1"}},
+		{filter: "var.port=22 and var.managed=true", want: []string{"host2"}},
+		{filter: "var.os.family^=Deb or var.host_var3~=val3$", want: []string{"host2", "host3"}},
+		{filter: "var.Port=22"},
+		{filter: "has_var=os.family", want: []string{"host2"}},
+		{filter: "has_var=host_var1 or has_var=host_var3", want: []string{"host1", "host3"}},
+		{filter: "has_var=host_var1.missing"},
+	}
+	for _, tt := range hosts {
+		f, err := datastructs.ParseFilter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := db.FilterHosts(f)
+		if err != nil {
+			t.Errorf("FilterHosts(%v) error = %v", tt.filter, err)
+			continue
+		}
+
+		assertNames(t, "FilterHosts("+tt.filter+")", hostnames(got), tt.want...)
+	}
+
+	// filtered hosts hold the same relationships data as selected hosts
+	if got, _ := db.FilterHosts(datastructs.Filter{Field: "hostname", Match: datastructs.MatchExact,
+		Value: "host3"}); len(got) != 1 || got[0].InheritedGroups != "group4,group5" || got[0].ID == 0 {
+		t.Errorf("FilterHosts() = %+v, want host3 with its inherited groups", got)
+	}
+
+	if got, err := db.FilterGroups(datastructs.Filter{Or: []datastructs.Filter{
+		{Field: "name", Match: datastructs.MatchExact, Value: "group5"},
+		{Field: "name", Match: datastructs.MatchGlob, Value: "*1"}}}); err != nil {
+		t.Errorf("FilterGroups() error = %v", err)
+	} else {
+		assertNames(t, "FilterGroups()", groupNames(got), "group1", "group5")
+	}
+
+	if got, err := db.FilterGroups(datastructs.Filter{Field: datastructs.HasVarField, Match: datastructs.MatchExact,
+		Value: "group_var1.group_sub_var1"}); err != nil {
+		t.Errorf("FilterGroups() error = %v", err)
+	} else {
+		assertNames(t, "FilterGroups() has_var", groupNames(got), "group1")
+	}
+
+	for _, f := range []datastructs.Filter{
+		{Field: "group", Match: datastructs.MatchExact, Value: "group1"},
+		{Field: "var.a..b", Match: datastructs.MatchExact, Value: "1"},
+		{Field: "enabled", Match: datastructs.MatchPrefix, Value: "t"},
+		{Field: "name", Match: datastructs.MatchRegex, Value: "("},
+	} {
+		if _, err := db.FilterGroups(f); err == nil {
+			t.Errorf("FilterGroups(%v) error = nil, want error for invalid filter", f)
+		}
+	}
+}
+
+func iterate(t *testing.T, db database.DBInterface) {
+	// host0 sort first by hostname while its id is the last
+	if _, err := db.InsertHost(&datastructs.Host{Host: "4.4.4.4", Hostname: "host0", Domain: "domain.local",
+		Variables: "{}", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	host1, _ := db.SelectHost("host1")
+	if _, err := db.DeleteHost(&host1); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []struct {
+		page datastructs.Page
+		want []string
+	}{
+		{want: []string{"host0", "host2", "host3"}},
+		{page: datastructs.Page{Limit: 2}, want: []string{"host0", "host2"}},
+		{page: datastructs.Page{Limit: 2, Offset: 2}, want: []string{"host3"}},
+		{page: datastructs.Page{Offset: 1}, want: []string{"host2", "host3"}},
+		{page: datastructs.Page{Offset: 3}},
+	}
+	for _, tt := range hosts {
+		var got []string
+
+		if err := db.IterateHosts(tt.page, func(host *datastructs.Host) error {
+			got = append(got, host.Hostname)
+			return nil
+		}); err != nil {
+			t.Errorf("IterateHosts(%+v) error = %v", tt.page, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IterateHosts(%+v) = %v, want %v", tt.page, got, tt.want)
+		}
+	}
+
+	// iterated hosts hold the same data as selected hosts
+	host3, _ := db.SelectHost("host3")
+	if err := db.IterateHosts(datastructs.Page{Offset: 2}, func(host *datastructs.Host) error {
+		if !reflect.DeepEqual(*host, host3) {
+			t.Errorf("IterateHosts() = %+v, want %+v", *host, host3)
+		}
+
+		return nil
+	}); err != nil {
+		t.Errorf("IterateHosts() error = %v", err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+
+	if err := db.IterateGroups(datastructs.Page{}, func(group *datastructs.Group) error {
+		calls++
+		return stop
+	}); !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("IterateGroups() error = %v after %v calls, want the fn error after 1 call", err, calls)
+	}
+
+	var got []string
+
+	if err := db.IterateGroups(datastructs.Page{Limit: 2, Offset: 3}, func(group *datastructs.Group) error {
+		got = append(got, group.Name)
+		return nil
+	}); err != nil || !reflect.DeepEqual(got, []string{"group4", "group5"}) {
+		t.Errorf("IterateGroups() = %v, %v, want group4 and group5", got, err)
+	}
+
+	if err := db.IterateHosts(datastructs.Page{Limit: -1}, func(*datastructs.Host) error { return nil }); err == nil {
+		t.Errorf("IterateHosts() error = nil, want error for negative limit")
+	}
+}
+
+func relationships(t *testing.T, db database.DBInterface) {
+	if got, err := db.SelectChildGroup("group3", "group4"); err != nil || len(got) != 1 || got[0].ParentID != 4 {
+		t.Errorf("SelectChildGroup() = %+v, %v, want group3 child of group4", got, err)
+	}
+
+	if got, err := db.SelectChildGroup("group3", "group10"); err != nil || len(got) != 0 {
+		t.Errorf("SelectChildGroup() = %+v, %v, want none without error", got, err)
+	}
+
+	if _, err := db.SelectChildGroup("", "group4"); err == nil {
+		t.Errorf("SelectChildGroup() error = nil, want error for missing child")
+	}
+
+	if got, err := db.SelectHostGroup("host1"); err != nil || len(got) != 1 || got[0].Group != "group1" {
+		t.Errorf("SelectHostGroup() = %+v, %v, want host1 member of group1", got, err)
+	}
+
+	if _, err := db.SelectHostGroup(""); err == nil {
+		t.Errorf("SelectHostGroup() error = nil, want error for missing host")
+	}
+
+	existing := datastructs.HostGroup{HostID: 1, GroupID: 1}
+	if affected, err := db.InsertHostGroup(&existing); err != nil || affected != 0 {
+		t.Errorf("InsertHostGroup() = %v, %v, want 0 for existing membership", affected, err)
+	}
+
+	second := datastructs.HostGroup{HostID: 1, GroupID: 2}
+	if affected, err := db.InsertHostGroup(&second); err != nil || affected != 1 {
+		t.Errorf("InsertHostGroup() = %v, %v, want 1 for additional group", affected, err)
+	}
+
+	if got, _ := db.SelectHost("host1"); !reflect.DeepEqual(got.DirectGroups, datastructs.GroupNames{"group1", "group2"}) {
+		t.Errorf("SelectHost() direct groups = %v, want group1,group2", got.DirectGroups)
+	}
+
+	if _, err := db.InsertHostGroup(&datastructs.HostGroup{HostID: 10, GroupID: 10}); err == nil {
+		t.Errorf("InsertHostGroup() error = nil, want error for none-existing host and group")
+	}
+
+	if affected, err := db.DeleteHostGroup(&second); err != nil || affected != 1 {
+		t.Errorf("DeleteHostGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if affected, err := db.DeleteHostGroup(&second); err != nil || affected != 0 {
+		t.Errorf("DeleteHostGroup() = %v, %v, want 0 for none-existing membership", affected, err)
+	}
+
+	child := datastructs.ChildGroup{ChildID: 2, ParentID: 1}
+	if affected, err := db.InsertChildGroup(&child); err != nil || affected != 1 {
+		t.Errorf("InsertChildGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if _, err := db.InsertChildGroup(&datastructs.ChildGroup{ChildID: 10, ParentID: 1}); err == nil {
+		t.Errorf("InsertChildGroup() error = nil, want error for none-existing child")
+	}
+
+	if affected, err := db.DeleteChildGroup(&child); err != nil || affected != 1 {
+		t.Errorf("DeleteChildGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if affected, err := db.DeleteChildGroup(&child); err != nil || affected != 0 {
+		t.Errorf("DeleteChildGroup() = %v, %v, want 0 for none-existing relationship", affected, err)
+	}
+}
+
+// inheritedGroups verify the recursive relationships admiral relies on for the inventory and for
+// detecting relationship loops
+func inheritedGroups(t *testing.T, db database.DBInterface) {
+	host3, _ := db.SelectHost("host3")
+	assertNames(t, "SelectHost() inherited groups", split(host3.InheritedGroups), "group4", "group5")
+
+	// group2 (host2) become a child of group3, and through it of group4 and group5
+	if _, err := db.InsertChildGroup(&datastructs.ChildGroup{ChildID: 2, ParentID: 3}); err != nil {
+		t.Fatalf("InsertChildGroup() error = %v", err)
+	}
+
+	host2, _ := db.SelectHost("host2")
+	assertNames(t, "SelectHost() inherited groups", split(host2.InheritedGroups), "group3", "group4", "group5")
+
+	group5, _ := db.SelectGroup("group5")
+	assertNames(t, "SelectGroup() child groups", split(group5.ChildGroups), "group2", "group3", "group4")
+
+	all, err := db.GetHosts()
+	if err != nil {
+		t.Fatalf("GetHosts() error = %v", err)
+	}
+
+	for i := range all {
+		if all[i].Hostname == "host2" && all[i].InheritedGroups != host2.InheritedGroups {
+			t.Errorf("GetHosts() host2 inherited groups = %v, want %v", all[i].InheritedGroups, host2.InheritedGroups)
+		}
+	}
+}
+
+func trashCascades(t *testing.T, db database.DBInterface) {
+	group4, _ := db.SelectGroup("group4")
+	if _, err := db.DeleteGroup(&group4); err != nil {
+		t.Fatalf("DeleteGroup() error = %v", err)
+	}
+
+	if got, _ := db.GetChildGroups(); len(got) != 0 {
+		t.Errorf("GetChildGroups() = %+v, want deleted group relationships hidden", got)
+	}
+
+	if got, _ := db.SelectHost("host3"); got.InheritedGroups != "" {
+		t.Errorf("SelectHost() inherited groups = %v, want none through deleted group", got.InheritedGroups)
+	}
+
+	if _, err := db.InsertGroup(&datastructs.Group{Name: "group4", Variables: "{}"}); err == nil {
+		t.Errorf("InsertGroup() error = nil, want error for group in the trash")
+	}
+
+	deleted, err := db.GetDeletedGroups()
+	if err != nil || len(deleted) != 1 || deleted[0].Name != "group4" || deleted[0].Deleted == "" {
+		t.Fatalf("GetDeletedGroups() = %+v, %v, want group4", deleted, err)
+	}
+
+	if affected, err := db.RestoreGroup(&deleted[0]); err != nil || affected != 1 {
+		t.Fatalf("RestoreGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ := db.GetChildGroups(); len(got) != 2 {
+		t.Errorf("GetChildGroups() = %+v, want restored relationships", got)
+	}
+
+	if _, err = db.DeleteGroup(&group4); err != nil {
+		t.Fatalf("DeleteGroup() error = %v", err)
+	}
+
+	if affected, err := db.PurgeGroup(&group4); err != nil || affected != 1 {
+		t.Fatalf("PurgeGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ := db.SelectGroup("group3"); got.ID == 0 {
+		t.Errorf("SelectGroup() = %+v, want child group3 kept", got)
+	}
+
+	host1, _ := db.SelectHost("host1")
+	if _, err = db.InsertAddress(&datastructs.Address{HostID: host1.ID, Name: "public", Address: "1.1.1.1"}); err != nil {
+		t.Fatalf("InsertAddress() error = %v", err)
+	}
+
+	if _, err = db.DeleteHost(&host1); err != nil {
+		t.Fatalf("DeleteHost() error = %v", err)
+	}
+
+	if got, _ := db.GetAddresses(); len(got) != 0 {
+		t.Errorf("GetAddresses() = %+v, want deleted host addresses hidden", got)
+	}
+
+	if got, _ := db.GetHostGroups(); len(got) != 2 {
+		t.Errorf("GetHostGroups() = %+v, want deleted host memberships hidden", got)
+	}
+
+	// the IP of the deleted host is still taken
+	taken := datastructs.Host{Host: host1.Host, Hostname: "host10", Variables: "{}"}
+	if _, err = db.InsertHost(&taken); err == nil || !strings.Contains(err.Error(), "is in the trash") {
+		t.Errorf("InsertHost() error = %v, want error for IP of a host in the trash", err)
+	}
+
+	if affected, err := db.PurgeHost(&host1); err != nil || affected != 1 {
+		t.Fatalf("PurgeHost() = %v, %v, want 1", affected, err)
+	}
+
+	// a host created with the purged host name does not inherit its relationships
+	host1.ID, host1.Revision = 0, 0
+	if _, err = db.InsertHost(&host1); err != nil {
+		t.Fatalf("InsertHost() error = %v", err)
+	}
+
+	if got, _ := db.SelectHost("host1"); len(got.DirectGroups) != 0 {
+		t.Errorf("SelectHost() direct groups = %v, want none for recreated host", got.DirectGroups)
+	}
+
+	if got, _ := db.SelectAddresses("host1"); len(got) != 0 {
+		t.Errorf("SelectAddresses() = %+v, want none for recreated host", got)
+	}
+}
+
+func addresses(t *testing.T, db database.DBInterface) {
+	public := datastructs.Address{HostID: 1, Name: "public", Address: "1.1.1.1", Primary: true}
+	management := datastructs.Address{HostID: 1, Name: "management", Address: "10.0.0.1"}
+
+	for _, address := range []*datastructs.Address{&public, &management} {
+		if affected, err := db.InsertAddress(address); err != nil || affected == 0 {
+			t.Fatalf("InsertAddress() = %v, %v, want inserted", affected, err)
+		}
+	}
+
+	got, err := db.SelectAddresses("host1")
+	if err != nil || len(got) != 2 || got[0].Name != "management" || got[0].Host != "host1" || !got[1].Primary {
+		t.Fatalf("SelectAddresses() = %+v, %v, want management and primary public", got, err)
+	}
+
+	management.Address = "10.0.0.2"
+	if _, err = db.InsertAddress(&management); err != nil {
+		t.Fatalf("InsertAddress() error = %v", err)
+	}
+
+	if got, _ = db.GetAddresses(); len(got) != 2 || got[0].Address != "10.0.0.2" {
+		t.Errorf("GetAddresses() = %+v, want management address updated in place", got)
+	}
+
+	if affected, err := db.DeleteAddress(&public); err != nil || affected != 1 {
+		t.Errorf("DeleteAddress() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ = db.SelectAddresses("host1"); len(got) != 1 {
+		t.Errorf("SelectAddresses() = %+v, want only management", got)
+	}
+}
+
+func transactions(t *testing.T, db database.DBInterface) {
+	group := datastructs.Group{Name: "group10", Variables: "{}"}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	if _, err = tx.InsertGroup(&group); err != nil {
+		t.Fatalf("InsertGroup() error = %v", err)
+	}
+
+	if got, _ := tx.SelectGroup("group10"); got.ID == 0 {
+		t.Errorf("SelectGroup() = %+v, want group visible in its transaction", got)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if got, _ := db.SelectGroup("group10"); got.ID != 0 {
+		t.Errorf("SelectGroup() = %+v, want rolled back group missing", got)
+	}
+
+	if tx, err = db.Begin(); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	if _, err = tx.InsertGroup(&group); err != nil {
+		t.Fatalf("InsertGroup() error = %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if got, _ := db.SelectGroup("group10"); got.ID == 0 {
+		t.Errorf("SelectGroup() = %+v, want committed group", got)
+	}
+}
+
+func revisions(t *testing.T, db database.DBInterface) {
+	host1, _ := db.SelectHost("host1")
+	if host1.Revision != 1 {
+		t.Fatalf("SelectHost() revision = %v, want 1 for fixture host", host1.Revision)
+	}
+
+	// writing the host unchanged keep its revision
+	if _, err := db.InsertHost(&host1); err != nil {
+		t.Fatalf("InsertHost() error = %v", err)
+	}
+
+	stale := host1
+	host1.Enabled = false
+
+	if _, err := db.InsertHost(&host1); err != nil {
+		t.Fatalf("InsertHost() error = %v", err)
+	}
+
+	if got, _ := db.SelectHost("host1"); got.Revision != 2 || got.Enabled {
+		t.Errorf("SelectHost() = %+v, want disabled host1 at revision 2", got)
+	}
+
+	stale.Domain = "domain.com"
+	if _, err := db.InsertHost(&stale); !errors.Is(err, datastructs.ErrRevisionConflict) {
+		t.Errorf("InsertHost() error = %v, want ErrRevisionConflict for stale host", err)
+	}
+
+	stale.Revision = 0
+	if _, err := db.InsertHost(&stale); err != nil {
+		t.Errorf("InsertHost() error = %v, want unchecked write for revision 0", err)
+	}
+
+	if _, err := db.InsertHost(&datastructs.Host{Hostname: "host10", Host: "10.10.10.10", Variables: "{}",
+		Revision: 1}); !errors.Is(err, datastructs.ErrRevisionConflict) {
+		t.Errorf("InsertHost() error = %v, want ErrRevisionConflict for none-existing host", err)
+	}
+
+	group1, _ := db.SelectGroup("group1")
+	staleGroup := group1
+	group1.Monitored = false
+
+	if _, err := db.InsertGroup(&group1); err != nil {
+		t.Fatalf("InsertGroup() error = %v", err)
+	}
+
+	if got, _ := db.SelectGroup("group1"); got.Revision != 2 {
+		t.Errorf("SelectGroup() revision = %v, want 2", got.Revision)
+	}
+
+	if _, err := db.InsertGroup(&staleGroup); !errors.Is(err, datastructs.ErrRevisionConflict) {
+		t.Errorf("InsertGroup() error = %v, want ErrRevisionConflict for stale group", err)
+	}
+
+	// the groups and addresses of a host are part of it, changing them increase its revision
+	host2, _ := db.SelectHost("host2")
+	changes := []struct {
+		name   string
+		change func() (int64, error)
+	}{
+		{"InsertHostGroup", func() (int64, error) {
+			return db.InsertHostGroup(&datastructs.HostGroup{HostID: host2.ID, GroupID: group1.ID})
+		}},
+		{"DeleteHostGroup", func() (int64, error) {
+			return db.DeleteHostGroup(&datastructs.HostGroup{HostID: host2.ID, GroupID: group1.ID})
+		}},
+		{"InsertAddress", func() (int64, error) {
+			return db.InsertAddress(&datastructs.Address{HostID: host2.ID, Name: "backup", Address: "2.2.2.3"})
+		}},
+		{"DeleteAddress", func() (int64, error) {
+			return db.DeleteAddress(&datastructs.Address{HostID: host2.ID, Name: "backup"})
+		}},
+	}
+
+	for _, c := range changes {
+		if _, err := c.change(); err != nil {
+			t.Fatalf("%v() error = %v", c.name, err)
+		}
+
+		got, _ := db.SelectHost("host2")
+		if got.Revision != host2.Revision+1 {
+			t.Errorf("%v() host revision = %v, want %v", c.name, got.Revision, host2.Revision+1)
+		}
+
+		if _, err := db.InsertHost(&host2); !errors.Is(err, datastructs.ErrRevisionConflict) {
+			t.Errorf("InsertHost() error = %v, want ErrRevisionConflict after %v()", err, c.name)
+		}
+
+		host2 = got
+	}
+}
+
+func auditLog(t *testing.T, db database.DBInterface) {
+	group := datastructs.Group{Name: "group10", Variables: "{}"}
+	if _, err := db.InsertGroup(&group); err != nil {
+		t.Fatalf("InsertGroup() error = %v", err)
+	}
+
+	changes, err := db.SelectChanges(datastructs.EntityGroup, "group10")
+	if err != nil {
+		t.Fatalf("SelectChanges() error = %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].Action != datastructs.ActionInsert || changes[0].After == "" {
+		t.Fatalf("SelectChanges() = %+v, want group10 insert", changes)
+	}
+
+	if got, err := db.SelectChange(changes[0].ID); err != nil || got.Name != "group10" {
+		t.Errorf("SelectChange() = %+v, %v, want group10 insert", got, err)
+	}
+}
+
+func scanHosts(db database.DBInterface) func(val string) ([]string, error) {
+	return func(val string) ([]string, error) {
+		hosts, err := db.ScanHosts(val)
+		return hostnames(hosts), err
+	}
+}
+
+func scanGroups(db database.DBInterface) func(val string) ([]string, error) {
+	return func(val string) ([]string, error) {
+		groups, err := db.ScanGroups(val)
+		return groupNames(groups), err
+	}
+}
+
+func scanChildGroups(db database.DBInterface) func(val string) ([]string, error) {
+	return func(val string) (names []string, err error) {
+		childGroups, err := db.ScanChildGroups(val)
+		for _, cg := range childGroups {
+			names = append(names, cg.Child+">"+cg.Parent)
+		}
+
+		return names, err
+	}
+}
+
+func scanHostGroups(db database.DBInterface) func(val string) ([]string, error) {
+	return func(val string) (names []string, err error) {
+		hostGroups, err := db.ScanHostGroups(val)
+		for _, hg := range hostGroups {
+			names = append(names, hg.Host+">"+hg.Group)
+		}
+
+		return names, err
+	}
+}
+
+func hostnames(hosts []datastructs.Host) (names []string) {
+	for i := range hosts {
+		names = append(names, hosts[i].Hostname)
+	}
+
+	return names
+}
+
+func groupNames(groups []datastructs.Group) (names []string) {
+	for i := range groups {
+		names = append(names, groups[i].Name)
+	}
+
+	return names
+}
+
+func split(val string) []string {
+	if val == "" {
+		return nil
+	}
+
+	return strings.Split(val, ",")
+}
+
+// assertNames compare the names regardless of the order the backend returned them in
+func assertNames(t *testing.T, name string, got []string, want ...string) {
+	t.Helper()
+
+	sorted := append([]string{}, got...)
+	sort.Strings(sorted)
+	sort.Strings(want)
+
+	if len(sorted) != len(want) || (len(want) > 0 && !reflect.DeepEqual(sorted, want)) {
+		t.Errorf("%v = %v, want %v", name, got, want)
+	}
+}
+
+// sameJSON compare json values regardless of their formatting, backends may store the variables reformatted
+func sameJSON(got, want string) bool {
+	var g, w interface{}
+
+	if json.Unmarshal([]byte(got), &g) != nil || json.Unmarshal([]byte(want), &w) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(g, w)
+}
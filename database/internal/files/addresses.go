@@ -0,0 +1,107 @@
+// nolint: golint
+package files
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// SelectAddresses accept hostname and return the host addresses ordered by name
+func (db *Database) SelectAddresses(host string) (addresses datastructs.Addresses, err error) {
+	return db.SelectAddressesContext(context.Background(), host)
+}
+
+// SelectAddressesContext is SelectAddresses with a context bounding the query
+func (db *Database) SelectAddressesContext(ctx context.Context, host string) (
+	addresses datastructs.Addresses, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return addresses, err
+	}
+
+	return newView(s).addressRows(func(a *datastructs.Address) bool { return a.Host == host }), nil
+}
+
+// GetAddresses return the addresses of all hosts ordered by hostname and name
+func (db *Database) GetAddresses() (addresses datastructs.Addresses, err error) {
+	return db.GetAddressesContext(context.Background())
+}
+
+// GetAddressesContext is GetAddresses with a context bounding the query
+func (db *Database) GetAddressesContext(ctx context.Context) (addresses datastructs.Addresses, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return addresses, err
+	}
+
+	return newView(s).addressRows(func(*datastructs.Address) bool { return true }), nil
+}
+
+// InsertAddress accept Address to insert or update by host ID and name and return the number of
+// affected rows and error if exists
+func (db *Database) InsertAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.InsertAddressContext(context.Background(), address)
+}
+
+// InsertAddressContext is InsertAddress with a context bounding the query
+func (db *Database) InsertAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		h := s.hostIndex(address.HostID)
+		if h < 0 {
+			return 0, fmt.Errorf("host %v does not exist", address.HostID)
+		}
+
+		for i := range s.addresses {
+			a := &s.addresses[i]
+			if a.HostID == address.HostID && a.Name == address.Name {
+				if a.Address != address.Address || a.Primary != address.Primary {
+					a.Address, a.Primary = address.Address, address.Primary
+					s.hosts[h].Revision++
+				}
+
+				return 1, nil
+			}
+		}
+
+		s.lastAddress++
+		s.addresses = append(s.addresses, datastructs.Address{ID: s.lastAddress, HostID: address.HostID,
+			Name: address.Name, Address: address.Address, Primary: address.Primary})
+		s.hosts[h].Revision++
+
+		return 1, nil
+	})
+}
+
+// DeleteAddress accept Address to delete by host ID and name and return the number of affected
+// rows and error if exists
+func (db *Database) DeleteAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.DeleteAddressContext(context.Background(), address)
+}
+
+// DeleteAddressContext is DeleteAddress with a context bounding the query
+func (db *Database) DeleteAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (affected int64, err error) {
+		kept := s.addresses[:0]
+
+		for _, a := range s.addresses {
+			if a.HostID == address.HostID && a.Name == address.Name {
+				affected++
+				continue
+			}
+
+			kept = append(kept, a)
+		}
+
+		s.addresses = kept
+
+		if h := s.hostIndex(address.HostID); affected > 0 && h >= 0 {
+			s.hosts[h].Revision++
+		}
+
+		return affected, nil
+	})
+}
@@ -0,0 +1,91 @@
+// nolint: golint
+package files
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// InsertChange accept Change to record in the audit log and return the ID of the recorded change
+func (db *Database) InsertChange(change *datastructs.Change) (id int64, err error) {
+	return db.InsertChangeContext(context.Background(), change)
+}
+
+// InsertChangeContext is InsertChange with a context bounding the query
+func (db *Database) InsertChangeContext(ctx context.Context, change *datastructs.Change) (id int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		s.lastChange++
+
+		c := *change
+		c.ID = s.lastChange
+		s.changes = append(s.changes, c)
+
+		return c.ID, nil
+	})
+}
+
+// SelectChanges return the audit log of a host or group ordered from the oldest change. Host changes
+// include its group membership and address changes and group changes include its child-group and host-group
+// relationship changes. If entity is empty the whole audit log is returned
+func (db *Database) SelectChanges(entity, name string) (changes []datastructs.Change, err error) {
+	return db.SelectChangesContext(context.Background(), entity, name)
+}
+
+// SelectChangesContext is SelectChanges with a context bounding the query
+func (db *Database) SelectChangesContext(ctx context.Context, entity, name string) (
+	changes []datastructs.Change, err error) {
+	var match func(c *datastructs.Change) bool
+
+	switch entity {
+	case "":
+		match = func(*datastructs.Change) bool { return true }
+	case datastructs.EntityHost:
+		match = func(c *datastructs.Change) bool {
+			return c.Name == name && (c.Entity == datastructs.EntityHost || c.Entity == datastructs.EntityHostGroup ||
+				c.Entity == datastructs.EntityAddress)
+		}
+	case datastructs.EntityGroup:
+		match = func(c *datastructs.Change) bool {
+			return (c.Name == name && (c.Entity == datastructs.EntityGroup || c.Entity == datastructs.EntityChildGroup)) ||
+				(c.Related == name && (c.Entity == datastructs.EntityChildGroup || c.Entity == datastructs.EntityHostGroup))
+		}
+	default:
+		return changes, fmt.Errorf("unknown entity %v", entity)
+	}
+
+	return db.changes(ctx, match)
+}
+
+// SelectChange return a single change by its ID
+func (db *Database) SelectChange(id int64) (change datastructs.Change, err error) {
+	return db.SelectChangeContext(context.Background(), id)
+}
+
+// SelectChangeContext is SelectChange with a context bounding the query
+func (db *Database) SelectChangeContext(ctx context.Context, id int64) (change datastructs.Change, err error) {
+	changes, err := db.changes(ctx, func(c *datastructs.Change) bool { return c.ID == id })
+	if err != nil || len(changes) == 0 {
+		return change, err
+	}
+
+	return changes[0], nil
+}
+
+// changes return the changes matching the filter ordered by ID
+func (db *Database) changes(ctx context.Context, match func(c *datastructs.Change) bool) (
+	changes []datastructs.Change, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return changes, err
+	}
+
+	for i := range s.changes {
+		if match(&s.changes[i]) {
+			changes = append(changes, s.changes[i])
+		}
+	}
+
+	return changes, nil
+}
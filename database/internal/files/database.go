@@ -0,0 +1,705 @@
+// Package files store the inventory as yaml files in a directory, one file per host and group, so it can
+// be kept in git and reviewed like any other configuration
+package files
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// Database exposes an inventory directory
+type Database struct {
+	store *store
+	// tx is set on a Database returned by Begin, all changes are made to it until committed
+	tx *state
+	// base is the store version the transaction started from
+	base int
+	// ctx is the context the transaction was started with
+	ctx  context.Context
+	done bool
+}
+
+// Connect returns a Database reading and writing the inventory directory
+func Connect(conf config.FilesConfig) (*Database, error) {
+	return ConnectContext(context.Background(), conf)
+}
+
+// ConnectContext is Connect with a context bounding the loading of the directory
+func ConnectContext(ctx context.Context, conf config.FilesConfig) (*Database, error) {
+	db := Database{store: &store{dir: conf.Path, audit: conf.Audit}}
+
+	if err := ctx.Err(); err != nil {
+		return &db, err
+	}
+
+	if db.store.audit == "" {
+		dir, err := filepath.Abs(conf.Path)
+		if err != nil {
+			return &db, err
+		}
+
+		db.store.audit = dir + defaultAuditSuffix
+	}
+
+	if err := db.store.load(); err != nil {
+		return &db, err
+	}
+
+	return &db, nil
+}
+
+// load (re)read the inventory directory, creating it when missing
+func (st *store) load() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return st.reload()
+}
+
+// reload is load with st.mu held
+func (st *store) reload() error {
+	for _, dir := range []string{filepath.Join(st.dir, hostsDir), filepath.Join(st.dir, groupsDir),
+		filepath.Dir(st.audit)} {
+		if err := os.MkdirAll(dir, 0755); err != nil { // nolint: gosec
+			return err
+		}
+	}
+
+	s, disk, err := load(st.dir, st.audit, st.state)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	st.state = s
+	st.files = files
+	st.disk = disk
+	st.version++
+
+	return nil
+}
+
+// Close release the inventory directory, changes are written as they are made so there is nothing to flush
+func (db *Database) Close() (err error) {
+	return nil
+}
+
+// timestamp return the current time in the format stored in the created and updated fields
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// fileName return error if name cannot be used as the name of the host or group file
+func fileName(entity, name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%v name %q cannot be used as a file name", entity, name)
+	}
+
+	return nil
+}
+
+// checkRevision return ErrRevisionConflict if a write read at revision is applied to a record stored at another
+// revision, a revision of 0 skip the check. A record that does not exist is at revision 0
+func checkRevision(entity, name string, revision, stored int64) error {
+	if revision != 0 && revision != stored {
+		return fmt.Errorf("%v %v %w (read at revision %v, stored at revision %v)", entity, name,
+			datastructs.ErrRevisionConflict, revision, stored)
+	}
+
+	return nil
+}
+
+// Hosts
+
+// SelectHost return host information by hostname
+func (db *Database) SelectHost(hostname string) (returnedHost datastructs.Host, err error) {
+	return db.SelectHostContext(context.Background(), hostname)
+}
+
+// SelectHostContext is SelectHost with a context bounding the query
+func (db *Database) SelectHostContext(ctx context.Context, hostname string) (returnedHost datastructs.Host, err error) {
+	if len(hostname) == 0 {
+		return returnedHost, fmt.Errorf("please provide either hostname")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return returnedHost, err
+	}
+
+	hosts := newView(s).hostRows(func(h *datastructs.Host) bool { return h.Hostname == hostname })
+	if len(hosts) == 0 {
+		return returnedHost, nil
+	}
+
+	return hosts[0], nil
+}
+
+// GetHosts return all hosts in the inventory
+func (db *Database) GetHosts() (hosts []datastructs.Host, err error) {
+	return db.GetHostsContext(context.Background())
+}
+
+// GetHostsContext is GetHosts with a context bounding the query
+func (db *Database) GetHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return hosts, err
+	}
+
+	return newView(s).hostRows(func(*datastructs.Host) bool { return true }), nil
+}
+
+// InsertHost accept Host to insert or update and return the number of affected rows and error if exists
+func (db *Database) InsertHost(host *datastructs.Host) (affected int64, err error) {
+	return db.InsertHostContext(context.Background(), host)
+}
+
+// InsertHostContext is InsertHost with a context bounding the query
+func (db *Database) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	if err = fileName("host", host.Hostname); err != nil {
+		return 0, err
+	}
+
+	return db.write(ctx, func(s *state) (int64, error) {
+		existing := -1
+
+		for i, h := range s.hosts {
+			switch {
+			case h.Hostname == host.Hostname && h.Deleted != "":
+				return 0, fmt.Errorf("host %v is in the trash, restore or purge it first", host.Hostname)
+			case h.Hostname == host.Hostname:
+				existing = i
+			case h.Host == host.Host && h.Deleted != "":
+				return 0, fmt.Errorf("host %v is in the trash, restore or purge it first", host.Host)
+			case h.Host == host.Host:
+				return 0, fmt.Errorf("ip %v is already used by host %v", host.Host, h.Hostname)
+			}
+		}
+
+		now := timestamp()
+
+		if existing < 0 {
+			if err := checkRevision("host", host.Hostname, host.Revision, 0); err != nil {
+				return 0, err
+			}
+
+			s.lastHost++
+			s.hosts = append(s.hosts, datastructs.Host{ID: s.lastHost, Host: host.Host, Hostname: host.Hostname,
+				Domain: host.Domain, Variables: host.Variables, Enabled: host.Enabled, Monitored: host.Monitored,
+				Created: now, Updated: now, Revision: 1})
+
+			return 1, nil
+		}
+
+		h := &s.hosts[existing]
+
+		if err := checkRevision("host", host.Hostname, host.Revision, h.Revision); err != nil {
+			return 0, err
+		}
+
+		// updated and revision are kept when the host did not change
+		if h.Host != host.Host || h.Domain != host.Domain || h.Variables != host.Variables ||
+			h.Enabled != host.Enabled || h.Monitored != host.Monitored {
+			h.Updated = now
+			h.Revision++
+		}
+
+		h.Host, h.Domain, h.Variables, h.Enabled, h.Monitored = host.Host, host.Domain, host.Variables,
+			host.Enabled, host.Monitored
+
+		return 1, nil
+	})
+}
+
+// DeleteHost accept Host to move to the trash and return the number of affected rows and error if exists
+func (db *Database) DeleteHost(host *datastructs.Host) (affected int64, err error) {
+	return db.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext is DeleteHost with a context bounding the query
+func (db *Database) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		for i := range s.hosts {
+			if s.hosts[i].ID == host.ID && s.hosts[i].Deleted == "" {
+				s.hosts[i].Deleted = timestamp()
+				return 1, nil
+			}
+		}
+
+		return 0, nil
+	})
+}
+
+// ScanHosts get hosts where hostname or IP is like requested string
+func (db *Database) ScanHosts(val string) (hosts []datastructs.Host, err error) {
+	return db.ScanHostsContext(context.Background(), val)
+}
+
+// ScanHostsContext is ScanHosts with a context bounding the query
+func (db *Database) ScanHostsContext(ctx context.Context, val string) (hosts []datastructs.Host, err error) {
+	if val == "" {
+		return hosts, fmt.Errorf("no search value passed")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return hosts, err
+	}
+
+	return newView(s).hostRows(func(h *datastructs.Host) bool { return like(h.Hostname, val) || like(h.Host, val) }),
+		nil
+}
+
+// Groups
+
+// SelectGroup return group information by name
+func (db *Database) SelectGroup(name string) (returnedGroup datastructs.Group, err error) {
+	return db.SelectGroupContext(context.Background(), name)
+}
+
+// SelectGroupContext is SelectGroup with a context bounding the query
+func (db *Database) SelectGroupContext(ctx context.Context, name string) (returnedGroup datastructs.Group, err error) {
+	if len(name) == 0 {
+		return returnedGroup, fmt.Errorf("please provide group name")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return returnedGroup, err
+	}
+
+	groups := newView(s).groupRows(func(g *datastructs.Group) bool { return g.Name == name })
+	if len(groups) == 0 {
+		return returnedGroup, nil
+	}
+
+	return groups[0], nil
+}
+
+// GetGroups return all groups in the inventory
+func (db *Database) GetGroups() (groups []datastructs.Group, err error) {
+	return db.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is GetGroups with a context bounding the query
+func (db *Database) GetGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	return newView(s).groupRows(func(*datastructs.Group) bool { return true }), nil
+}
+
+// InsertGroup accept Group to insert or update and return the number of affected rows and error if exists
+func (db *Database) InsertGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.InsertGroupContext(context.Background(), group)
+}
+
+// InsertGroupContext is InsertGroup with a context bounding the query
+func (db *Database) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	if err = fileName("group", group.Name); err != nil {
+		return 0, err
+	}
+
+	return db.write(ctx, func(s *state) (int64, error) {
+		now := timestamp()
+
+		for i := range s.groups {
+			g := &s.groups[i]
+			if g.Name != group.Name {
+				continue
+			}
+
+			if g.Deleted != "" {
+				return 0, fmt.Errorf("group %v is in the trash, restore or purge it first", group.Name)
+			}
+
+			if err := checkRevision("group", group.Name, group.Revision, g.Revision); err != nil {
+				return 0, err
+			}
+
+			// updated and revision are kept when the group did not change
+			if g.Variables != group.Variables || g.Enabled != group.Enabled || g.Monitored != group.Monitored {
+				g.Updated = now
+				g.Revision++
+			}
+
+			g.Variables, g.Enabled, g.Monitored = group.Variables, group.Enabled, group.Monitored
+
+			return 1, nil
+		}
+
+		if err := checkRevision("group", group.Name, group.Revision, 0); err != nil {
+			return 0, err
+		}
+
+		s.lastGroup++
+		s.groups = append(s.groups, datastructs.Group{ID: s.lastGroup, Name: group.Name, Variables: group.Variables,
+			Enabled: group.Enabled, Monitored: group.Monitored, Created: now, Updated: now, Revision: 1})
+
+		return 1, nil
+	})
+}
+
+// DeleteGroup accept Group to move to the trash and return the number of affected rows and error if exists
+func (db *Database) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.DeleteGroupContext(context.Background(), group)
+}
+
+// DeleteGroupContext is DeleteGroup with a context bounding the query
+func (db *Database) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		for i := range s.groups {
+			if s.groups[i].ID == group.ID && s.groups[i].Deleted == "" {
+				s.groups[i].Deleted = timestamp()
+				return 1, nil
+			}
+		}
+
+		return 0, nil
+	})
+}
+
+// ScanGroups get group where group name in like requested string
+func (db *Database) ScanGroups(val string) (groups []datastructs.Group, err error) {
+	return db.ScanGroupsContext(context.Background(), val)
+}
+
+// ScanGroupsContext is ScanGroups with a context bounding the query
+func (db *Database) ScanGroupsContext(ctx context.Context, val string) (groups []datastructs.Group, err error) {
+	if val == "" {
+		return groups, fmt.Errorf("no search value passed")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	return newView(s).groupRows(func(g *datastructs.Group) bool { return like(g.Name, val) }), nil
+}
+
+// ChildGroups
+
+// SelectChildGroup accept child and parent group names and return their relationship
+func (db *Database) SelectChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error) {
+	return db.SelectChildGroupContext(context.Background(), child, parent)
+}
+
+// SelectChildGroupContext is SelectChildGroup with a context bounding the query
+func (db *Database) SelectChildGroupContext(ctx context.Context, child, parent string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	if child == "" || parent == "" {
+		return childGroups, fmt.Errorf("please provide child and parent group names")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return childGroups, err
+	}
+
+	return newView(s).childGroupRows(func(cg *datastructs.ChildGroup) bool {
+		return cg.Child == child && cg.Parent == parent
+	}), nil
+}
+
+// GetChildGroups return all child groups relationships in the inventory
+func (db *Database) GetChildGroups() (childGroups []datastructs.ChildGroup, err error) {
+	return db.GetChildGroupsContext(context.Background())
+}
+
+// GetChildGroupsContext is GetChildGroups with a context bounding the query
+func (db *Database) GetChildGroupsContext(ctx context.Context) (childGroups []datastructs.ChildGroup, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return childGroups, err
+	}
+
+	return newView(s).childGroupRows(func(*datastructs.ChildGroup) bool { return true }), nil
+}
+
+// InsertChildGroup accept ChildGroup to insert and return the number of affected rows and error if exists
+func (db *Database) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+// InsertChildGroupContext is InsertChildGroup with a context bounding the query
+func (db *Database) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		if err := s.groupsExist(childGroup.ChildID, childGroup.ParentID); err != nil {
+			return 0, err
+		}
+
+		for _, cg := range s.childGroups {
+			if cg.ChildID == childGroup.ChildID && cg.ParentID == childGroup.ParentID {
+				return 0, fmt.Errorf("group %v is already a child of group %v", childGroup.ChildID, childGroup.ParentID)
+			}
+		}
+
+		s.lastChildGroup++
+		s.childGroups = append(s.childGroups, datastructs.ChildGroup{ID: s.lastChildGroup,
+			ChildID: childGroup.ChildID, ParentID: childGroup.ParentID})
+
+		return 1, nil
+	})
+}
+
+// DeleteChildGroup accept ChildGroup to delete and return the number of affected rows and error if exists
+func (db *Database) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+// DeleteChildGroupContext is DeleteChildGroup with a context bounding the query
+func (db *Database) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (affected int64, err error) {
+		kept := s.childGroups[:0]
+
+		for _, cg := range s.childGroups {
+			if cg.ChildID == childGroup.ChildID && cg.ParentID == childGroup.ParentID {
+				affected++
+				continue
+			}
+
+			kept = append(kept, cg)
+		}
+
+		s.childGroups = kept
+
+		return affected, nil
+	})
+}
+
+// ScanChildGroups get child-group relationships where parent or child is like requested string
+func (db *Database) ScanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error) {
+	return db.ScanChildGroupsContext(context.Background(), val)
+}
+
+// ScanChildGroupsContext is ScanChildGroups with a context bounding the query
+func (db *Database) ScanChildGroupsContext(ctx context.Context, val string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	if val == "" {
+		return childGroups, fmt.Errorf("no search value passed")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return childGroups, err
+	}
+
+	return newView(s).childGroupRows(func(cg *datastructs.ChildGroup) bool {
+		return like(cg.Parent, val) || like(cg.Child, val)
+	}), nil
+}
+
+// HostGroups
+
+// SelectHostGroup accept hostname and return slice of HostGroup for the host.
+func (db *Database) SelectHostGroup(host string) (hostGroups []datastructs.HostGroup, err error) {
+	return db.SelectHostGroupContext(context.Background(), host)
+}
+
+// SelectHostGroupContext is SelectHostGroup with a context bounding the query
+func (db *Database) SelectHostGroupContext(ctx context.Context, host string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	if host == "" {
+		return hostGroups, fmt.Errorf("please provide either host or group id")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return hostGroups, err
+	}
+
+	return newView(s).hostGroupRows(func(hg *datastructs.HostGroup) bool { return hg.Host == host }), nil
+}
+
+// GetHostGroups return all host groups relationships in the inventory
+func (db *Database) GetHostGroups() (hostGroups []datastructs.HostGroup, err error) {
+	return db.GetHostGroupsContext(context.Background())
+}
+
+// GetHostGroupsContext is GetHostGroups with a context bounding the query
+func (db *Database) GetHostGroupsContext(ctx context.Context) (hostGroups []datastructs.HostGroup, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return hostGroups, err
+	}
+
+	return newView(s).hostGroupRows(func(*datastructs.HostGroup) bool { return true }), nil
+}
+
+// InsertHostGroup accept HostGroup to add the host to the group, keeping its other group memberships,
+// and return the number of affected rows and error if exists
+func (db *Database) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+// InsertHostGroupContext is InsertHostGroup with a context bounding the query
+func (db *Database) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		h := s.hostIndex(hostGroup.HostID)
+		if h < 0 {
+			return 0, fmt.Errorf("host %v does not exist", hostGroup.HostID)
+		}
+
+		if err := s.groupsExist(hostGroup.GroupID); err != nil {
+			return 0, err
+		}
+
+		for _, hg := range s.hostGroups {
+			if hg.HostID == hostGroup.HostID && hg.GroupID == hostGroup.GroupID {
+				return 0, nil
+			}
+		}
+
+		s.lastHostGroup++
+		s.hostGroups = append(s.hostGroups, datastructs.HostGroup{ID: s.lastHostGroup, HostID: hostGroup.HostID,
+			GroupID: hostGroup.GroupID})
+		s.hosts[h].Revision++
+
+		return 1, nil
+	})
+}
+
+// DeleteHostGroup accept HostGroup to delete and return the number of affected rows and error if exists
+func (db *Database) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+// DeleteHostGroupContext is DeleteHostGroup with a context bounding the query
+func (db *Database) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	return db.write(ctx, func(s *state) (affected int64, err error) {
+		kept := s.hostGroups[:0]
+
+		for _, hg := range s.hostGroups {
+			if hg.HostID == hostGroup.HostID && hg.GroupID == hostGroup.GroupID {
+				affected++
+				continue
+			}
+
+			kept = append(kept, hg)
+		}
+
+		s.hostGroups = kept
+
+		if h := s.hostIndex(hostGroup.HostID); affected > 0 && h >= 0 {
+			s.hosts[h].Revision++
+		}
+
+		return affected, nil
+	})
+}
+
+// ScanHostGroups get host-groups where group is like requested string
+func (db *Database) ScanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error) {
+	return db.ScanHostGroupsContext(context.Background(), val)
+}
+
+// ScanHostGroupsContext is ScanHostGroups with a context bounding the query
+func (db *Database) ScanHostGroupsContext(ctx context.Context, val string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	if val == "" {
+		return hostGroups, fmt.Errorf("no search value passed")
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return hostGroups, err
+	}
+
+	return newView(s).hostGroupRows(func(hg *datastructs.HostGroup) bool { return like(hg.Group, val) }), nil
+}
+
+// hostIndex return the index of the host with the given ID in s.hosts or -1 if there is none
+func (s *state) hostIndex(id int) int {
+	for i := range s.hosts {
+		if s.hosts[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// groupIndex return the index of the group with the given ID in s.groups or -1 if there is none
+func (s *state) groupIndex(id int) int {
+	for i := range s.groups {
+		if s.groups[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// groupsExist return error if any of the groups does not exist, like the foreign keys of the SQL backends
+// groups in the trash exist
+func (s *state) groupsExist(ids ...int) error {
+	for _, id := range ids {
+		if s.groupIndex(id) < 0 {
+			return fmt.Errorf("group %v does not exist", id)
+		}
+	}
+
+	return nil
+}
+
+// PopulateTestData replace the content of the inventory directory with the test inventory in
+// fixturesPath/inventory
+func (db *Database) PopulateTestData(fixturesPath string) (err error) {
+	dir := db.store.dir
+
+	for _, path := range []string{filepath.Join(dir, hostsDir), filepath.Join(dir, groupsDir), db.store.audit} {
+		if err = os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range []string{hostsDir, groupsDir} {
+		if err = os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil { // nolint: gosec
+			return err
+		}
+
+		entries, err := ioutil.ReadDir(filepath.Join(fixturesPath, "inventory", sub))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			content, err := ioutil.ReadFile(filepath.Join(fixturesPath, "inventory", sub, entry.Name()))
+			if err != nil {
+				return err
+			}
+
+			if err = writeFile(filepath.Join(dir, sub, entry.Name()), content); err != nil {
+				return err
+			}
+		}
+	}
+
+	db.store.mu.Lock()
+	defer db.store.mu.Unlock()
+
+	// the test inventory is numbered from the start, like the fixtures of the SQL backends
+	db.store.state = nil
+
+	return db.store.reload()
+}
@@ -0,0 +1,109 @@
+package files
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// FilterHosts return the hosts matching the filter ordered by hostname
+func (db *Database) FilterHosts(filter datastructs.Filter) (hosts []datastructs.Host, err error) {
+	return db.FilterHostsContext(context.Background(), filter)
+}
+
+// FilterHostsContext is FilterHosts with a context bounding the query
+func (db *Database) FilterHostsContext(ctx context.Context, filter datastructs.Filter) (
+	hosts []datastructs.Host, err error) {
+	if err = filter.Validate(datastructs.HostFilterFields); err != nil {
+		return hosts, err
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return hosts, err
+	}
+
+	v := newView(s)
+
+	return v.hostRows(func(h *datastructs.Host) bool {
+		row := v.host(*h)
+
+		return filter.Matches(func(field string) []string {
+			switch field {
+			case "hostname":
+				return []string{row.Hostname}
+			case "ip":
+				return []string{row.Host}
+			case "domain":
+				return []string{row.Domain}
+			case "enabled":
+				return []string{strconv.FormatBool(row.Enabled)}
+			case "monitored":
+				return []string{strconv.FormatBool(row.Monitored)}
+			case "group":
+				return row.DirectGroups
+			case "inherited_group":
+				return splitNames(row.InheritedGroups)
+			}
+
+			return varValues(row.Variables, field)
+		})
+	}), nil
+}
+
+// FilterGroups return the groups matching the filter ordered by ID
+func (db *Database) FilterGroups(filter datastructs.Filter) (groups []datastructs.Group, err error) {
+	return db.FilterGroupsContext(context.Background(), filter)
+}
+
+// FilterGroupsContext is FilterGroups with a context bounding the query
+func (db *Database) FilterGroupsContext(ctx context.Context, filter datastructs.Filter) (
+	groups []datastructs.Group, err error) {
+	if err = filter.Validate(datastructs.GroupFilterFields); err != nil {
+		return groups, err
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	return newView(s).groupRows(func(g *datastructs.Group) bool {
+		return filter.Matches(func(field string) []string {
+			switch field {
+			case "name":
+				return []string{g.Name}
+			case "enabled":
+				return []string{strconv.FormatBool(g.Enabled)}
+			case "monitored":
+				return []string{strconv.FormatBool(g.Monitored)}
+			}
+
+			return varValues(g.Variables, field)
+		})
+	}), nil
+}
+
+// varValues return the value of the variable filter field as single value, none when the variable is not set
+func varValues(variables, field string) []string {
+	if !strings.HasPrefix(field, datastructs.VarPrefix) {
+		return nil
+	}
+
+	if value, ok := datastructs.VarValue(variables, strings.TrimPrefix(field, datastructs.VarPrefix)); ok {
+		return []string{value}
+	}
+
+	return nil
+}
+
+// splitNames split comma separated group names
+func splitNames(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,70 @@
+package files
+
+import (
+	"context"
+	"sort"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// IterateHosts call fn with every host ordered by hostname, within page. The iteration stops at the first error
+// returned by fn
+func (db *Database) IterateHosts(page datastructs.Page, fn func(host *datastructs.Host) error) error {
+	return db.IterateHostsContext(context.Background(), page, fn)
+}
+
+// IterateHostsContext is IterateHosts with a context bounding the query
+func (db *Database) IterateHostsContext(ctx context.Context, page datastructs.Page,
+	fn func(host *datastructs.Host) error) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	hosts := newView(s).hostRows(func(*datastructs.Host) bool { return true })
+	start, end := page.Bounds(len(hosts))
+
+	for i := start; i < end; i++ {
+		if err = fn(&hosts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IterateGroups call fn with every group ordered by name, within page. The iteration stops at the first error
+// returned by fn
+func (db *Database) IterateGroups(page datastructs.Page, fn func(group *datastructs.Group) error) error {
+	return db.IterateGroupsContext(context.Background(), page, fn)
+}
+
+// IterateGroupsContext is IterateGroups with a context bounding the query
+func (db *Database) IterateGroupsContext(ctx context.Context, page datastructs.Page,
+	fn func(group *datastructs.Group) error) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	s, err := db.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	groups := newView(s).groupRows(func(*datastructs.Group) bool { return true })
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	start, end := page.Bounds(len(groups))
+
+	for i := start; i < end; i++ {
+		if err = fn(&groups[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
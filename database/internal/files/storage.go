@@ -0,0 +1,631 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/via-justa/admiral/datastructs"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	hostsDir  = "hosts"
+	groupsDir = "groups"
+	// auditFile is the key of the audit log in the files, the file is written at store.audit
+	auditFile = "audit_log.yaml"
+	// defaultAuditSuffix name the audit log after the directory when no path is configured, e.g.
+	// inventory.audit_log.yaml next to inventory/
+	defaultAuditSuffix = "." + auditFile
+	extension          = ".yaml"
+)
+
+// state hold the inventory the same way the SQL backends hold it in their tables, the hosts and groups
+// hold only their own columns and the relationships reference them by ID. IDs are not written to the
+// files, they are assigned in name order when the directory is first loaded and kept by name when it is
+// loaded again
+type state struct {
+	hosts       []datastructs.Host
+	groups      []datastructs.Group
+	hostGroups  []datastructs.HostGroup
+	childGroups []datastructs.ChildGroup
+	addresses   []datastructs.Address
+	changes     []datastructs.Change
+	// last IDs given, like SQL auto increment IDs are not reused
+	lastHost, lastGroup, lastHostGroup, lastChildGroup, lastAddress int
+	lastChange                                                      int64
+}
+
+// clone return a copy of s that can be changed without affecting s
+func (s *state) clone() *state {
+	c := *s
+	c.hosts = append([]datastructs.Host(nil), s.hosts...)
+	c.groups = append([]datastructs.Group(nil), s.groups...)
+	c.hostGroups = append([]datastructs.HostGroup(nil), s.hostGroups...)
+	c.childGroups = append([]datastructs.ChildGroup(nil), s.childGroups...)
+	c.addresses = append([]datastructs.Address(nil), s.addresses...)
+	c.changes = append([]datastructs.Change(nil), s.changes...)
+
+	return &c
+}
+
+// hostFile is the content of hosts/<hostname>.yaml
+type hostFile struct {
+	IP        string                 `yaml:"ip"`
+	Domain    string                 `yaml:"domain"`
+	Enabled   bool                   `yaml:"enabled"`
+	Monitored bool                   `yaml:"monitored"`
+	Groups    []string               `yaml:"groups,omitempty"`
+	Addresses []addressFile          `yaml:"addresses,omitempty"`
+	Variables map[string]interface{} `yaml:"variables,omitempty"`
+	Created   string                 `yaml:"created"`
+	Updated   string                 `yaml:"updated"`
+	Deleted   string                 `yaml:"deleted,omitempty"`
+	Revision  int64                  `yaml:"revision,omitempty"`
+}
+
+type addressFile struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Primary bool   `yaml:"primary,omitempty"`
+}
+
+// groupFile is the content of groups/<name>.yaml
+type groupFile struct {
+	Enabled   bool                   `yaml:"enabled"`
+	Monitored bool                   `yaml:"monitored"`
+	Children  []string               `yaml:"children,omitempty"`
+	Variables map[string]interface{} `yaml:"variables,omitempty"`
+	Created   string                 `yaml:"created"`
+	Updated   string                 `yaml:"updated"`
+	Deleted   string                 `yaml:"deleted,omitempty"`
+	Revision  int64                  `yaml:"revision,omitempty"`
+}
+
+// changeFile is an audit_log.yaml entry
+type changeFile struct {
+	ID        int64  `yaml:"id"`
+	ChangedAt string `yaml:"changed_at"`
+	Actor     string `yaml:"actor"`
+	Entity    string `yaml:"entity"`
+	Name      string `yaml:"name"`
+	Related   string `yaml:"related,omitempty"`
+	Action    string `yaml:"action"`
+	Before    string `yaml:"before,omitempty"`
+	After     string `yaml:"after,omitempty"`
+	// Cause is written by older versions and ignored
+	Cause int64 `yaml:"cause,omitempty"`
+}
+
+// ids are the IDs of the state the directory is loaded again over, by the names they are kept for
+type ids struct {
+	hosts, groups           map[string]int
+	hostGroups, childGroups map[[2]int]int
+	addresses               map[addressKey]int
+}
+
+type addressKey struct {
+	hostID int
+	name   string
+}
+
+// ids return the IDs of s by name, the hosts and groups by their name and the relationships by what they relate
+func (s *state) ids() ids {
+	i := ids{hosts: map[string]int{}, groups: map[string]int{}, hostGroups: map[[2]int]int{},
+		childGroups: map[[2]int]int{}, addresses: map[addressKey]int{}}
+
+	for _, h := range s.hosts {
+		i.hosts[h.Hostname] = h.ID
+	}
+
+	for _, g := range s.groups {
+		i.groups[g.Name] = g.ID
+	}
+
+	for _, hg := range s.hostGroups {
+		i.hostGroups[[2]int{hg.HostID, hg.GroupID}] = hg.ID
+	}
+
+	for _, cg := range s.childGroups {
+		i.childGroups[[2]int{cg.ParentID, cg.ChildID}] = cg.ID
+	}
+
+	for _, a := range s.addresses {
+		i.addresses[addressKey{a.HostID, a.Name}] = a.ID
+	}
+
+	return i
+}
+
+// keep return id, the ID kept from the previous load, or the ID following last when there is none
+func keep(id int, last *int) int {
+	if id != 0 {
+		return id
+	}
+
+	*last++
+
+	return *last
+}
+
+// load read the inventory from dir and the audit log from audit and return it together with the content of the
+// files it was read from. The hosts, groups and relationships still in the files keep their ID in prev, a reload
+// does not change the IDs callers hold, and new ones are numbered after the last ID of prev
+func load(dir, audit string, prev *state) (s *state, files map[string][]byte, err error) {
+	s = new(state)
+	files = make(map[string][]byte)
+
+	if prev == nil {
+		prev = new(state)
+	}
+
+	kept := prev.ids()
+	s.lastHost, s.lastGroup, s.lastHostGroup = prev.lastHost, prev.lastGroup, prev.lastHostGroup
+	s.lastChildGroup, s.lastAddress = prev.lastChildGroup, prev.lastAddress
+
+	groups, err := readDir(dir, groupsDir, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupIDs := make(map[string]int, len(groups))
+	children := make(map[int][]string, len(groups))
+
+	for _, name := range sortedKeys(groups) {
+		var f groupFile
+		if err = yaml.UnmarshalStrict(groups[name], &f); err != nil {
+			return nil, nil, fmt.Errorf("%v: %v", filepath.Join(groupsDir, name+extension), err)
+		}
+
+		id := keep(kept.groups[name], &s.lastGroup)
+		groupIDs[name] = id
+		children[id] = f.Children
+
+		variables, err := marshalVariables(f.Variables)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%v: %v", filepath.Join(groupsDir, name+extension), err)
+		}
+
+		s.groups = append(s.groups, datastructs.Group{ID: id, Name: name, Variables: variables,
+			Enabled: f.Enabled, Monitored: f.Monitored, Created: f.Created, Updated: f.Updated, Deleted: f.Deleted,
+			Revision: revision(f.Revision)})
+	}
+
+	for _, g := range s.groups {
+		for _, child := range children[g.ID] {
+			childID, ok := groupIDs[child]
+			if !ok {
+				return nil, nil, fmt.Errorf("%v: unknown child group %v", filepath.Join(groupsDir, g.Name+extension), child)
+			}
+
+			s.childGroups = append(s.childGroups, datastructs.ChildGroup{
+				ID: keep(kept.childGroups[[2]int{g.ID, childID}], &s.lastChildGroup), ChildID: childID, ParentID: g.ID})
+		}
+	}
+
+	hosts, err := readDir(dir, hostsDir, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range sortedKeys(hosts) {
+		if err = s.loadHost(name, hosts[name], groupIDs, kept); err != nil {
+			return nil, nil, fmt.Errorf("%v: %v", filepath.Join(hostsDir, name+extension), err)
+		}
+	}
+
+	if err = s.loadChanges(audit, files); err != nil {
+		return nil, nil, err
+	}
+
+	return s, files, nil
+}
+
+// revision return the revision read from a file, hand written files without one are at the first revision
+func revision(r int64) int64 {
+	if r == 0 {
+		return 1
+	}
+
+	return r
+}
+
+func (s *state) loadHost(hostname string, content []byte, groupIDs map[string]int, kept ids) error {
+	var f hostFile
+	if err := yaml.UnmarshalStrict(content, &f); err != nil {
+		return err
+	}
+
+	variables, err := marshalVariables(f.Variables)
+	if err != nil {
+		return err
+	}
+
+	id := keep(kept.hosts[hostname], &s.lastHost)
+	s.hosts = append(s.hosts, datastructs.Host{ID: id, Host: f.IP, Hostname: hostname, Domain: f.Domain,
+		Variables: variables, Enabled: f.Enabled, Monitored: f.Monitored, Created: f.Created, Updated: f.Updated,
+		Deleted: f.Deleted, Revision: revision(f.Revision)})
+
+	for _, group := range f.Groups {
+		groupID, ok := groupIDs[group]
+		if !ok {
+			return fmt.Errorf("unknown group %v", group)
+		}
+
+		s.hostGroups = append(s.hostGroups, datastructs.HostGroup{
+			ID: keep(kept.hostGroups[[2]int{id, groupID}], &s.lastHostGroup), HostID: id, GroupID: groupID})
+	}
+
+	for _, a := range f.Addresses {
+		s.addresses = append(s.addresses, datastructs.Address{ID: keep(kept.addresses[addressKey{id, a.Name}],
+			&s.lastAddress), HostID: id, Name: a.Name, Address: a.Address, Primary: a.Primary})
+	}
+
+	return nil
+}
+
+func (s *state) loadChanges(audit string, files map[string][]byte) error {
+	content, err := ioutil.ReadFile(audit)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	files[auditFile] = content
+
+	var changes []changeFile
+	if err = yaml.UnmarshalStrict(content, &changes); err != nil {
+		return fmt.Errorf("%v: %v", audit, err)
+	}
+
+	for _, c := range changes {
+		s.changes = append(s.changes, datastructs.Change{ID: c.ID, ChangedAt: c.ChangedAt, Actor: c.Actor,
+			Entity: c.Entity, Name: c.Name, Related: c.Related, Action: c.Action, Before: c.Before, After: c.After})
+
+		if c.ID > s.lastChange {
+			s.lastChange = c.ID
+		}
+	}
+
+	return nil
+}
+
+// readDir return the content of the yaml files in dir/sub by their name without the extension,
+// adding them to files by their path relative to dir
+func readDir(dir, sub string, files map[string][]byte) (map[string][]byte, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(dir, sub))
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string][]byte, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != extension {
+			continue
+		}
+
+		path := filepath.Join(sub, entry.Name())
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return nil, err
+		}
+
+		files[path] = b
+		content[strings.TrimSuffix(entry.Name(), extension)] = b
+	}
+
+	return content, nil
+}
+
+// files return the content of every file of the inventory by its path relative to the directory
+func (s *state) files() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(s.hosts)+len(s.groups)+1)
+	groups := make(map[int]string, len(s.groups))
+
+	for _, g := range s.groups {
+		groups[g.ID] = g.Name
+	}
+
+	children := make(map[int][]string, len(s.groups))
+	for _, cg := range s.childGroups {
+		children[cg.ParentID] = append(children[cg.ParentID], groups[cg.ChildID])
+	}
+
+	hostGroups := make(map[int][]string, len(s.hosts))
+	for _, hg := range s.hostGroups {
+		hostGroups[hg.HostID] = append(hostGroups[hg.HostID], groups[hg.GroupID])
+	}
+
+	addresses := make(map[int][]addressFile, len(s.hosts))
+	for _, a := range s.addresses {
+		addresses[a.HostID] = append(addresses[a.HostID], addressFile{Name: a.Name, Address: a.Address,
+			Primary: a.Primary})
+	}
+
+	for _, g := range s.groups {
+		f := groupFile{Enabled: g.Enabled, Monitored: g.Monitored, Children: children[g.ID], Created: g.Created,
+			Updated: g.Updated, Deleted: g.Deleted, Revision: g.Revision}
+
+		sort.Strings(f.Children)
+
+		if err := encode(files, filepath.Join(groupsDir, g.Name+extension), g.Variables, &f.Variables, &f); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, h := range s.hosts {
+		f := hostFile{IP: h.Host, Domain: h.Domain, Enabled: h.Enabled, Monitored: h.Monitored,
+			Groups: hostGroups[h.ID], Addresses: addresses[h.ID], Created: h.Created, Updated: h.Updated,
+			Deleted: h.Deleted, Revision: h.Revision}
+
+		sort.Strings(f.Groups)
+		sort.Slice(f.Addresses, func(i, j int) bool { return f.Addresses[i].Name < f.Addresses[j].Name })
+
+		if err := encode(files, filepath.Join(hostsDir, h.Hostname+extension), h.Variables, &f.Variables,
+			&f); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.changes) == 0 {
+		return files, nil
+	}
+
+	changes := make([]changeFile, 0, len(s.changes))
+	for _, c := range s.changes {
+		changes = append(changes, changeFile{ID: c.ID, ChangedAt: c.ChangedAt, Actor: c.Actor, Entity: c.Entity,
+			Name: c.Name, Related: c.Related, Action: c.Action, Before: c.Before, After: c.After})
+	}
+
+	b, err := yaml.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	files[auditFile] = b
+
+	return files, nil
+}
+
+// encode set the yaml content of path in files, the json variables are decoded into vars, a field of f, first
+func encode(files map[string][]byte, path, variables string, vars *map[string]interface{}, f interface{}) error {
+	if err := unmarshalVariables(variables, vars); err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+
+	files[path] = b
+
+	return nil
+}
+
+// store is the inventory directory shared by a Database and its transactions, the Databases may be used
+// concurrently, e.g. by admiral serve. The committed state is never modified, writes replace it
+type store struct {
+	dir string
+	// audit is the path of the audit log, kept out of dir so branches of the inventory do not conflict on it
+	audit string
+	// mu guard the fields below
+	mu    sync.Mutex
+	state *state
+	// files hold the content of the committed state files, as they would be written
+	files map[string][]byte
+	// disk hold the content of the files as admiral last read or wrote them
+	disk map[string][]byte
+	// version is incremented on every commit
+	version int
+}
+
+// commit write the files that changed between the committed state and s and make s the committed state.
+// Files of hosts and groups that did not change are not rewritten, keeping their comments and formatting.
+// commit is called with st.mu held
+func (st *store) commit(s *state) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	var changed, removed []string
+
+	for path, b := range files {
+		if !bytes.Equal(st.files[path], b) {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range st.files {
+		if _, ok := files[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+
+	// refuse to overwrite changes made to the files since they were read, e.g. by a git pull or another admiral
+	for _, path := range append(changed, removed...) {
+		if err = st.unchanged(path); err != nil {
+			// the directory is read again, the next write is made on top of the new content
+			if loadErr := st.reload(); loadErr != nil {
+				return fmt.Errorf("%w, reading the directory again failed: %v", err, loadErr)
+			}
+
+			return err
+		}
+	}
+
+	for _, path := range changed {
+		if err = writeFile(st.path(path), files[path]); err != nil {
+			return err
+		}
+
+		st.disk[path] = files[path]
+	}
+
+	for _, path := range removed {
+		if err = os.Remove(st.path(path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		delete(st.disk, path)
+	}
+
+	st.state = s
+	st.files = files
+	st.version++
+
+	return nil
+}
+
+// unchanged return error if the file at path does not hold the content it was last read or written with
+func (st *store) unchanged(path string) error {
+	b, err := ioutil.ReadFile(st.path(path))
+	if os.IsNotExist(err) {
+		b, err = nil, nil
+	} else if err != nil {
+		return err
+	}
+
+	if read, ok := st.disk[path]; (ok || b != nil) && !bytes.Equal(read, b) {
+		return fmt.Errorf("%v was changed outside of admiral, run the command again to use the new content", path)
+	}
+
+	return nil
+}
+
+// path return the path of the file, files are keyed by their path relative to the directory
+func (st *store) path(file string) string {
+	if file == auditFile {
+		return st.audit
+	}
+
+	return filepath.Join(st.dir, file)
+}
+
+// writeFile replace the file at path with content, the content is written to a temporary file first so
+// the file is never left partially written
+func writeFile(path string, content []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close() // nolint: errcheck,gosec
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp.Name(), 0644); err != nil { // nolint: gosec
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// marshalVariables convert the variables read from a yaml file to the json string the other backends store
+func marshalVariables(vars map[string]interface{}) (string, error) {
+	if len(vars) == 0 {
+		return "{}", nil
+	}
+
+	converted, err := jsonValue(vars)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(converted)
+
+	return string(b), err
+}
+
+// jsonValue convert the nested maps decoded by yaml, keyed by interface{}, to maps json can encode
+func jsonValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("variable key %v is not a string", key)
+			}
+
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = converted
+		}
+
+		return m, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+
+		for k, item := range v {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = converted
+		}
+
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+
+		for _, item := range v {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			l = append(l, converted)
+		}
+
+		return l, nil
+	default:
+		return v, nil
+	}
+}
+
+// unmarshalVariables decode the json variables of a host or group into vars
+func unmarshalVariables(variables string, vars *map[string]interface{}) error {
+	if variables == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(variables), vars); err != nil {
+		return fmt.Errorf("invalid variables: %v", err)
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
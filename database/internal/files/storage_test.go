@@ -0,0 +1,357 @@
+// nolint
+package files
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func prepTestDB(t *testing.T) *Database {
+	db, err := Connect(config.FilesConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.PopulateTestData("../../../fixtures"); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func writeTestFile(t *testing.T, db *Database, path, content string) {
+	if err := ioutil.WriteFile(filepath.Join(db.store.dir, path), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnect_handEditedFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "new host",
+			path:    "hosts/host4.yaml",
+			content: "ip: 4.4.4.4\ndomain: domain.local\nenabled: true\ngroups: [group1]\nvariables:\n  port: 22\n",
+		},
+		{
+			name:    "unknown group",
+			path:    "hosts/host4.yaml",
+			content: "ip: 4.4.4.4\ngroups: [group10]\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown child group",
+			path:    "groups/group6.yaml",
+			content: "children: [group10]\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			path:    "hosts/host4.yaml",
+			content: "ip: 4.4.4.4\ngroup: group1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := prepTestDB(t)
+			writeTestFile(t, db, tt.path, tt.content)
+
+			got, err := Connect(config.FilesConfig{Path: db.store.dir})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			host4, _ := got.SelectHost("host4")
+			if host4.Host != "4.4.4.4" || host4.Variables != `{"port":22}` || host4.DirectGroups.String() != "group1" {
+				t.Errorf("SelectHost() = %+v, want hand-edited host4", host4)
+			}
+		})
+	}
+}
+
+func TestDatabase_writeFiles(t *testing.T) {
+	db := prepTestDB(t)
+
+	host1, _ := db.SelectHost("host1")
+	host1.Variables = `{"b": [1, "two"], "a": {"c": true}}`
+
+	if _, err := db.InsertHost(&host1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.InsertAddress(&datastructs.Address{HostID: host1.ID, Name: "public", Address: "1.1.1.1",
+		Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	host1, _ = db.SelectHost("host1")
+
+	content, err := ioutil.ReadFile(filepath.Join(db.store.dir, "hosts", "host1.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `ip: 1.1.1.1
+domain: domain.local
+enabled: true
+monitored: true
+groups:
+- group1
+addresses:
+- name: public
+  address: 1.1.1.1
+  primary: true
+variables:
+  a:
+    c: true
+  b:
+  - 1
+  - two
+created: "2020-11-01T10:00:00Z"
+updated: "` + host1.Updated
+
+	if !strings.HasPrefix(string(content), want) {
+		t.Errorf("host1.yaml = %s, want %s", content, want)
+	}
+
+	// unchanged files are not rewritten
+	info, _ := os.Stat(filepath.Join(db.store.dir, "hosts", "host2.yaml"))
+	if fixture, _ := os.Stat("../../../fixtures/inventory/hosts/host2.yaml"); info.Size() != fixture.Size() {
+		t.Errorf("host2.yaml size = %v, want fixture file kept", info.Size())
+	}
+
+	host2, _ := db.SelectHost("host2")
+	if _, err = db.DeleteHost(&host2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = db.PurgeHost(&host2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(db.store.dir, "hosts", "host2.yaml")); !os.IsNotExist(err) {
+		t.Errorf("host2.yaml error = %v, want purged host file removed", err)
+	}
+}
+
+func TestDatabase_auditLog(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name      string
+		conf      config.FilesConfig
+		wantAudit string
+	}{
+		{
+			name:      "next to the directory",
+			conf:      config.FilesConfig{Path: filepath.Join(root, "inventory")},
+			wantAudit: filepath.Join(root, "inventory.audit_log.yaml"),
+		},
+		{
+			name:      "configured",
+			conf:      config.FilesConfig{Path: filepath.Join(root, "configured"), Audit: filepath.Join(root, "log", "a.yaml")},
+			wantAudit: filepath.Join(root, "log", "a.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Connect(tt.conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = db.InsertChange(&datastructs.Change{Actor: "admin", Entity: "host", Name: "host1",
+				Action: "update"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = os.Stat(tt.wantAudit); err != nil {
+				t.Errorf("InsertChange() audit log error = %v, want %v written", err, tt.wantAudit)
+			}
+
+			if _, err = os.Stat(filepath.Join(tt.conf.Path, auditFile)); !os.IsNotExist(err) {
+				t.Errorf("InsertChange() error = %v, want no audit log in the directory", err)
+			}
+
+			got, err := Connect(tt.conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if changes, _ := got.SelectChanges("host", "host1"); len(changes) != 1 {
+				t.Errorf("SelectChanges() = %v, want the change read back", changes)
+			}
+		})
+	}
+}
+
+func TestDatabase_changedOutside(t *testing.T) {
+	db := prepTestDB(t)
+
+	writeTestFile(t, db, "hosts/host1.yaml", "ip: 1.1.1.1\n")
+
+	host1, _ := db.SelectHost("host1")
+	host1.Enabled = false
+
+	if _, err := db.InsertHost(&host1); err == nil {
+		t.Errorf("InsertHost() error = nil, want error for host file changed outside of admiral")
+	}
+
+	// other files can still be written
+	host2, _ := db.SelectHost("host2")
+	host2.Enabled = false
+
+	if _, err := db.InsertHost(&host2); err != nil {
+		t.Errorf("InsertHost() error = %v", err)
+	}
+
+	// the directory was read again, the host is written on top of the new content
+	host1, _ = db.SelectHost("host1")
+	host1.Enabled = false
+
+	if _, err := db.InsertHost(&host1); err != nil {
+		t.Errorf("InsertHost() error = %v, want host written after reading the changed file", err)
+	}
+}
+
+func TestDatabase_reloadKeepIDs(t *testing.T) {
+	db := prepTestDB(t)
+
+	before := db.store.state.ids()
+
+	// files sorted before the existing ones, numbered in name order they would shift every ID
+	writeTestFile(t, db, "groups/group0.yaml", "enabled: true\n")
+	writeTestFile(t, db, "hosts/host0.yaml", "ip: 4.4.4.4\ngroups: [group0, group1]\n")
+
+	if err := db.store.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	after := db.store.state.ids()
+
+	for name, id := range before.hosts {
+		if after.hosts[name] != id {
+			t.Errorf("load() %v ID = %v, want %v", name, after.hosts[name], id)
+		}
+	}
+
+	for name, id := range before.groups {
+		if after.groups[name] != id {
+			t.Errorf("load() %v ID = %v, want %v", name, after.groups[name], id)
+		}
+	}
+
+	for key, id := range before.hostGroups {
+		if after.hostGroups[key] != id {
+			t.Errorf("load() host group %v ID = %v, want %v", key, after.hostGroups[key], id)
+		}
+	}
+
+	if got, want := after.hosts["host0"], len(before.hosts)+1; got != want {
+		t.Errorf("load() host0 ID = %v, want %v", got, want)
+	}
+
+	if got, want := after.groups["group0"], len(before.groups)+1; got != want {
+		t.Errorf("load() group0 ID = %v, want %v", got, want)
+	}
+}
+
+func TestDatabase_concurrent(t *testing.T) {
+	db := prepTestDB(t)
+
+	const writers = 10
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 2*writers)
+
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := db.InsertGroup(&datastructs.Group{Name: fmt.Sprintf("group1%v", i), Variables: "{}"})
+			errs <- err
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := db.GetGroups()
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call error = %v", err)
+		}
+	}
+
+	// no write was lost
+	for i := 0; i < writers; i++ {
+		if got, _ := db.SelectGroup(fmt.Sprintf("group1%v", i)); got.ID == 0 {
+			t.Errorf("SelectGroup(group1%v) = %+v, want concurrently inserted group", i, got)
+		}
+	}
+}
+
+func TestDatabase_Commit(t *testing.T) {
+	db := prepTestDB(t)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = db.InsertGroup(&datastructs.Group{Name: "group10", Variables: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tx.InsertGroup(&datastructs.Group{Name: "group11", Variables: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.Commit(); err == nil {
+		t.Errorf("Commit() error = nil, want error for inventory changed since the transaction started")
+	}
+
+	if got, _ := db.SelectGroup("group10"); got.ID == 0 {
+		t.Errorf("SelectGroup() = %+v, want change made outside of the transaction kept", got)
+	}
+
+	if err = tx.Rollback(); err == nil {
+		t.Errorf("Rollback() error = nil, want error for finished transaction")
+	}
+}
+
+func TestDatabase_InsertHost_invalidName(t *testing.T) {
+	db := prepTestDB(t)
+
+	for _, hostname := range []string{"", "..", "dc1/host1"} {
+		if _, err := db.InsertHost(&datastructs.Host{Hostname: hostname, Host: "10.0.0.1"}); err == nil {
+			t.Errorf("InsertHost(%q) error = nil, want error for invalid file name", hostname)
+		}
+	}
+}
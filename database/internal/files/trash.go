@@ -0,0 +1,175 @@
+// nolint: golint
+package files
+
+import (
+	"context"
+	"sort"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// GetDeletedHosts return all hosts in the trash
+func (db *Database) GetDeletedHosts() (hosts []datastructs.Host, err error) {
+	return db.GetDeletedHostsContext(context.Background())
+}
+
+// GetDeletedHostsContext is GetDeletedHosts with a context bounding the query
+func (db *Database) GetDeletedHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return hosts, err
+	}
+
+	for _, h := range s.hosts {
+		if h.Deleted != "" {
+			hosts = append(hosts, h)
+		}
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Hostname < hosts[j].Hostname })
+
+	return hosts, nil
+}
+
+// RestoreHost accept Host to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (db *Database) RestoreHost(host *datastructs.Host) (affected int64, err error) {
+	return db.RestoreHostContext(context.Background(), host)
+}
+
+// RestoreHostContext is RestoreHost with a context bounding the query
+func (db *Database) RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		i := s.hostIndex(host.ID)
+		if i < 0 || s.hosts[i].Deleted == "" {
+			return 0, nil
+		}
+
+		s.hosts[i].Deleted, s.hosts[i].Updated = "", timestamp()
+		s.hosts[i].Revision++
+
+		return 1, nil
+	})
+}
+
+// PurgeHost accept Host in the trash to delete permanently and return the number of affected rows and error if exists
+func (db *Database) PurgeHost(host *datastructs.Host) (affected int64, err error) {
+	return db.PurgeHostContext(context.Background(), host)
+}
+
+// PurgeHostContext is PurgeHost with a context bounding the query
+func (db *Database) PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		i := s.hostIndex(host.ID)
+		if i < 0 || s.hosts[i].Deleted == "" {
+			return 0, nil
+		}
+
+		s.hosts = append(s.hosts[:i], s.hosts[i+1:]...)
+
+		// the group memberships and addresses of the host are deleted with it
+		hostGroups := s.hostGroups[:0]
+
+		for _, hg := range s.hostGroups {
+			if hg.HostID != host.ID {
+				hostGroups = append(hostGroups, hg)
+			}
+		}
+
+		s.hostGroups = hostGroups
+		addresses := s.addresses[:0]
+
+		for _, a := range s.addresses {
+			if a.HostID != host.ID {
+				addresses = append(addresses, a)
+			}
+		}
+
+		s.addresses = addresses
+
+		return 1, nil
+	})
+}
+
+// GetDeletedGroups return all groups in the trash
+func (db *Database) GetDeletedGroups() (groups []datastructs.Group, err error) {
+	return db.GetDeletedGroupsContext(context.Background())
+}
+
+// GetDeletedGroupsContext is GetDeletedGroups with a context bounding the query
+func (db *Database) GetDeletedGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	s, err := db.read(ctx)
+	if err != nil {
+		return groups, err
+	}
+
+	for _, g := range s.groups {
+		if g.Deleted != "" {
+			groups = append(groups, g)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	return groups, nil
+}
+
+// RestoreGroup accept Group to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (db *Database) RestoreGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.RestoreGroupContext(context.Background(), group)
+}
+
+// RestoreGroupContext is RestoreGroup with a context bounding the query
+func (db *Database) RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		i := s.groupIndex(group.ID)
+		if i < 0 || s.groups[i].Deleted == "" {
+			return 0, nil
+		}
+
+		s.groups[i].Deleted, s.groups[i].Updated = "", timestamp()
+		s.groups[i].Revision++
+
+		return 1, nil
+	})
+}
+
+// PurgeGroup accept Group in the trash to delete permanently and return the number of affected rows and error if exists
+func (db *Database) PurgeGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.PurgeGroupContext(context.Background(), group)
+}
+
+// PurgeGroupContext is PurgeGroup with a context bounding the query
+func (db *Database) PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	return db.write(ctx, func(s *state) (int64, error) {
+		i := s.groupIndex(group.ID)
+		if i < 0 || s.groups[i].Deleted == "" {
+			return 0, nil
+		}
+
+		s.groups = append(s.groups[:i], s.groups[i+1:]...)
+
+		// the child, parent and host relationships of the group are deleted with it
+		childGroups := s.childGroups[:0]
+
+		for _, cg := range s.childGroups {
+			if cg.ChildID != group.ID && cg.ParentID != group.ID {
+				childGroups = append(childGroups, cg)
+			}
+		}
+
+		s.childGroups = childGroups
+		hostGroups := s.hostGroups[:0]
+
+		for _, hg := range s.hostGroups {
+			if hg.GroupID != group.ID {
+				hostGroups = append(hostGroups, hg)
+			}
+		}
+
+		s.hostGroups = hostGroups
+
+		return 1, nil
+	})
+}
@@ -0,0 +1,134 @@
+package files
+
+import (
+	"context"
+	"fmt"
+)
+
+// current return the transaction state if one is in progress, otherwise the committed state
+func (db *Database) current() *state {
+	if db.tx != nil {
+		return db.tx
+	}
+
+	db.store.mu.Lock()
+	defer db.store.mu.Unlock()
+
+	return db.store.state
+}
+
+// read return the state to read from unless ctx is already done
+func (db *Database) read(ctx context.Context) (*state, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return db.current(), nil
+}
+
+// write apply fn to a copy of the current state. Outside of a transaction the copy is written to the
+// directory right away, in a transaction it replace the transaction state. The state is left untouched
+// when fn fails so every write is applied entirely or not at all, like a single SQL statement
+func (db *Database) write(ctx context.Context, fn func(s *state) (int64, error)) (affected int64, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if db.done {
+		return 0, errTxDone
+	}
+
+	if db.tx != nil {
+		s := db.tx.clone()
+
+		if affected, err = fn(s); err != nil {
+			return 0, err
+		}
+
+		db.tx = s
+
+		return affected, nil
+	}
+
+	// the committed state is read and replaced under the lock, concurrent writes are applied one after the other
+	db.store.mu.Lock()
+	defer db.store.mu.Unlock()
+
+	s := db.store.state.clone()
+
+	if affected, err = fn(s); err != nil {
+		return 0, err
+	}
+
+	if err = db.store.commit(s); err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+var errTxDone = fmt.Errorf("transaction has already been committed or rolled back")
+
+// Begin start a new transaction and return a Database bound to it
+func (db *Database) Begin() (*Database, error) {
+	return db.BeginContext(context.Background())
+}
+
+// BeginContext is Begin with a context, the transaction is rolled back if the context is canceled
+// before it is committed
+func (db *Database) BeginContext(ctx context.Context) (*Database, error) {
+	if db.tx != nil {
+		return nil, fmt.Errorf("transaction already in progress")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.store.mu.Lock()
+	defer db.store.mu.Unlock()
+
+	return &Database{store: db.store, tx: db.store.state.clone(), base: db.store.version, ctx: ctx}, nil
+}
+
+// Commit write all changes made in the transaction to the directory
+func (db *Database) Commit() error {
+	if db.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	if db.done {
+		return errTxDone
+	}
+
+	db.done = true
+
+	if err := db.ctx.Err(); err != nil {
+		return err
+	}
+
+	db.store.mu.Lock()
+	defer db.store.mu.Unlock()
+
+	// the transaction state replace the committed one, it cannot hold changes committed after it started
+	if db.store.version != db.base {
+		return fmt.Errorf("the inventory was changed since the transaction started")
+	}
+
+	return db.store.commit(db.tx)
+}
+
+// Rollback discard all changes made in the transaction
+func (db *Database) Rollback() error {
+	if db.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	if db.done {
+		return errTxDone
+	}
+
+	db.done = true
+
+	return nil
+}
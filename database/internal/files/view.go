@@ -0,0 +1,223 @@
+package files
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// view compute from a state the same data the SQL backends read from their views, deleted hosts and
+// groups and their relationships are hidden
+type view struct {
+	s      *state
+	hosts  map[int]*datastructs.Host
+	groups map[int]*datastructs.Group
+	// active child-group relationships by parent and by child ID
+	children, parents map[int][]int
+	// active host-group relationships by host ID
+	hostGroups map[int][]int
+}
+
+func newView(s *state) *view {
+	v := &view{s: s, hosts: make(map[int]*datastructs.Host, len(s.hosts)),
+		groups: make(map[int]*datastructs.Group, len(s.groups)), children: make(map[int][]int),
+		parents: make(map[int][]int), hostGroups: make(map[int][]int)}
+
+	for i := range s.hosts {
+		v.hosts[s.hosts[i].ID] = &s.hosts[i]
+	}
+
+	for i := range s.groups {
+		v.groups[s.groups[i].ID] = &s.groups[i]
+	}
+
+	for _, cg := range s.childGroups {
+		if v.activeGroup(cg.ParentID) && v.activeGroup(cg.ChildID) {
+			v.children[cg.ParentID] = append(v.children[cg.ParentID], cg.ChildID)
+			v.parents[cg.ChildID] = append(v.parents[cg.ChildID], cg.ParentID)
+		}
+	}
+
+	for _, hg := range s.hostGroups {
+		if v.activeHost(hg.HostID) && v.activeGroup(hg.GroupID) {
+			v.hostGroups[hg.HostID] = append(v.hostGroups[hg.HostID], hg.GroupID)
+		}
+	}
+
+	return v
+}
+
+func (v *view) activeHost(id int) bool {
+	h, ok := v.hosts[id]
+	return ok && h.Deleted == ""
+}
+
+func (v *view) activeGroup(id int) bool {
+	g, ok := v.groups[id]
+	return ok && g.Deleted == ""
+}
+
+// host return the host_view row of h
+func (v *view) host(h datastructs.Host) datastructs.Host {
+	var direct []string
+
+	for _, id := range v.hostGroups[h.ID] {
+		direct = append(direct, v.groups[id].Name)
+	}
+
+	h.DirectGroups = datastructs.NewGroupNames(direct)
+	h.InheritedGroups = v.names(v.reachable(v.hostGroups[h.ID], v.parents))
+	h.Deleted = ""
+
+	return h
+}
+
+// hostRows return the host_view rows matching the filter ordered by hostname
+func (v *view) hostRows(match func(h *datastructs.Host) bool) (hosts []datastructs.Host) {
+	for i := range v.s.hosts {
+		if v.s.hosts[i].Deleted == "" && match(&v.s.hosts[i]) {
+			hosts = append(hosts, v.host(v.s.hosts[i]))
+		}
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Hostname < hosts[j].Hostname })
+
+	return hosts
+}
+
+// group return the groups_view row of g
+func (v *view) group(g datastructs.Group) datastructs.Group {
+	children := v.reachable([]int{g.ID}, v.children)
+
+	g.NumChildren = len(children)
+	g.ChildGroups = v.names(children)
+	g.NumHosts = 0
+	g.Deleted = ""
+
+	// like the view, hosts are counted by the group memberships of the none-deleted hosts
+	for _, hg := range v.s.hostGroups {
+		if hg.GroupID == g.ID && v.activeHost(hg.HostID) {
+			g.NumHosts++
+		}
+	}
+
+	return g
+}
+
+// groupRows return the groups_view rows matching the filter ordered by ID
+func (v *view) groupRows(match func(g *datastructs.Group) bool) (groups []datastructs.Group) {
+	for i := range v.s.groups {
+		if v.s.groups[i].Deleted == "" && match(&v.s.groups[i]) {
+			groups = append(groups, v.group(v.s.groups[i]))
+		}
+	}
+
+	return groups
+}
+
+// childGroupRows return the childgroups_view rows matching the filter ordered by parent name
+func (v *view) childGroupRows(match func(cg *datastructs.ChildGroup) bool) (childGroups []datastructs.ChildGroup) {
+	for _, cg := range v.s.childGroups {
+		if !v.activeGroup(cg.ParentID) || !v.activeGroup(cg.ChildID) {
+			continue
+		}
+
+		cg.Parent = v.groups[cg.ParentID].Name
+		cg.Child = v.groups[cg.ChildID].Name
+
+		if match(&cg) {
+			childGroups = append(childGroups, cg)
+		}
+	}
+
+	sort.SliceStable(childGroups, func(i, j int) bool { return childGroups[i].Parent < childGroups[j].Parent })
+
+	return childGroups
+}
+
+// hostGroupRows return the hostgroup_view rows matching the filter ordered by group name
+func (v *view) hostGroupRows(match func(hg *datastructs.HostGroup) bool) (hostGroups []datastructs.HostGroup) {
+	for _, hg := range v.s.hostGroups {
+		if !v.activeHost(hg.HostID) || !v.activeGroup(hg.GroupID) {
+			continue
+		}
+
+		hg.Host = v.hosts[hg.HostID].Hostname
+		hg.Group = v.groups[hg.GroupID].Name
+
+		if match(&hg) {
+			hostGroups = append(hostGroups, hg)
+		}
+	}
+
+	sort.SliceStable(hostGroups, func(i, j int) bool { return hostGroups[i].Group < hostGroups[j].Group })
+
+	return hostGroups
+}
+
+// addressRows return the address_view rows matching the filter ordered by hostname and name
+func (v *view) addressRows(match func(a *datastructs.Address) bool) (addresses datastructs.Addresses) {
+	for _, a := range v.s.addresses {
+		if !v.activeHost(a.HostID) {
+			continue
+		}
+
+		a.Host = v.hosts[a.HostID].Hostname
+
+		if match(&a) {
+			addresses = append(addresses, a)
+		}
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].Host != addresses[j].Host {
+			return addresses[i].Host < addresses[j].Host
+		}
+
+		return addresses[i].Name < addresses[j].Name
+	})
+
+	return addresses
+}
+
+// reachable return the groups reachable from the given groups following edges, as the recursive
+// queries of the views do. The given groups are included only when reachable from another given group
+func (v *view) reachable(from []int, edges map[int][]int) []int {
+	seen := make(map[int]bool)
+
+	var ids []int
+
+	queue := append([]int(nil), from...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, next := range edges[id] {
+			if !seen[next] {
+				seen[next] = true
+				ids = append(ids, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return ids
+}
+
+// names return the sorted comma separated names of the groups
+func (v *view) names(ids []int) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, v.groups[id].Name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+// like match val the way the SQL LIKE '%val%' does
+func like(s, val string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(val))
+}
@@ -0,0 +1,82 @@
+// nolint: golint,rowserrcheck,errcheck
+package mariadb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+const changeColumns = "SELECT id, changed_at, actor, entity, name, related, action, before_state," +
+	" after_state FROM audit_log"
+
+// InsertChange accept Change to record in the audit log and return the ID of the recorded change
+func (db *Database) InsertChange(change *datastructs.Change) (id int64, err error) {
+	return db.InsertChangeContext(context.Background(), change)
+}
+
+// InsertChangeContext is InsertChange with a context bounding the query
+func (db *Database) InsertChangeContext(ctx context.Context, change *datastructs.Change) (id int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "INSERT INTO audit_log (changed_at, actor, entity, name, related,"+
+		" action, before_state, after_state) VALUES (?,?,?,?,?,?,?,?)", change.ChangedAt, change.Actor,
+		change.Entity, change.Name, change.Related, change.Action, change.Before, change.After)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SelectChanges return the audit log of a host or group ordered from the oldest change. Host changes
+// include its group membership and address changes and group changes include its child-group and host-group
+// relationship changes. If entity is empty the whole audit log is returned
+func (db *Database) SelectChanges(entity, name string) (changes []datastructs.Change, err error) {
+	return db.SelectChangesContext(context.Background(), entity, name)
+}
+
+// SelectChangesContext is SelectChanges with a context bounding the query
+func (db *Database) SelectChangesContext(ctx context.Context, entity, name string) (
+	changes []datastructs.Change, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	switch entity {
+	case "":
+		err = db.conn().SelectContext(ctx, &changes, changeColumns+" ORDER BY id")
+	case datastructs.EntityHost:
+		err = db.conn().SelectContext(ctx, &changes, changeColumns+" WHERE entity IN (?,?,?) AND name=? ORDER BY id",
+			datastructs.EntityHost, datastructs.EntityHostGroup, datastructs.EntityAddress, name)
+	case datastructs.EntityGroup:
+		err = db.conn().SelectContext(ctx, &changes, changeColumns+" WHERE (entity IN (?,?) AND name=?)"+
+			" OR (entity IN (?,?) AND related=?) ORDER BY id", datastructs.EntityGroup, datastructs.EntityChildGroup,
+			name, datastructs.EntityChildGroup, datastructs.EntityHostGroup, name)
+	default:
+		return changes, fmt.Errorf("unknown entity %v", entity)
+	}
+
+	return changes, err
+}
+
+// SelectChange return a single change by its ID
+func (db *Database) SelectChange(id int64) (change datastructs.Change, err error) {
+	return db.SelectChangeContext(context.Background(), id)
+}
+
+// SelectChangeContext is SelectChange with a context bounding the query
+func (db *Database) SelectChangeContext(ctx context.Context, id int64) (change datastructs.Change, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	var changes []datastructs.Change
+
+	err = db.conn().SelectContext(ctx, &changes, changeColumns+" WHERE id=?", id)
+	if err != nil || len(changes) == 0 {
+		return change, err
+	}
+
+	return changes[0], nil
+}
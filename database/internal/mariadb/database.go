@@ -2,14 +2,16 @@
 package mariadb
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"net"
+	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/via-justa/admiral/config"
 
-	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/via-justa/admiral/datastructs"
@@ -18,39 +20,91 @@ import (
 // Database exposes a database connection
 type Database struct {
 	Conn *sqlx.DB
+	// tx is set on a Database returned by Begin, all queries then run in the transaction
+	tx *sqlx.Tx
+	// timeout bound every query, zero disable it
+	timeout time.Duration
+	// tunnel is the ssh proxy connection of a Database connected by ProxyConnect
+	tunnel *tunnel
 }
 
 // Connect returns a Database connection
 func Connect(conf config.MariaDBConfig) (*Database, error) {
-	var db Database
+	return ConnectContext(context.Background(), conf)
+}
 
-	dbConfig := mysql.Config{
-		User:                 conf.User,
-		Passwd:               conf.Password,
-		Net:                  "tcp",
-		Addr:                 net.JoinHostPort(conf.Host, fmt.Sprint(conf.Port)),
-		DBName:               conf.DB,
-		AllowNativePasswords: true,
+// ConnectContext is Connect with a context bounding the connection attempt
+func ConnectContext(ctx context.Context, conf config.MariaDBConfig) (*Database, error) {
+	db := Database{timeout: conf.Timeout}
+	if db.timeout == 0 {
+		db.timeout = config.DefaultTimeout
 	}
 
 	var err error
 
-	db.Conn, err = sqlx.Open("mysql", dbConfig.FormatDSN())
+	db.Conn, err = open(conf, "")
 	if err != nil {
 		return &db, err
 	}
 
-	err = db.Conn.Ping()
+	err = db.ping(ctx)
 	if err != nil {
 		return &db, err
 	}
 
+	err = db.checkSchema()
+
 	return &db, err
 }
 
 // Close close the connection to database
 func (db *Database) Close() (err error) {
-	return db.Conn.Close()
+	err = db.Conn.Close()
+
+	if db.tunnel != nil {
+		if tunnelErr := db.tunnel.Close(); err == nil {
+			err = tunnelErr
+		}
+	}
+
+	return err
+}
+
+// timestamp return the current time in the format stored in the created and updated columns
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// bumpHostRevision increase the revision of the host whose groups or addresses changed, a write based on the
+// host read before the change is then rejected
+func (db *Database) bumpHostRevision(ctx context.Context, hostID int) error {
+	_, err := db.conn().ExecContext(ctx, "UPDATE host SET revision=revision+1 WHERE id=?", hostID)
+	return err
+}
+
+// checkRevision return ErrRevisionConflict if the record with the given unique value is not stored at revision,
+// a revision of 0 skip the check. A record that does not exist is at revision 0, the row is locked until the
+// end of the transaction so it cannot change before it is written
+func (db *Database) checkRevision(ctx context.Context, table, column, val string, revision int64) error {
+	if revision == 0 {
+		return nil
+	}
+
+	var stored []int64
+
+	err := db.conn().SelectContext(ctx, &stored, "SELECT revision FROM `"+table+"` WHERE "+column+"=? FOR UPDATE", val)
+	if err != nil {
+		return err
+	} else if len(stored) == 0 {
+		stored = append(stored, 0)
+	}
+
+	if stored[0] != revision {
+		return fmt.Errorf("%v %v %w (read at revision %v, stored at revision %v)", table, val,
+			datastructs.ErrRevisionConflict, revision, stored[0])
+	}
+
+	return nil
 }
 
 // Hosts
@@ -58,9 +112,18 @@ func (db *Database) Close() (err error) {
 // SelectHost return host information. The function will search for the host in the following order:
 // By hostname, if hostname is empty by host and if both hostname and host are empty by id
 func (db *Database) SelectHost(hostname string) (returnedHost datastructs.Host, err error) {
+	return db.SelectHostContext(context.Background(), hostname)
+}
+
+// SelectHostContext is SelectHost with a context bounding the query
+func (db *Database) SelectHostContext(ctx context.Context, hostname string) (returnedHost datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
 	if len(hostname) != 0 {
-		err = db.Conn.Get(&returnedHost, "SELECT host_id, host, hostname, domain, variables,"+
-			" enabled, monitored, direct_group, inherited_groups FROM host_view WHERE hostname=?", hostname)
+		err = db.conn().GetContext(ctx, &returnedHost, "SELECT host_id, host, hostname, domain, variables,"+
+			" enabled, monitored, direct_group, inherited_groups, created, updated, revision FROM host_view"+
+			" WHERE hostname=?", hostname)
 		if errors.Is(err, sql.ErrNoRows) {
 			return returnedHost, nil
 		} else if err != nil {
@@ -75,8 +138,16 @@ func (db *Database) SelectHost(hostname string) (returnedHost datastructs.Host,
 
 // GetHosts return all hosts in the inventory
 func (db *Database) GetHosts() (hosts []datastructs.Host, err error) {
-	rows, err := db.Conn.Query("SELECT host_id, host, hostname, domain, variables, enabled," +
-		" monitored, direct_group, inherited_groups FROM host_view")
+	return db.GetHostsContext(context.Background())
+}
+
+// GetHostsContext is GetHosts with a context bounding the query
+func (db *Database) GetHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view")
 	if errors.Is(err, sql.ErrNoRows) {
 		return hosts, nil
 	} else if err != nil {
@@ -88,7 +159,8 @@ func (db *Database) GetHosts() (hosts []datastructs.Host, err error) {
 	for rows.Next() {
 		host := new(datastructs.Host)
 		if err = rows.Scan(&host.ID, &host.Host, &host.Hostname, &host.Domain, &host.Variables, &host.Enabled,
-			&host.Monitored, &host.DirectGroup, &host.InheritedGroups); err != nil {
+			&host.Monitored, &host.DirectGroups, &host.InheritedGroups, &host.Created, &host.Updated,
+			&host.Revision); err != nil {
 			return hosts, err
 		}
 
@@ -100,11 +172,44 @@ func (db *Database) GetHosts() (hosts []datastructs.Host, err error) {
 
 // InsertHost accept Host to insert or update and return the number of affected rows and error if exists
 func (db *Database) InsertHost(host *datastructs.Host) (affected int64, err error) {
-	sql := `INSERT INTO host (host, hostname, domain, variables, enabled, monitored) VALUES (?,?,?,?,?,?) 
-	ON DUPLICATE KEY UPDATE host=?, hostname=?, domain=?, variables=?, enabled=?, monitored=?`
+	return db.InsertHostContext(context.Background(), host)
+}
+
+// InsertHostContext is InsertHost with a context bounding the query
+func (db *Database) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
 
-	res, err := db.Conn.Exec(sql, host.Host, host.Hostname, host.Domain, host.Variables, host.Enabled, host.Monitored,
-		host.Host, host.Hostname, host.Domain, host.Variables, host.Enabled, host.Monitored)
+	if err = db.inTrash(ctx, "host", "hostname", host.Hostname); err != nil {
+		return 0, err
+	}
+
+	// a host in the trash keeps its IP
+	if err = db.inTrash(ctx, "host", "host", host.Host); err != nil {
+		return 0, err
+	}
+
+	if err = db.checkRevision(ctx, "host", "hostname", host.Hostname, host.Revision); err != nil {
+		return 0, err
+	}
+
+	now := timestamp()
+
+	// updated and revision are kept when the host did not change, they must be set before the other columns are
+	// updated
+	sql := `INSERT INTO host (host, hostname, domain, variables, enabled, monitored, created, updated)
+	VALUES (?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE
+	revision=IF((host, domain, variables, enabled, monitored) <=>
+		(VALUES(host), VALUES(domain), VALUES(variables), VALUES(enabled), VALUES(monitored)),
+		revision, revision+1),
+	updated=IF((host, domain, variables, enabled, monitored) <=>
+		(VALUES(host), VALUES(domain), VALUES(variables), VALUES(enabled), VALUES(monitored)),
+		updated, VALUES(updated)),
+	host=VALUES(host), hostname=VALUES(hostname), domain=VALUES(domain), variables=VALUES(variables),
+	enabled=VALUES(enabled), monitored=VALUES(monitored)`
+
+	res, err := db.conn().ExecContext(ctx, sql, host.Host, host.Hostname, host.Domain, host.Variables,
+		host.Enabled, host.Monitored, now, now)
 	if err != nil {
 		return 0, err
 	}
@@ -114,9 +219,17 @@ func (db *Database) InsertHost(host *datastructs.Host) (affected int64, err erro
 	return affected, err
 }
 
-// DeleteHost accept Host to delete and return the number of affected rows and error if exists
+// DeleteHost accept Host to move to the trash and return the number of affected rows and error if exists
 func (db *Database) DeleteHost(host *datastructs.Host) (affected int64, err error) {
-	res, err := db.Conn.Exec("DELETE FROM host WHERE id=?", host.ID)
+	return db.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext is DeleteHost with a context bounding the query
+func (db *Database) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "UPDATE host SET deleted=? WHERE id=? AND deleted=''", timestamp(), host.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -128,8 +241,20 @@ func (db *Database) DeleteHost(host *datastructs.Host) (affected int64, err erro
 
 // ScanHosts get hosts where hostname or IP is like requested string
 func (db *Database) ScanHosts(val string) (hosts []datastructs.Host, err error) {
-	rows, err := db.Conn.Query("Select host_id, host, hostname, domain, variables, enabled,"+
-		" monitored, direct_group, inherited_groups FROM host_view WHERE hostname"+
+	return db.ScanHostsContext(context.Background(), val)
+}
+
+// ScanHostsContext is ScanHosts with a context bounding the query
+func (db *Database) ScanHostsContext(ctx context.Context, val string) (hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return hosts, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "Select host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view WHERE hostname"+
 		" LIKE ? OR host LIKE ?;", "%"+val+"%", "%"+val+"%")
 	if errors.Is(err, sql.ErrNoRows) {
 		return hosts, nil
@@ -142,7 +267,8 @@ func (db *Database) ScanHosts(val string) (hosts []datastructs.Host, err error)
 	for rows.Next() {
 		host := new(datastructs.Host)
 		if err = rows.Scan(&host.ID, &host.Host, &host.Hostname, &host.Domain, &host.Variables,
-			&host.Enabled, &host.Monitored, &host.DirectGroup, &host.InheritedGroups); err != nil {
+			&host.Enabled, &host.Monitored, &host.DirectGroups, &host.InheritedGroups, &host.Created, &host.Updated,
+			&host.Revision); err != nil {
 			return hosts, err
 		}
 
@@ -157,9 +283,18 @@ func (db *Database) ScanHosts(val string) (hosts []datastructs.Host, err error)
 // SelectGroup return group information. The function will search for the group in the following order:
 // By name, if name is empty by id
 func (db *Database) SelectGroup(name string) (returnedGroup datastructs.Group, err error) {
+	return db.SelectGroupContext(context.Background(), name)
+}
+
+// SelectGroupContext is SelectGroup with a context bounding the query
+func (db *Database) SelectGroupContext(ctx context.Context, name string) (returnedGroup datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
 	if len(name) != 0 {
-		err = db.Conn.Get(&returnedGroup, "SELECT group_id, name, variables, enabled,"+
-			" monitored, num_children, num_hosts, child_groups FROM `groups_view` WHERE name=?", name)
+		err = db.conn().GetContext(ctx, &returnedGroup, "SELECT group_id, name, variables, enabled,"+
+			" monitored, num_children, num_hosts, child_groups, created, updated, revision FROM `groups_view`"+
+			" WHERE name=?", name)
 		if errors.Is(err, sql.ErrNoRows) {
 			return returnedGroup, nil
 		} else if err != nil {
@@ -174,8 +309,16 @@ func (db *Database) SelectGroup(name string) (returnedGroup datastructs.Group, e
 
 // GetGroups return all groups in the inventory
 func (db *Database) GetGroups() (groups []datastructs.Group, err error) {
-	rows, err := db.Conn.Query("SELECT group_id, name, variables, enabled, monitored," +
-		" num_children, num_hosts, child_groups FROM `groups_view`")
+	return db.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is GetGroups with a context bounding the query
+func (db *Database) GetGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT group_id, name, variables, enabled, monitored,"+
+		" num_children, num_hosts, child_groups, created, updated, revision FROM `groups_view`")
 	if errors.Is(err, sql.ErrNoRows) {
 		return groups, nil
 	} else if err != nil {
@@ -187,7 +330,8 @@ func (db *Database) GetGroups() (groups []datastructs.Group, err error) {
 	for rows.Next() {
 		group := new(datastructs.Group)
 		if err = rows.Scan(&group.ID, &group.Name, &group.Variables, &group.Enabled, &group.Monitored,
-			&group.NumChildren, &group.NumHosts, &group.ChildGroups); err != nil {
+			&group.NumChildren, &group.NumHosts, &group.ChildGroups, &group.Created, &group.Updated,
+			&group.Revision); err != nil {
 			return groups, err
 		}
 
@@ -199,11 +343,35 @@ func (db *Database) GetGroups() (groups []datastructs.Group, err error) {
 
 // InsertGroup accept Group to insert or update and return the number of affected rows and error if exists
 func (db *Database) InsertGroup(group *datastructs.Group) (affected int64, err error) {
-	sql := "INSERT INTO `group` (name, variables, enabled, monitored) VALUES (?,?,?,?)" +
-		" ON DUPLICATE KEY UPDATE variables=?, enabled=?, monitored=?"
+	return db.InsertGroupContext(context.Background(), group)
+}
+
+// InsertGroupContext is InsertGroup with a context bounding the query
+func (db *Database) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = db.inTrash(ctx, "group", "name", group.Name); err != nil {
+		return 0, err
+	}
+
+	if err = db.checkRevision(ctx, "group", "name", group.Name, group.Revision); err != nil {
+		return 0, err
+	}
+
+	now := timestamp()
+
+	// updated and revision are kept when the group did not change, they must be set before the other columns are
+	// updated
+	sql := "INSERT INTO `group` (name, variables, enabled, monitored, created, updated) VALUES (?,?,?,?,?,?)" +
+		" ON DUPLICATE KEY UPDATE revision=IF((variables, enabled, monitored) <=>" +
+		" (VALUES(variables), VALUES(enabled), VALUES(monitored)), revision, revision+1)," +
+		" updated=IF((variables, enabled, monitored) <=>" +
+		" (VALUES(variables), VALUES(enabled), VALUES(monitored)), updated, VALUES(updated))," +
+		" variables=VALUES(variables), enabled=VALUES(enabled), monitored=VALUES(monitored)"
 
-	res, err := db.Conn.Exec(sql, group.Name, group.Variables, group.Enabled,
-		group.Monitored, group.Variables, group.Enabled, group.Monitored)
+	res, err := db.conn().ExecContext(ctx, sql, group.Name, group.Variables, group.Enabled,
+		group.Monitored, now, now)
 	if err != nil {
 		return 0, err
 	}
@@ -213,9 +381,18 @@ func (db *Database) InsertGroup(group *datastructs.Group) (affected int64, err e
 	return affected, err
 }
 
-// DeleteGroup accept Group to delete and return the number of affected rows and error if exists
+// DeleteGroup accept Group to move to the trash and return the number of affected rows and error if exists
 func (db *Database) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
-	res, err := db.Conn.Exec("DELETE FROM `group` WHERE id=?", group.ID)
+	return db.DeleteGroupContext(context.Background(), group)
+}
+
+// DeleteGroupContext is DeleteGroup with a context bounding the query
+func (db *Database) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "UPDATE `group` SET deleted=? WHERE id=? AND deleted=''", timestamp(),
+		group.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -227,8 +404,21 @@ func (db *Database) DeleteGroup(group *datastructs.Group) (affected int64, err e
 
 // ScanGroups get group where group name in like requested string
 func (db *Database) ScanGroups(val string) (groups []datastructs.Group, err error) {
-	rows, err := db.Conn.Query("SELECT group_id, name, variables, enabled, monitored, num_children,"+
-		" num_hosts FROM `groups_view` WHERE name LIKE ?;", "%"+val+"%")
+	return db.ScanGroupsContext(context.Background(), val)
+}
+
+// ScanGroupsContext is ScanGroups with a context bounding the query
+func (db *Database) ScanGroupsContext(ctx context.Context, val string) (groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return groups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT group_id, name, variables, enabled, monitored, num_children,"+
+		" num_hosts, child_groups, created, updated, revision FROM `groups_view` WHERE name LIKE ?;",
+		"%"+val+"%")
 	if errors.Is(err, sql.ErrNoRows) {
 		return groups, nil
 	} else if err != nil {
@@ -240,7 +430,9 @@ func (db *Database) ScanGroups(val string) (groups []datastructs.Group, err erro
 	for rows.Next() {
 		group := new(datastructs.Group)
 		if err = rows.Scan(&group.ID, &group.Name, &group.Variables, &group.Enabled,
-			&group.Monitored, &group.NumChildren, &group.NumHosts); err != nil {
+			&group.Monitored, &group.NumChildren, &group.NumHosts, &group.ChildGroups,
+			&group.Created, &group.Updated,
+			&group.Revision); err != nil {
 			return groups, err
 		}
 
@@ -257,10 +449,19 @@ func (db *Database) ScanGroups(val string) (groups []datastructs.Group, err erro
 // If parent is provided will return slice of child ids
 // will error if none is provided
 func (db *Database) SelectChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error) {
+	return db.SelectChildGroupContext(context.Background(), child, parent)
+}
+
+// SelectChildGroupContext is SelectChildGroup with a context bounding the query
+func (db *Database) SelectChildGroupContext(ctx context.Context, child, parent string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
 	if child != "" && parent != "" {
 		var rows *sql.Rows
 
-		rows, err = db.Conn.Query("SELECT relationship_id,parent, parent_id, child, child_id"+
+		rows, err = db.conn().QueryContext(ctx, "SELECT relationship_id,parent, parent_id, child, child_id"+
 			" FROM childgroups_view WHERE parent=? AND child=?", parent, child)
 		if errors.Is(err, sql.ErrNoRows) {
 			return childGroups, nil
@@ -288,7 +489,16 @@ func (db *Database) SelectChildGroup(child, parent string) (childGroups []datast
 
 // GetChildGroups return all child groups relationships in the inventory
 func (db *Database) GetChildGroups() (childGroups []datastructs.ChildGroup, err error) {
-	rows, err := db.Conn.Query("SELECT relationship_id,parent, parent_id, child, child_id FROM childgroups_view")
+	return db.GetChildGroupsContext(context.Background())
+}
+
+// GetChildGroupsContext is GetChildGroups with a context bounding the query
+func (db *Database) GetChildGroupsContext(ctx context.Context) (childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx,
+		"SELECT relationship_id,parent, parent_id, child, child_id FROM childgroups_view")
 	if errors.Is(err, sql.ErrNoRows) {
 		return childGroups, nil
 	} else if err != nil {
@@ -312,9 +522,18 @@ func (db *Database) GetChildGroups() (childGroups []datastructs.ChildGroup, err
 
 // InsertChildGroup accept ChildGroup to insert and return the number of affected rows and error if exists
 func (db *Database) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+// InsertChildGroupContext is InsertChildGroup with a context bounding the query
+func (db *Database) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
 	sql := `INSERT INTO childgroups (child_id, parent_id) VALUES (?,?)`
 
-	res, err := db.Conn.Exec(sql, childGroup.ChildID, childGroup.ParentID)
+	res, err := db.conn().ExecContext(ctx, sql, childGroup.ChildID, childGroup.ParentID)
 	if err != nil {
 		return 0, err
 	}
@@ -326,7 +545,16 @@ func (db *Database) InsertChildGroup(childGroup *datastructs.ChildGroup) (affect
 
 // DeleteChildGroup accept ChildGroup to delete and return the number of affected rows and error if exists
 func (db *Database) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
-	res, err := db.Conn.Exec("DELETE FROM childgroups WHERE child_id=? and parent_id=?",
+	return db.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+// DeleteChildGroupContext is DeleteChildGroup with a context bounding the query
+func (db *Database) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM childgroups WHERE child_id=? and parent_id=?",
 		childGroup.ChildID, childGroup.ParentID)
 	if err != nil {
 		return 0, err
@@ -339,7 +567,20 @@ func (db *Database) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affect
 
 // ScanChildGroups get child-group relationships where parent or child is like requested string
 func (db *Database) ScanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error) {
-	rows, err := db.Conn.Query("SELECT relationship_id,parent, parent_id, child, child_id FROM"+
+	return db.ScanChildGroupsContext(context.Background(), val)
+}
+
+// ScanChildGroupsContext is ScanChildGroups with a context bounding the query
+func (db *Database) ScanChildGroupsContext(ctx context.Context, val string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return childGroups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT relationship_id,parent, parent_id, child, child_id FROM"+
 		" childgroups_view WHERE parent LIKE ? OR child LIKE ?;", "%"+val+"%", "%"+val+"%")
 	if errors.Is(err, sql.ErrNoRows) {
 		return childGroups, nil
@@ -369,8 +610,17 @@ func (db *Database) ScanChildGroups(val string) (childGroups []datastructs.Child
 // If group is provided will return slice of hosts ids
 // will error if none is provided
 func (db *Database) SelectHostGroup(host string) (hostGroups []datastructs.HostGroup, err error) {
+	return db.SelectHostGroupContext(context.Background(), host)
+}
+
+// SelectHostGroupContext is SelectHostGroup with a context bounding the query
+func (db *Database) SelectHostGroupContext(ctx context.Context, host string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
 	if host != "" {
-		rows, err := db.Conn.Query("SELECT relationship_id, `group`, group_id,"+
+		rows, err := db.conn().QueryContext(ctx, "SELECT relationship_id, `group`, group_id,"+
 			" host, host_id FROM hostgroup_view WHERE host=?", host)
 		if errors.Is(err, sql.ErrNoRows) {
 			return hostGroups, nil
@@ -398,7 +648,16 @@ func (db *Database) SelectHostGroup(host string) (hostGroups []datastructs.HostG
 
 // GetHostGroups return all host groups relationships in the inventory
 func (db *Database) GetHostGroups() (hostGroups []datastructs.HostGroup, err error) {
-	rows, err := db.Conn.Query("SELECT relationship_id, `group`, group_id, host, host_id FROM hostgroup_view")
+	return db.GetHostGroupsContext(context.Background())
+}
+
+// GetHostGroupsContext is GetHostGroups with a context bounding the query
+func (db *Database) GetHostGroupsContext(ctx context.Context) (hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx,
+		"SELECT relationship_id, `group`, group_id, host, host_id FROM hostgroup_view")
 	if errors.Is(err, sql.ErrNoRows) {
 		return hostGroups, nil
 	} else if err != nil {
@@ -420,35 +679,74 @@ func (db *Database) GetHostGroups() (hostGroups []datastructs.HostGroup, err err
 	return hostGroups, nil
 }
 
-// InsertHostGroup accept HostGroup to insert and return the number of affected rows and error if exists
+// InsertHostGroup accept HostGroup to add the host to the group, keeping its other group memberships,
+// and return the number of affected rows and error if exists
 func (db *Database) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
-	sql := `INSERT INTO hostgroups (host_id, group_id) VALUES (?,?)`
+	return db.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+// InsertHostGroupContext is InsertHostGroup with a context bounding the query
+func (db *Database) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
 
-	res, err := db.Conn.Exec(sql, hostGroup.HostID, hostGroup.GroupID)
+	sql := `INSERT INTO hostgroups (host_id, group_id) VALUES (?,?) ON DUPLICATE KEY UPDATE id=id`
+
+	res, err := db.conn().ExecContext(ctx, sql, hostGroup.HostID, hostGroup.GroupID)
 	if err != nil {
 		return 0, err
 	}
 
 	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
 
-	return affected, err
+	return affected, db.bumpHostRevision(ctx, hostGroup.HostID)
 }
 
 // DeleteHostGroup accept HostGroup to delete and return the number of affected rows and error if exists
 func (db *Database) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
-	res, err := db.Conn.Exec("DELETE FROM hostgroups WHERE host_id=? and group_id=?", hostGroup.HostID, hostGroup.GroupID)
+	return db.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+// DeleteHostGroupContext is DeleteHostGroup with a context bounding the query
+func (db *Database) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM hostgroups WHERE host_id=? and group_id=?",
+		hostGroup.HostID, hostGroup.GroupID)
 	if err != nil {
 		return 0, err
 	}
 
 	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
 
-	return affected, err
+	return affected, db.bumpHostRevision(ctx, hostGroup.HostID)
 }
 
 // ScanHostGroups get host-groups where group is like requested string
 func (db *Database) ScanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error) {
-	rows, err := db.Conn.Query("Select relationship_id, host_id, host, group_id,"+
+	return db.ScanHostGroupsContext(context.Background(), val)
+}
+
+// ScanHostGroupsContext is ScanHostGroups with a context bounding the query
+func (db *Database) ScanHostGroupsContext(ctx context.Context, val string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return hostGroups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "Select relationship_id, host_id, host, group_id,"+
 		" `group` FROM hostgroup_view WHERE `group` LIKE ?", "%"+val+"%")
 	if errors.Is(err, sql.ErrNoRows) {
 		return hostGroups, nil
@@ -470,33 +768,45 @@ func (db *Database) ScanHostGroups(val string) (hostGroups []datastructs.HostGro
 	return hostGroups, nil
 }
 
-// PopulateTestData populate test database for internal testing
-// TODO: MariaDB is not tests, create a docker based tests
+// PopulateTestData populate test database for internal testing, the database is expected to be migrated
 // nolint
 func (db *Database) PopulateTestData(fixturesPath string) (err error) {
-	// sql, _ := ioutil.ReadFile("../../../fixtures/sqlite/01_scheme.sql")
-	// sqlfileE := base64.StdEncoding.EncodeToString(sql)
-	// sqlfileD, _ := base64.StdEncoding.DecodeString(sqlfileE)
-	// queries := strings.Split(string(sqlfileD), ";\n\n")
-
-	// for _, query := range queries[0:] {
-	// 	_, err = db.Conn.Exec(query)
-	// 	if err != nil {
-	// 		return err
-	// 	}
-	// }
-
-	// sql, _ = ioutil.ReadFile("../../../fixtures/02_test_data.sql")
-	// sqlfileE = base64.StdEncoding.EncodeToString(sql)
-	// sqlfileD, _ = base64.StdEncoding.DecodeString(sqlfileE)
-	// queries = strings.Split(string(sqlfileD), ";\n\n")
-
-	// for _, query := range queries[1:] {
-	// 	_, err = db.Conn.Exec(query)
-	// 	if err != nil {
-	// 		return err
-	// 	}
-	// }
+	sql, err := ioutil.ReadFile(fixturesPath + "/02_test_data.sql")
+	if err != nil {
+		return err
+	}
+
+	// the driver runs a single statement per call, the fixture is split into its statements
+	for _, query := range fixtureStatements(string(sql)) {
+		// skip the `USE ansible` statement, the data is loaded into the configured database
+		if strings.HasPrefix(query, "USE ") {
+			continue
+		}
+
+		_, err = db.Conn.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
 
 	return err
 }
+
+// fixtureStatements split the fixture into its statements without the comment lines
+func fixtureStatements(sql string) (statements []string) {
+	for _, chunk := range strings.Split(sql, ";\n") {
+		var lines []string
+
+		for _, line := range strings.Split(chunk, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+
+		if len(lines) > 0 {
+			statements = append(statements, strings.TrimSuffix(strings.Join(lines, "\n"), ";"))
+		}
+	}
+
+	return statements
+}
@@ -0,0 +1,48 @@
+// nolint
+package mariadb
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_fixtureStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "statements and comments",
+			sql:  "USE ansible;\n\n-- clean\nDELETE FROM `a`;\nDELETE FROM `b`;\n\n-- fill\nINSERT INTO `a` VALUES (1);\n",
+			want: []string{"USE ansible", "DELETE FROM `a`", "DELETE FROM `b`", "INSERT INTO `a` VALUES (1)"},
+		},
+		{
+			name: "comments only",
+			sql:  "-- nothing\n\n-- to run\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fixtureStatements(tt.sql); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fixtureStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fixtureStatements_testData(t *testing.T) {
+	sql, err := ioutil.ReadFile("../../../fixtures/02_test_data.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, statement := range fixtureStatements(string(sql)) {
+		if strings.Contains(statement, ";") || strings.Contains(statement, "--") {
+			t.Errorf("fixtureStatements() statement %q is not a single statement", statement)
+		}
+	}
+}
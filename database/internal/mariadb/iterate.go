@@ -0,0 +1,102 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// IterateHosts call fn with every host ordered by hostname, within page. The hosts are read while fn run, fn must
+// not query the database. The iteration stops at the first error returned by fn
+func (db *Database) IterateHosts(page datastructs.Page, fn func(host *datastructs.Host) error) error {
+	return db.IterateHostsContext(context.Background(), page, fn)
+}
+
+// IterateHostsContext is IterateHosts with a context bounding the query
+func (db *Database) IterateHostsContext(ctx context.Context, page datastructs.Page,
+	fn func(host *datastructs.Host) error) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view ORDER BY hostname"+
+		limit(page))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		host := new(datastructs.Host)
+		if err = rows.Scan(&host.ID, &host.Host, &host.Hostname, &host.Domain, &host.Variables, &host.Enabled,
+			&host.Monitored, &host.DirectGroups, &host.InheritedGroups, &host.Created, &host.Updated,
+			&host.Revision); err != nil {
+			return err
+		}
+
+		if err = fn(host); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// IterateGroups call fn with every group ordered by name, within page. The groups are read while fn run, fn must
+// not query the database. The iteration stops at the first error returned by fn
+func (db *Database) IterateGroups(page datastructs.Page, fn func(group *datastructs.Group) error) error {
+	return db.IterateGroupsContext(context.Background(), page, fn)
+}
+
+// IterateGroupsContext is IterateGroups with a context bounding the query
+func (db *Database) IterateGroupsContext(ctx context.Context, page datastructs.Page,
+	fn func(group *datastructs.Group) error) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT group_id, name, variables, enabled, monitored,"+
+		" num_children, num_hosts, child_groups, created, updated, revision FROM `groups_view` ORDER BY name"+
+		limit(page))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		group := new(datastructs.Group)
+		if err = rows.Scan(&group.ID, &group.Name, &group.Variables, &group.Enabled, &group.Monitored,
+			&group.NumChildren, &group.NumHosts, &group.ChildGroups, &group.Created, &group.Updated,
+			&group.Revision); err != nil {
+			return err
+		}
+
+		if err = fn(group); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// limit return the LIMIT clause of page, an offset without limit need the largest limit
+func limit(page datastructs.Page) string {
+	switch {
+	case page.Limit > 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", page.Limit, page.Offset)
+	case page.Offset > 0:
+		return fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", page.Offset)
+	}
+
+	return ""
+}
@@ -1,8 +1,4 @@
-CREATE DATABASE IF NOT EXISTS ansible;
-
-USE ansible;
-
-CREATE TABLE `group` (
+CREATE TABLE IF NOT EXISTS `group` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `name` varchar(255) NOT NULL,
   `variables` varchar(8192) NOT NULL DEFAULT '{}',
@@ -14,7 +10,7 @@ CREATE TABLE `group` (
   UNIQUE KEY `group_name` (`name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 
-CREATE TABLE `childgroups` (
+CREATE TABLE IF NOT EXISTS `childgroups` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `child_id` int(11) NOT NULL,
   `parent_id` int(11) NOT NULL,
@@ -26,7 +22,7 @@ CREATE TABLE `childgroups` (
   CONSTRAINT `childgroups_ibfk_3` FOREIGN KEY (`child_id`) REFERENCES `group` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-CREATE TABLE `host` (
+CREATE TABLE IF NOT EXISTS `host` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `host` varchar(255) NOT NULL,
   `hostname` varchar(255) NOT NULL,
@@ -41,7 +37,7 @@ CREATE TABLE `host` (
   UNIQUE KEY `host_hostname` (`hostname`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 
-CREATE TABLE `hostgroups` (
+CREATE TABLE IF NOT EXISTS `hostgroups` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `host_id` int(11) NOT NULL,
   `group_id` int(11) NOT NULL,
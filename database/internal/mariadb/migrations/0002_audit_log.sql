@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `changed_at` varchar(32) NOT NULL,
+  `actor` varchar(255) NOT NULL,
+  `entity` varchar(32) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `related` varchar(255) NOT NULL DEFAULT '',
+  `action` varchar(16) NOT NULL,
+  `before_state` longtext NOT NULL DEFAULT '',
+  `after_state` longtext NOT NULL DEFAULT '',
+  `cause` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `audit_log_name` (`name`),
+  KEY `audit_log_related` (`related`),
+  KEY `audit_log_cause` (`cause`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
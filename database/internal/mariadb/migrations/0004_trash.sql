@@ -0,0 +1,120 @@
+-- deleted hosts and groups are kept in the trash until purged, the views hide them and their relationships
+ALTER TABLE `host` ADD COLUMN `deleted` varchar(32) NOT NULL DEFAULT '';
+
+ALTER TABLE `group` ADD COLUMN `deleted` varchar(32) NOT NULL DEFAULT '';
+
+CREATE OR REPLACE
+ALGORITHM = UNDEFINED VIEW `hostgroup_view` AS
+SELECT
+    `hostgroups`.`id` AS `relationship_id`,
+    `host`.`hostname` AS `host`,
+    `hostgroups`.`host_id` AS `host_id`,
+    `group`.`name` AS `group`,
+    `hostgroups`.`group_id` AS `group_id`
+FROM `hostgroups`
+LEFT JOIN `group`
+    ON `hostgroups`.`group_id` = `group`.`id`
+LEFT JOIN `host`
+    ON `hostgroups`.`host_id` = `host`.`id`
+WHERE `host`.`deleted` = '' AND `group`.`deleted` = ''
+ORDER BY `group`.`name`;
+
+CREATE OR REPLACE
+ALGORITHM = UNDEFINED VIEW `childgroups_view` AS
+SELECT
+    `childgroups`.`id` AS `relationship_id`,
+    `gparent`.`name` AS `parent`,
+    `gparent`.`id` AS `parent_id`,
+    `gchild`.`name` AS `child`,
+    `gchild`.`id` AS `child_id`
+FROM `childgroups`
+LEFT JOIN `group` `gparent`
+	ON `childgroups`.`parent_id` = `gparent`.`id`
+LEFT JOIN `group` `gchild`
+	ON `childgroups`.`child_id` = `gchild`.`id`
+WHERE `gparent`.`deleted` = '' AND `gchild`.`deleted` = ''
+ORDER BY `gparent`.`name`;
+
+CREATE OR REPLACE
+ALGORITHM = UNDEFINED VIEW `host_view` AS
+WITH RECURSIVE inherited (child_id, parent_id) AS (
+SELECT
+	child_id,
+	parent_id
+from
+	childgroups_view cv
+UNION ALL
+SELECT
+	cv.child_id,
+	i.parent_id
+FROM
+	inherited i
+JOIN childgroups_view cv ON
+	i.child_id = cv.parent_id )
+SELECT
+	`host`.`id` AS `host_id`,
+    `host`.`hostname` AS `hostname`,
+    `host`.`domain` AS `domain`,
+    `host`.`host` AS `host`,
+    `host`.`enabled` AS `enabled`,
+    `host`.`monitored` AS `monitored`,
+    `host`.`variables` AS `variables`,
+    `host`.`created` AS `created`,
+    `host`.`updated` AS `updated`,
+    ifnull(group_concat(distinct `g1`.`name` separator ','),"") AS `direct_group`,
+    ifnull(group_concat(distinct `g2`.`name` separator ','),"") AS `inherited_groups`
+FROM
+	host
+LEFT JOIN hostgroup_view hv ON
+	host.id = hv.host_id
+LEFT JOIN inherited i ON
+	hv.group_id = i.child_id
+LEFT JOIN `group` g1 ON
+	hv.group_id = g1.id
+LEFT JOIN `group` g2 ON
+	i.parent_id = g2.id
+WHERE
+	host.deleted = ''
+GROUP BY
+	host.hostname
+ORDER BY
+	host.hostname;
+
+CREATE OR REPLACE
+ALGORITHM = UNDEFINED VIEW `groups_view` AS 
+WITH RECURSIVE inherited(`child_id`, `parent_id`) AS (
+SELECT
+    `cv`.`child_id` AS `child_id`,
+    `cv`.`parent_id` AS `parent_id`
+FROM
+    `childgroups_view` `cv`
+UNION ALL
+SELECT
+    `cv`.`child_id` AS `child_id`,
+    `i`.`parent_id` AS `parent_id`
+FROM
+    `inherited` `i`
+JOIN `childgroups_view` `cv` ON `i`.`child_id` = `cv`.`parent_id`)
+SELECT 
+	`g1`.`id` AS `group_id`,
+    `g1`.`name` AS `name`,
+    `g1`.`enabled` AS `enabled`,
+    `g1`.`monitored` AS `monitored`,
+	COUNT(DISTINCT h.id) AS `num_hosts`,
+	COUNT(DISTINCT `g2`.`name`) AS `num_children`,
+	IFNULL(GROUP_CONCAT(DISTINCT `g2`.`name` SEPARATOR ','),'') AS `child_groups`,
+	`g1`.`variables` AS `variables`,
+	`g1`.`created` AS `created`,
+	`g1`.`updated` AS `updated`
+FROM `group` g1
+LEFT JOIN `inherited` `i` ON
+     `g1`.`id` = `i`.`parent_id`
+LEFT JOIN `group` `g2` ON
+     `i`.`child_id` = `g2`.`id`
+LEFT JOIN hostgroups hg ON
+	`hg`.`group_id` = `g1`.`id` 
+LEFT JOIN host h ON
+	`h`.`id` = `hg`.`host_id` AND `h`.`deleted` = ''
+WHERE `g1`.`deleted` = ''
+GROUP BY `g1`.`id` 
+ORDER BY `g1`.`id`;
@@ -0,0 +1,26 @@
+-- a host can have multiple named addresses (e.g. management, storage, public), at most one of them primary
+CREATE TABLE IF NOT EXISTS `addresses` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `host_id` int(11) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `address` varchar(255) NOT NULL,
+  `is_primary` tinyint(1) NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `addresses_host_id_name` (`host_id`,`name`),
+  CONSTRAINT `addresses_ibfk_1` FOREIGN KEY (`host_id`) REFERENCES `host` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+CREATE OR REPLACE
+ALGORITHM = UNDEFINED VIEW `address_view` AS
+SELECT
+    `addresses`.`id` AS `address_id`,
+    `addresses`.`host_id` AS `host_id`,
+    `host`.`hostname` AS `host`,
+    `addresses`.`name` AS `name`,
+    `addresses`.`address` AS `address`,
+    `addresses`.`is_primary` AS `is_primary`
+FROM `addresses`
+JOIN `host`
+    ON `addresses`.`host_id` = `host`.`id`
+WHERE `host`.`deleted` = ''
+ORDER BY `host`.`hostname`, `addresses`.`name`;
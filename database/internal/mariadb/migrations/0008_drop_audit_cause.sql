@@ -0,0 +1,4 @@
+-- the audit log no longer records the change causing a relationship change
+ALTER TABLE `audit_log` DROP INDEX `audit_log_cause`;
+
+ALTER TABLE `audit_log` DROP COLUMN `cause`;
@@ -0,0 +1,237 @@
+package mariadb
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/via-justa/admiral/config"
+)
+
+// tlsConfigName is the name the TLS configuration is registered with to the mysql driver
+const tlsConfigName = "admiral"
+
+// optionGroups are the option file groups read, later groups override earlier ones
+var optionGroups = []string{"client", "client-mariadb"}
+
+// expandHome return path with a leading ~/ replaced by the user home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path, err
+	}
+
+	return filepath.Join(home, path[2:]), nil
+}
+
+// readOptionFile return the options of the client groups of a MySQL option file, `-` and `_` in option names are
+// the same and are returned as `-`
+func readOptionFile(path string) (map[string]string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	options := make(map[string]string)
+	rank := make(map[string]int)
+	group := -1
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "", line[0] == '#', line[0] == ';', line[0] == '!':
+			// comments and !include directives
+			continue
+		case line[0] == '[':
+			group = -1
+
+			for i, name := range optionGroups {
+				if strings.EqualFold(strings.Trim(line, "[] \t"), name) {
+					group = i
+				}
+			}
+
+			continue
+		case group < 0:
+			continue
+		}
+
+		name, value := line, ""
+		if i := strings.IndexByte(line, '='); i >= 0 {
+			name, value = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+		if r, ok := rank[name]; !ok || group >= r {
+			options[name], rank[name] = value, group
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// withOptionFile return conf with the settings it leaves empty set from its option file
+func withOptionFile(conf config.MariaDBConfig) (config.MariaDBConfig, error) {
+	if conf.OptionFile == "" {
+		return conf, nil
+	}
+
+	options, err := readOptionFile(conf.OptionFile)
+	if err != nil {
+		return conf, fmt.Errorf("option file: %w", err)
+	}
+
+	for _, s := range []struct {
+		option  string
+		setting *string
+	}{
+		{"user", &conf.User},
+		{"password", &conf.Password},
+		{"host", &conf.Host},
+		{"socket", &conf.Socket},
+		{"database", &conf.DB},
+		{"ssl-ca", &conf.TLS.CA},
+		{"ssl-cert", &conf.TLS.Cert},
+		{"ssl-key", &conf.TLS.Key},
+	} {
+		if *s.setting == "" {
+			*s.setting = options[s.option]
+		}
+	}
+
+	if port, ok := options["port"]; ok && conf.Port == 0 {
+		if conf.Port, err = strconv.Atoi(port); err != nil {
+			return conf, fmt.Errorf("option file port: %w", err)
+		}
+	}
+
+	return conf, nil
+}
+
+// tlsConfig return the TLS configuration of conf, nil when TLS is not configured
+func tlsConfig(conf config.MariaDBConfig) (*tls.Config, error) {
+	if (conf.TLS == config.MariaDBTLS{}) {
+		return nil, nil
+	}
+
+	c := &tls.Config{
+		ServerName:         conf.TLS.ServerName,
+		InsecureSkipVerify: conf.TLS.SkipVerify, // nolint: gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if c.ServerName == "" {
+		c.ServerName = conf.Host
+	}
+
+	if conf.TLS.CA != "" {
+		pem, err := ioutil.ReadFile(conf.TLS.CA)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca: %w", err)
+		}
+
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca: no certificate found in %v", conf.TLS.CA)
+		}
+	}
+
+	if conf.TLS.Cert != "" || conf.TLS.Key != "" {
+		cert, err := tls.LoadX509KeyPair(conf.TLS.Cert, conf.TLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("tls client certificate: %w", err)
+		}
+
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
+
+// driverConfig return the mysql driver configuration of conf. The connection is made over the socket when set,
+// otherwise over tcp, with the network name prefixed by dialer, e.g. the dialer registered for the ssh proxy
+func driverConfig(conf config.MariaDBConfig, dialer string) (*mysql.Config, error) {
+	dbConfig := mysql.Config{
+		User:                 conf.User,
+		Passwd:               conf.Password,
+		Net:                  dialer + "tcp",
+		Addr:                 net.JoinHostPort(conf.Host, fmt.Sprint(conf.Port)),
+		DBName:               conf.DB,
+		AllowNativePasswords: true,
+	}
+
+	if conf.Socket != "" {
+		dbConfig.Net, dbConfig.Addr = dialer+"unix", conf.Socket
+	}
+
+	tlsConf, err := tlsConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConf != nil {
+		if err = mysql.RegisterTLSConfig(tlsConfigName, tlsConf); err != nil {
+			return nil, err
+		}
+
+		dbConfig.TLSConfig = tlsConfigName
+	}
+
+	return &dbConfig, nil
+}
+
+// open return the connection pool of conf, the connections are made by the network prefixed by dialer
+func open(conf config.MariaDBConfig, dialer string) (*sqlx.DB, error) {
+	conf, err := withOptionFile(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConfig, err := driverConfig(conf, dialer)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sqlx.Open("mysql", dbConfig.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(conf.MaxOpenConns)
+	conn.SetConnMaxLifetime(conf.ConnMaxLifetime)
+
+	if conf.MaxIdleConns != 0 {
+		conn.SetMaxIdleConns(conf.MaxIdleConns)
+	}
+
+	return conn, nil
+}
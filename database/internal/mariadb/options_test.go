@@ -0,0 +1,183 @@
+// nolint
+package mariadb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+)
+
+func Test_withOptionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my.cnf")
+	if err := ioutil.WriteFile(path, []byte(`# admiral credentials
+[mysql]
+user = cli
+
+[client]
+user = admiral
+password = "secret # not a comment"
+host=db.local
+port = 3307
+ssl_ca = /etc/ca.pem
+skip-ssl
+
+[client-mariadb]
+socket = /run/mysqld/mysqld.sock
+!includedir /etc/mysql/conf.d/
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := withOptionFile(config.MariaDBConfig{User: "root", DB: "ansible", OptionFile: path})
+	if err != nil {
+		t.Fatalf("withOptionFile() error = %v", err)
+	}
+
+	want := config.MariaDBConfig{User: "root", Password: "secret # not a comment", Host: "db.local", Port: 3307,
+		DB: "ansible", Socket: "/run/mysqld/mysqld.sock", OptionFile: path, TLS: config.MariaDBTLS{CA: "/etc/ca.pem"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withOptionFile() = %+v, want %+v", got, want)
+	}
+
+	if _, err = withOptionFile(config.MariaDBConfig{OptionFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("withOptionFile() error = nil, want error for missing option file")
+	}
+}
+
+func Test_driverConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca, cert, key := writeTestCertificate(t, dir)
+
+	tests := []struct {
+		name     string
+		conf     config.MariaDBConfig
+		dialer   string
+		wantNet  string
+		wantAddr string
+		wantTLS  bool
+		wantErr  bool
+	}{
+		{
+			name:     "tcp",
+			conf:     config.MariaDBConfig{Host: "db.local", Port: 3306},
+			wantNet:  "tcp",
+			wantAddr: "db.local:3306",
+		},
+		{
+			name:     "socket through ssh proxy",
+			conf:     config.MariaDBConfig{Host: "db.local", Port: 3306, Socket: "/run/mysqld/mysqld.sock"},
+			dialer:   proxyDialer,
+			wantNet:  "mysql+unix",
+			wantAddr: "/run/mysqld/mysqld.sock",
+		},
+		{
+			name: "tls",
+			conf: config.MariaDBConfig{Host: "db.local", Port: 3306, TLS: config.MariaDBTLS{CA: ca, Cert: cert,
+				Key: key}},
+			wantNet:  "tcp",
+			wantAddr: "db.local:3306",
+			wantTLS:  true,
+		},
+		{
+			name:     "skip verify",
+			conf:     config.MariaDBConfig{Host: "db.local", Port: 3306, TLS: config.MariaDBTLS{SkipVerify: true}},
+			wantNet:  "tcp",
+			wantAddr: "db.local:3306",
+			wantTLS:  true,
+		},
+		{
+			name:    "ca without certificate",
+			conf:    config.MariaDBConfig{TLS: config.MariaDBTLS{CA: key}},
+			wantErr: true,
+		},
+		{
+			name:    "certificate without key",
+			conf:    config.MariaDBConfig{TLS: config.MariaDBTLS{Cert: cert}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := driverConfig(tt.conf, tt.dialer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("driverConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.Net != tt.wantNet || got.Addr != tt.wantAddr || (got.TLSConfig != "") != tt.wantTLS {
+				t.Errorf("driverConfig() = %v %v tls %q, want %v %v tls %v", got.Net, got.Addr, got.TLSConfig,
+					tt.wantNet, tt.wantAddr, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func Test_tlsConfig(t *testing.T) {
+	ca, cert, key := writeTestCertificate(t, t.TempDir())
+
+	got, err := tlsConfig(config.MariaDBConfig{Host: "db.local", TLS: config.MariaDBTLS{CA: ca, Cert: cert, Key: key}})
+	if err != nil {
+		t.Fatalf("tlsConfig() error = %v", err)
+	}
+
+	if got.ServerName != "db.local" || got.RootCAs == nil || len(got.Certificates) != 1 || got.InsecureSkipVerify {
+		t.Errorf("tlsConfig() = %+v, want verified db.local with private CA and client certificate", got)
+	}
+
+	if got, _ = tlsConfig(config.MariaDBConfig{Host: "db.local"}); got != nil {
+		t.Errorf("tlsConfig() = %+v, want nil without TLS settings", got)
+	}
+}
+
+// writeTestCertificate write a self signed certificate, used as both CA and client certificate, and its key
+func writeTestCertificate(t *testing.T, dir string) (ca, cert, key string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "admiral"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, key = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err = ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, cert, key
+}
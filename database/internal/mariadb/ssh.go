@@ -2,92 +2,415 @@ package mariadb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/via-justa/admiral/config"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
 
 	"github.com/go-sql-driver/mysql"
 )
 
+// proxyDialer prefix the networks dialed through the ssh proxy
+const proxyDialer = "mysql+"
+
+// defaultKnownHosts is the file of the trusted ssh proxy host keys when none is configured
+const defaultKnownHosts = "~/.ssh/known_hosts"
+
+// Prompter ask the user while connecting to the ssh proxy
+type Prompter interface {
+	// TrustHostKey return true when the user trust the unknown host key of the proxy, given by its type and
+	// fingerprint, the key is then added to the known hosts file
+	TrustHostKey(host, keyType, fingerprint string) bool
+	// Passphrase return the passphrase of the encrypted private key file
+	Passphrase(keyPath string) ([]byte, error)
+}
+
 type viaSSHDialer struct {
-	client *ssh.Client
+	tunnel *tunnel
+	// network is "tcp", or "unix" for sockets on the ssh server
+	network string
 }
 
 // Dial implement ssh dialer
 func (sshd *viaSSHDialer) Dial(addr string) (net.Conn, error) {
-	return sshd.client.Dial("tcp", addr)
+	client, err := sshd.tunnel.connect(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Dial(sshd.network, addr)
 }
 
-func newSSHProxy(conf config.SSHProxy) (*ssh.Client, error) {
-	var agentClient agent.Agent
+// DialContext is Dial that gives up once ctx is done, the ssh client does not accept a context
+func (sshd *viaSSHDialer) DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	type dialed struct {
+		conn net.Conn
+		err  error
+	}
 
-	// Establish a connection to the local ssh-agent
-	if conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK")); err == nil {
-		defer conn.Close() // nolint: errcheck
+	ch := make(chan dialed, 1)
+
+	go func() {
+		conn, err := sshd.Dial(addr)
+		ch <- dialed{conn, err}
+	}()
 
-		// Create a new instance of the ssh agent
-		agentClient = agent.NewClient(conn)
+	select {
+	case d := <-ch:
+		return d.conn, d.err
+	case <-ctx.Done():
+		// close the connection if the dial completes after we gave up on it
+		go func() {
+			if d := <-ch; d.conn != nil {
+				d.conn.Close() // nolint: errcheck,gosec
+			}
+		}()
+
+		return nil, ctx.Err()
 	}
+}
 
-	// The client configuration with configuration option to use the ssh-agent
-	sshConfig := &ssh.ClientConfig{
-		User:            conf.User,
-		Auth:            []ssh.AuthMethod{},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // nolint:gosec
+// tunnel is the connection to the ssh proxy. A lost connection is reopened by the next dial, the database
+// connections made through it are then reopened by the connection pool
+type tunnel struct {
+	conf    config.SSHProxy
+	timeout time.Duration
+	prompt  Prompter
+	auth    []ssh.AuthMethod
+	// agent is kept open for the life of the tunnel as it sign every authentication, including reconnects
+	agent net.Conn
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// newTunnel return a tunnel to the ssh proxy of conf, the connection is opened by the first dial. Private key
+// files are read once, prompt is asked for the passphrase of an encrypted key and to trust unknown host keys
+func newTunnel(conf config.SSHProxy, timeout time.Duration, prompt Prompter) (*tunnel, error) {
+	t := &tunnel{conf: conf, timeout: timeout, prompt: prompt}
+
+	if conf.KeyPath != "" {
+		signer, err := loadKey(conf.KeyPath, prompt)
+		if err != nil {
+			return nil, err
+		}
+
+		t.auth = append(t.auth, ssh.PublicKeys(signer))
 	}
 
-	// When the agentClient connection succeeded, add them as AuthMethod
-	if agentClient != nil {
-		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeysCallback(agentClient.Signers))
+	// Establish a connection to the local ssh-agent
+	if conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK")); err == nil {
+		t.agent = conn
+		t.auth = append(t.auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
 	}
+
 	// When there's a non empty password add the password AuthMethod
 	if conf.Password != "" {
-		sshConfig.Auth = append(sshConfig.Auth, ssh.PasswordCallback(func() (string, error) {
+		t.auth = append(t.auth, ssh.PasswordCallback(func() (string, error) {
 			return conf.Password, nil
 		}))
 	}
 
+	if len(t.auth) == 0 {
+		return nil, fmt.Errorf("no ssh proxy authentication, set the ssh-proxy key-path or password or start an" +
+			" ssh agent")
+	}
+
+	return t, nil
+}
+
+// loadKey return the signer of the private key file, prompt is asked for the passphrase of an encrypted key
+func loadKey(path string, prompt Prompter) (ssh.Signer, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pem, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, fmt.Errorf("ssh proxy key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(pem)
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if prompt == nil {
+			return nil, fmt.Errorf("ssh proxy key %v is passphrase protected, add it to the ssh agent", path)
+		}
+
+		passphrase, err := prompt.Passphrase(path)
+		if err != nil {
+			return nil, fmt.Errorf("ssh proxy key %v passphrase: %w", path, err)
+		}
+
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("ssh proxy key %v: %w", path, err)
+		}
+
+		return signer, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("ssh proxy key %v: %w", path, err)
+	}
+
+	return signer, nil
+}
+
+// connect return the ssh client of the tunnel, connecting to the proxy when the tunnel is not connected
+func (t *tunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	addr := net.JoinHostPort(t.conf.Host, fmt.Sprint(t.conf.Port))
+
 	// Connect to the SSH Server
-	return ssh.Dial("tcp", net.JoinHostPort(conf.Host, fmt.Sprint(conf.Port)), sshConfig)
+	dialer := net.Dialer{Timeout: t.timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// bound the ssh handshake as well, the deadline is cleared once the client is up
+	if err = conn.SetDeadline(time.Now().Add(t.timeout)); err != nil {
+		conn.Close() // nolint: errcheck,gosec
+		return nil, err
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            t.conf.User,
+		Auth:            t.auth,
+		HostKeyCallback: t.verifyHostKey(conn),
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, sshConfig)
+	if err != nil {
+		conn.Close() // nolint: errcheck,gosec
+		return nil, err
+	}
+
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		c.Close() // nolint: errcheck,gosec
+		return nil, err
+	}
+
+	t.client = ssh.NewClient(c, chans, reqs)
+
+	done := make(chan struct{})
+
+	go func(client *ssh.Client) {
+		client.Wait() // nolint: errcheck,gosec
+		t.lost(client)
+		close(done)
+	}(t.client)
+
+	keepAlive := t.conf.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = config.DefaultKeepAlive
+	}
+
+	if keepAlive > 0 {
+		go t.keepAlive(t.client, keepAlive, done)
+	}
+
+	return t.client, nil
+}
+
+// lost forget the closed client so the next dial reconnect
+func (t *tunnel) lost(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == client {
+		t.client = nil
+	}
+}
+
+// keepAlive send a keepalive request every interval until done, the client is closed when a request is not
+// answered within the timeout
+func (t *tunnel) keepAlive(client *ssh.Client, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		answered := make(chan error, 1)
+
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			answered <- err
+		}()
+
+		select {
+		case err := <-answered:
+			if err == nil {
+				continue
+			}
+		case <-time.After(t.timeout):
+		}
+
+		client.Close() // nolint: errcheck,gosec
+
+		return
+	}
+}
+
+// verifyHostKey return the callback verifying the proxy host key against the known hosts file. The user is
+// asked to trust an unknown key, the handshake deadline of conn is lifted meanwhile
+func (t *tunnel) verifyHostKey(conn net.Conn) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		path := t.conf.KnownHosts
+		if path == "" {
+			path = defaultKnownHosts
+		}
+
+		path, err := expandHome(path)
+		if err != nil {
+			return err
+		}
+
+		err = checkKnownHost(path, hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+
+		switch {
+		case err == nil:
+			return nil
+		case !errors.As(err, &keyErr):
+			return err
+		case len(keyErr.Want) > 0:
+			return fmt.Errorf("ssh proxy %v host key %v %v does not match the key in %v, the host key changed"+
+				" or the connection is intercepted", hostname, key.Type(), ssh.FingerprintSHA256(key), path)
+		}
+
+		if err = conn.SetDeadline(time.Time{}); err != nil {
+			return err
+		}
+
+		trusted := t.prompt != nil && t.prompt.TrustHostKey(hostname, key.Type(), ssh.FingerprintSHA256(key))
+
+		if err = conn.SetDeadline(time.Now().Add(t.timeout)); err != nil {
+			return err
+		}
+
+		if !trusted {
+			return fmt.Errorf("ssh proxy %v host key %v %v is not trusted, add it to %v", hostname, key.Type(),
+				ssh.FingerprintSHA256(key), path)
+		}
+
+		return addKnownHost(path, hostname, key)
+	}
+}
+
+// checkKnownHost verify the host key against the known hosts file, a missing file knows no hosts
+func checkKnownHost(path, hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &knownhosts.KeyError{}
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return err
+	}
+
+	return callback(hostname, remote, key)
+}
+
+// addKnownHost append the host key to the known hosts file, created if missing
+func addKnownHost(path, hostname string, key ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // nolint: gosec
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n"); err != nil {
+		f.Close() // nolint: errcheck,gosec
+		return err
+	}
+
+	return f.Close()
+}
+
+// Close close the connection to the ssh proxy and the ssh agent
+func (t *tunnel) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.agent != nil {
+		t.agent.Close() // nolint: errcheck,gosec
+	}
+
+	if t.client == nil {
+		return nil
+	}
+
+	return t.client.Close()
 }
 
 // ProxyConnect starts new database connection via ssh proxy
 func ProxyConnect(conf *config.Config) (*Database, error) {
-	var db Database
+	return ProxyConnectContext(context.Background(), conf, nil)
+}
+
+// ProxyConnectContext is ProxyConnect with a context bounding the connection attempt and prompt asked for the
+// ssh key passphrase and to trust the proxy host key, a nil prompt fail on them instead
+func ProxyConnectContext(ctx context.Context, conf *config.Config, prompt Prompter) (*Database, error) {
+	db := Database{timeout: conf.MariaDB.Timeout}
+	if db.timeout == 0 {
+		db.timeout = config.DefaultTimeout
+	}
+
+	var err error
 
-	sshcon, err := newSSHProxy(conf.SSHProxy)
+	db.tunnel, err = newTunnel(conf.SSHProxy, db.timeout, prompt)
 	if err != nil {
 		return &db, err
 	}
 
-	mysql.RegisterDialContext("mysql+tcp", func(ctx context.Context, addr string) (net.Conn, error) {
-		sshd := &viaSSHDialer{sshcon}
-		return sshd.Dial(addr)
-	})
+	// the first connection verify the proxy and authenticate before the database is dialed through it
+	if _, err = db.tunnel.connect(ctx); err != nil {
+		return &db, err
+	}
 
-	dbConfig := mysql.Config{
-		User:                 conf.MariaDB.User,
-		Passwd:               conf.MariaDB.Password,
-		Net:                  "mysql+tcp",
-		Addr:                 net.JoinHostPort(conf.MariaDB.Host, fmt.Sprint(conf.MariaDB.Port)),
-		DBName:               conf.MariaDB.DB,
-		AllowNativePasswords: true,
+	for _, network := range []string{"tcp", "unix"} {
+		sshd := &viaSSHDialer{tunnel: db.tunnel, network: network}
+		mysql.RegisterDialContext(proxyDialer+network, sshd.DialContext)
 	}
 
-	db.Conn, err = sqlx.Open("mysql", dbConfig.FormatDSN())
+	db.Conn, err = open(conf.MariaDB, proxyDialer)
 	if err != nil {
 		return &db, err
 	}
 
-	err = db.Conn.Ping()
+	err = db.ping(ctx)
 	if err != nil {
 		return &db, err
 	}
 
+	err = db.checkSchema()
+
 	return &db, err
 }
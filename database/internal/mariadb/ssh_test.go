@@ -0,0 +1,245 @@
+// nolint
+package mariadb
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+	"golang.org/x/crypto/ssh"
+)
+
+type testPrompter struct {
+	trust      bool
+	passphrase string
+	asked      int
+}
+
+func (p *testPrompter) TrustHostKey(host, keyType, fingerprint string) bool {
+	p.asked++
+	return p.trust
+}
+
+func (p *testPrompter) Passphrase(keyPath string) ([]byte, error) {
+	p.asked++
+	return []byte(p.passphrase), nil
+}
+
+func Test_loadKey(t *testing.T) {
+	dir := t.TempDir()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := filepath.Join(dir, "id_ecdsa")
+	if err = ioutil.WriteFile(plain, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte("secret"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted := filepath.Join(dir, "id_ecdsa_encrypted")
+	if err = ioutil.WriteFile(encrypted, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		prompt  Prompter
+		wantErr bool
+	}{
+		{name: "plain key", path: plain},
+		{name: "encrypted key", path: encrypted, prompt: &testPrompter{passphrase: "secret"}},
+		{name: "wrong passphrase", path: encrypted, prompt: &testPrompter{passphrase: "guess"}, wantErr: true},
+		{name: "encrypted key without prompt", path: encrypted, wantErr: true},
+		{name: "missing key", path: filepath.Join(dir, "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := loadKey(tt.path, tt.prompt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && signer.PublicKey().Type() != ssh.KeyAlgoECDSA256 {
+				t.Errorf("loadKey() key type = %v, want %v", signer.PublicKey().Type(), ssh.KeyAlgoECDSA256)
+			}
+		})
+	}
+}
+
+func Test_tunnel(t *testing.T) {
+	hostKey, addr, drop := testSSHServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	knownHosts := filepath.Join(t.TempDir(), "ssh", "known_hosts")
+	conf := config.SSHProxy{User: "admiral", Password: "secret", Host: host, Port: portNum, KnownHosts: knownHosts,
+		KeepAlive: -1}
+
+	// an unknown host key is refused without the user trust
+	refused := &testPrompter{}
+
+	tun, err := newTunnel(conf, time.Second, refused)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tun.connect(context.Background()); err == nil || refused.asked != 1 {
+		t.Fatalf("connect() error = %v asked %v times, want error for untrusted host key", err, refused.asked)
+	}
+
+	// a trusted host key is added to the known hosts and not asked again
+	trusted := &testPrompter{trust: true}
+
+	tun, err = newTunnel(conf, time.Second, trusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tun.Close()
+
+	client, err := tun.connect(context.Background())
+	if err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+
+	if b, _ := ioutil.ReadFile(knownHosts); !strings.Contains(string(b), hostKey.Type()) {
+		t.Errorf("known hosts = %s, want the trusted %v host key", b, hostKey.Type())
+	}
+
+	// a lost connection is reopened by the next connect
+	drop()
+	client.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		again, err := tun.connect(context.Background())
+		if err != nil {
+			t.Fatalf("connect() after lost connection error = %v", err)
+		}
+
+		if again != client {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("connect() = lost client, want reconnected client")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if trusted.asked != 1 {
+		t.Errorf("asked to trust the host key %v times, want once", trusted.asked)
+	}
+
+	// a changed host key is refused without asking
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, _ := ssh.NewPublicKey(&other.PublicKey)
+	if err = tun.verifyHostKey(nopConn{})(addr, &net.TCPAddr{IP: net.ParseIP(host), Port: portNum},
+		otherKey); err == nil || trusted.asked != 1 {
+		t.Errorf("verifyHostKey() error = %v, want error for changed host key", err)
+	}
+}
+
+// testSSHServer start an ssh server accepting the password secret, drop close the accepted connections
+func testSSHServer(t *testing.T) (hostKey ssh.PublicKey, addr string, drop func()) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverConf := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, errors.New("wrong password")
+			}
+
+			return nil, nil
+		},
+	}
+	serverConf.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	conns := make(chan net.Conn, 10)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			conns <- conn
+
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, serverConf)
+				if err != nil {
+					return
+				}
+
+				go ssh.DiscardRequests(reqs)
+
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels")
+				}
+			}()
+		}
+	}()
+
+	drop = func() {
+		for {
+			select {
+			case conn := <-conns:
+				conn.Close()
+			default:
+				return
+			}
+		}
+	}
+
+	return signer.PublicKey(), listener.Addr().String(), drop
+}
+
+// nopConn is a net.Conn accepting deadlines
+type nopConn struct{ net.Conn }
+
+func (nopConn) SetDeadline(time.Time) error { return nil }
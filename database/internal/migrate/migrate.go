@@ -0,0 +1,213 @@
+// Package migrate apply ordered schema migrations to a database backend and keep
+// track of the applied versions in the `schema_version` table.
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+const versionTable = `CREATE TABLE IF NOT EXISTS schema_version (
+  version integer NOT NULL PRIMARY KEY,
+  description varchar(255) NOT NULL,
+  applied_at varchar(32) NOT NULL
+)`
+
+// migration file names are expected in the format `0001_short_description.sql`
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+// Migration is a single schema version with the statements required to reach it
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Load read the migration files from the root of fsys and return them ordered by version.
+// Statements in a file are separated by an empty line following the `;`
+func Load(fsys fs.FS) (migrations []Migration, err error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]string)
+
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %v", file)
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %v and %v have the same version", other, file)
+		}
+
+		seen[version] = file
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var statements []string
+
+		for _, statement := range strings.Split(string(content), ";\n\n") {
+			if strings.TrimSpace(statement) != "" {
+				statements = append(statements, statement)
+			}
+		}
+
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: strings.ReplaceAll(match[2], "_", " "),
+			Statements:  statements,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// latest return the highest version known to the binary
+func latest(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// applied return the applied versions and the time they were applied
+func applied(conn *sqlx.DB) (versions map[int]string, err error) {
+	if _, err = conn.Exec(versionTable); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	versions = make(map[int]string)
+
+	for rows.Next() {
+		var version int
+
+		var appliedAt string
+
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		versions[version] = appliedAt
+	}
+
+	return versions, rows.Err()
+}
+
+// Check return error if the database schema is newer than the latest known migration,
+// meaning the database was upgraded by a newer version of admiral
+func Check(conn *sqlx.DB, migrations []Migration) error {
+	versions, err := applied(conn)
+	if err != nil {
+		return err
+	}
+
+	for version := range versions {
+		if version > latest(migrations) {
+			return fmt.Errorf("database schema version %v is newer than the latest version supported"+
+				" by this binary (%v), please upgrade admiral", version, latest(migrations))
+		}
+	}
+
+	return nil
+}
+
+// Status return all known migrations, migrations that were not applied yet have empty `AppliedAt`
+func Status(conn *sqlx.DB, migrations []Migration) (status []datastructs.Migration, err error) {
+	versions, err := applied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		status = append(status, datastructs.Migration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   versions[m.Version],
+		})
+	}
+
+	return status, nil
+}
+
+// Up apply all pending migrations in order, each in its own transaction, and return the
+// applied migrations
+func Up(conn *sqlx.DB, migrations []Migration) (done []datastructs.Migration, err error) {
+	if err = Check(conn, migrations); err != nil {
+		return nil, err
+	}
+
+	versions, err := applied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		if _, ok := versions[m.Version]; ok {
+			continue
+		}
+
+		appliedAt := time.Now().UTC().Format(time.RFC3339)
+
+		if err = apply(conn, &m, appliedAt); err != nil {
+			return done, fmt.Errorf("migration %v (%v) failed: %w", m.Version, m.Description, err)
+		}
+
+		done = append(done, datastructs.Migration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   appliedAt,
+		})
+	}
+
+	return done, nil
+}
+
+func apply(conn *sqlx.DB, m *Migration, appliedAt string) error {
+	tx, err := conn.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range m.Statements {
+		if _, err = tx.Exec(statement); err != nil {
+			tx.Rollback() // nolint: errcheck,gosec
+			return err
+		}
+	}
+
+	_, err = tx.Exec(tx.Rebind("INSERT INTO schema_version (version, description, applied_at) VALUES (?,?,?)"),
+		m.Version, m.Description, appliedAt)
+	if err != nil {
+		tx.Rollback() // nolint: errcheck,gosec
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,142 @@
+// nolint
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testFiles = fstest.MapFS{
+	"0002_add_host.sql": &fstest.MapFile{
+		Data: []byte("CREATE TABLE host (id integer PRIMARY KEY);\n\nCREATE INDEX host_id ON host (id);\n"),
+	},
+	"0001_initial_scheme.sql": &fstest.MapFile{
+		Data: []byte("CREATE TABLE `group` (id integer PRIMARY KEY);\n"),
+	},
+}
+
+func prepEnv(t *testing.T) *sqlx.DB {
+	conn, err := sqlx.Open("sqlite3", "file:migrate.sqlite?cache=shared&mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          fstest.MapFS
+		wantVersions   []int
+		wantStatements []int
+		wantErr        bool
+	}{
+		{
+			name:           "ordered by version",
+			files:          testFiles,
+			wantVersions:   []int{1, 2},
+			wantStatements: []int{1, 2},
+			wantErr:        false,
+		},
+		{
+			name: "invalid file name",
+			files: fstest.MapFS{
+				"initial.sql": &fstest.MapFile{Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"0001_a.sql": &fstest.MapFile{Data: []byte("SELECT 1")},
+				"01_b.sql":   &fstest.MapFile{Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for i := range tt.wantVersions {
+				if got[i].Version != tt.wantVersions[i] {
+					t.Errorf("Load() version = %v, want %v", got[i].Version, tt.wantVersions[i])
+				}
+
+				if len(got[i].Statements) != tt.wantStatements[i] {
+					t.Errorf("Load() statements = %v, want %v", len(got[i].Statements), tt.wantStatements[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUp(t *testing.T) {
+	conn := prepEnv(t)
+
+	defer conn.Close()
+
+	migrations, err := Load(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := Up(conn, migrations[:1])
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("Up() = %v, %v, want 1 applied migration", applied, err)
+	}
+
+	status, err := Status(conn, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status[0].AppliedAt == "" || status[1].AppliedAt != "" {
+		t.Errorf("Status() = %v, want first migration applied and second pending", status)
+	}
+
+	applied, err = Up(conn, migrations)
+	if err != nil || len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("Up() = %v, %v, want only version 2 applied", applied, err)
+	}
+
+	applied, err = Up(conn, migrations)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Up() = %v, %v, want nothing to apply", applied, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	conn := prepEnv(t)
+
+	defer conn.Close()
+
+	migrations, err := Load(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Up(conn, migrations); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Check(conn, migrations); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+
+	if err = Check(conn, migrations[:1]); err == nil {
+		t.Errorf("Check() error = nil, want error for database newer than binary")
+	}
+
+	if _, err = Up(conn, migrations[:1]); err == nil {
+		t.Errorf("Up() error = nil, want error for database newer than binary")
+	}
+}
@@ -0,0 +1,777 @@
+// nolint: golint,rowserrcheck,errcheck
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/via-justa/admiral/datastructs"
+
+	// postgres driver
+	_ "github.com/lib/pq"
+)
+
+// Database exposes a database connection
+type Database struct {
+	Conn *sqlx.DB
+	// tx is set on a Database returned by Begin, all queries then run in the transaction
+	tx *sqlx.Tx
+	// timeout bound every query, zero disable it
+	timeout time.Duration
+}
+
+// Connect returns a Database connection
+func Connect(conf config.PostgresConfig) (*Database, error) {
+	return ConnectContext(context.Background(), conf)
+}
+
+// ConnectContext is Connect with a context bounding the connection attempt
+func ConnectContext(ctx context.Context, conf config.PostgresConfig) (*Database, error) {
+	db := Database{timeout: conf.Timeout}
+	if db.timeout == 0 {
+		db.timeout = config.DefaultTimeout
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(conf.User, conf.Password),
+		Host:   net.JoinHostPort(conf.Host, fmt.Sprint(conf.Port)),
+		Path:   conf.DB,
+	}
+
+	if conf.SSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": []string{conf.SSLMode}}.Encode()
+	}
+
+	var err error
+
+	db.Conn, err = sqlx.Open("postgres", dsn.String())
+	if err != nil {
+		return &db, err
+	}
+
+	err = db.ping(ctx)
+	if err != nil {
+		return &db, err
+	}
+
+	err = db.checkSchema()
+
+	return &db, err
+}
+
+// Close close the connection to database
+func (db *Database) Close() (err error) {
+	return db.Conn.Close()
+}
+
+// timestamp return the current time in the format stored in the created and updated columns
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// bumpHostRevision increase the revision of the host whose groups or addresses changed, a write based on the
+// host read before the change is then rejected
+func (db *Database) bumpHostRevision(ctx context.Context, hostID int) error {
+	_, err := db.conn().ExecContext(ctx, "UPDATE host SET revision=revision+1 WHERE id=$1", hostID)
+	return err
+}
+
+// checkRevision return ErrRevisionConflict if the record with the given unique value is not stored at revision,
+// a revision of 0 skip the check. A record that does not exist is at revision 0, the row is locked until the
+// end of the transaction so it cannot change before it is written
+func (db *Database) checkRevision(ctx context.Context, table, column, val string, revision int64) error {
+	if revision == 0 {
+		return nil
+	}
+
+	var stored []int64
+
+	err := db.conn().SelectContext(ctx, &stored, `SELECT revision FROM "`+table+`" WHERE `+column+`=$1 FOR UPDATE`, val)
+	if err != nil {
+		return err
+	} else if len(stored) == 0 {
+		stored = append(stored, 0)
+	}
+
+	if stored[0] != revision {
+		return fmt.Errorf("%v %v %w (read at revision %v, stored at revision %v)", table, val,
+			datastructs.ErrRevisionConflict, revision, stored[0])
+	}
+
+	return nil
+}
+
+// Hosts
+
+// SelectHost return host information. The function will search for the host in the following order:
+// By hostname, if hostname is empty by host and if both hostname and host are empty by id
+func (db *Database) SelectHost(hostname string) (returnedHost datastructs.Host, err error) {
+	return db.SelectHostContext(context.Background(), hostname)
+}
+
+// SelectHostContext is SelectHost with a context bounding the query
+func (db *Database) SelectHostContext(ctx context.Context, hostname string) (returnedHost datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if len(hostname) != 0 {
+		err = db.conn().GetContext(ctx, &returnedHost, "SELECT host_id, host, hostname, domain, variables,"+
+			" enabled, monitored, direct_group, inherited_groups, created, updated, revision FROM host_view"+
+			" WHERE hostname=$1", hostname)
+		if errors.Is(err, sql.ErrNoRows) {
+			return returnedHost, nil
+		} else if err != nil {
+			return returnedHost, err
+		}
+
+		return returnedHost, nil
+	}
+
+	return returnedHost, fmt.Errorf("please provide either hostname")
+}
+
+// GetHosts return all hosts in the inventory
+func (db *Database) GetHosts() (hosts []datastructs.Host, err error) {
+	return db.GetHostsContext(context.Background())
+}
+
+// GetHostsContext is GetHosts with a context bounding the query
+func (db *Database) GetHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view")
+	if errors.Is(err, sql.ErrNoRows) {
+		return hosts, nil
+	} else if err != nil {
+		return hosts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		host := new(datastructs.Host)
+		if err = rows.Scan(&host.ID, &host.Host, &host.Hostname, &host.Domain, &host.Variables, &host.Enabled,
+			&host.Monitored, &host.DirectGroups, &host.InheritedGroups, &host.Created, &host.Updated,
+			&host.Revision); err != nil {
+			return hosts, err
+		}
+
+		hosts = append(hosts, *host)
+	}
+
+	return hosts, nil
+}
+
+// InsertHost accept Host to insert or update and return the number of affected rows and error if exists
+func (db *Database) InsertHost(host *datastructs.Host) (affected int64, err error) {
+	return db.InsertHostContext(context.Background(), host)
+}
+
+// InsertHostContext is InsertHost with a context bounding the query
+func (db *Database) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = db.inTrash(ctx, "host", "hostname", host.Hostname); err != nil {
+		return 0, err
+	}
+
+	// a host in the trash keeps its IP
+	if err = db.inTrash(ctx, "host", "host", host.Host); err != nil {
+		return 0, err
+	}
+
+	if err = db.checkRevision(ctx, "host", "hostname", host.Hostname, host.Revision); err != nil {
+		return 0, err
+	}
+
+	// updated and revision are kept when the host did not change
+	sql := `INSERT INTO host (host, hostname, domain, variables, enabled, monitored, created, updated)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$7) ON CONFLICT (hostname) DO UPDATE SET host=$1, domain=$3, variables=$4,
+	enabled=$5, monitored=$6, updated=CASE WHEN (host.host, host.domain, host.variables, host.enabled, host.monitored)
+		IS NOT DISTINCT FROM ($1, $3, $4, $5, $6) THEN host.updated ELSE $7 END,
+	revision=CASE WHEN (host.host, host.domain, host.variables, host.enabled, host.monitored)
+		IS NOT DISTINCT FROM ($1, $3, $4, $5, $6) THEN host.revision ELSE host.revision+1 END`
+
+	res, err := db.conn().ExecContext(ctx, sql, host.Host, host.Hostname, host.Domain, host.Variables,
+		host.Enabled, host.Monitored, timestamp())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// DeleteHost accept Host to move to the trash and return the number of affected rows and error if exists
+func (db *Database) DeleteHost(host *datastructs.Host) (affected int64, err error) {
+	return db.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext is DeleteHost with a context bounding the query
+func (db *Database) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "UPDATE host SET deleted=$1 WHERE id=$2 AND deleted=''", timestamp(),
+		host.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// ScanHosts get hosts where hostname or IP is like requested string
+func (db *Database) ScanHosts(val string) (hosts []datastructs.Host, err error) {
+	return db.ScanHostsContext(context.Background(), val)
+}
+
+// ScanHostsContext is ScanHosts with a context bounding the query
+func (db *Database) ScanHostsContext(ctx context.Context, val string) (hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return hosts, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view WHERE hostname"+
+		" LIKE $1 OR host LIKE $1", "%"+val+"%")
+	if errors.Is(err, sql.ErrNoRows) {
+		return hosts, nil
+	} else if err != nil {
+		return hosts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		host := new(datastructs.Host)
+		if err = rows.Scan(&host.ID, &host.Host, &host.Hostname, &host.Domain, &host.Variables,
+			&host.Enabled, &host.Monitored, &host.DirectGroups, &host.InheritedGroups, &host.Created, &host.Updated,
+			&host.Revision); err != nil {
+			return hosts, err
+		}
+
+		hosts = append(hosts, *host)
+	}
+
+	return hosts, nil
+}
+
+// Groups
+
+// SelectGroup return group information. The function will search for the group in the following order:
+// By name, if name is empty by id
+func (db *Database) SelectGroup(name string) (returnedGroup datastructs.Group, err error) {
+	return db.SelectGroupContext(context.Background(), name)
+}
+
+// SelectGroupContext is SelectGroup with a context bounding the query
+func (db *Database) SelectGroupContext(ctx context.Context, name string) (returnedGroup datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if len(name) != 0 {
+		err = db.conn().GetContext(ctx, &returnedGroup, "SELECT group_id, name, variables, enabled,"+
+			" monitored, num_children, num_hosts, child_groups, created, updated, revision FROM groups_view WHERE name=$1", name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return returnedGroup, nil
+		} else if err != nil {
+			return returnedGroup, err
+		}
+
+		return returnedGroup, nil
+	}
+
+	return returnedGroup, fmt.Errorf("please provide group name")
+}
+
+// GetGroups return all groups in the inventory
+func (db *Database) GetGroups() (groups []datastructs.Group, err error) {
+	return db.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is GetGroups with a context bounding the query
+func (db *Database) GetGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT group_id, name, variables, enabled, monitored,"+
+		" num_children, num_hosts, child_groups, created, updated, revision FROM groups_view")
+	if errors.Is(err, sql.ErrNoRows) {
+		return groups, nil
+	} else if err != nil {
+		return groups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		group := new(datastructs.Group)
+		if err = rows.Scan(&group.ID, &group.Name, &group.Variables, &group.Enabled, &group.Monitored,
+			&group.NumChildren, &group.NumHosts, &group.ChildGroups, &group.Created, &group.Updated,
+			&group.Revision); err != nil {
+			return groups, err
+		}
+
+		groups = append(groups, *group)
+	}
+
+	return groups, nil
+}
+
+// InsertGroup accept Group to insert or update and return the number of affected rows and error if exists
+func (db *Database) InsertGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.InsertGroupContext(context.Background(), group)
+}
+
+// InsertGroupContext is InsertGroup with a context bounding the query
+func (db *Database) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = db.inTrash(ctx, "group", "name", group.Name); err != nil {
+		return 0, err
+	}
+
+	if err = db.checkRevision(ctx, "group", "name", group.Name, group.Revision); err != nil {
+		return 0, err
+	}
+
+	// updated and revision are kept when the group did not change
+	sql := `INSERT INTO "group" (name, variables, enabled, monitored, created, updated) VALUES ($1,$2,$3,$4,$5,$5)` +
+		` ON CONFLICT (name) DO UPDATE SET variables=$2, enabled=$3, monitored=$4, updated=CASE WHEN` +
+		` ("group".variables, "group".enabled, "group".monitored) IS NOT DISTINCT FROM ($2, $3, $4)` +
+		` THEN "group".updated ELSE $5 END, revision=CASE WHEN` +
+		` ("group".variables, "group".enabled, "group".monitored) IS NOT DISTINCT FROM ($2, $3, $4)` +
+		` THEN "group".revision ELSE "group".revision+1 END`
+
+	res, err := db.conn().ExecContext(ctx, sql, group.Name, group.Variables, group.Enabled, group.Monitored,
+		timestamp())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// DeleteGroup accept Group to move to the trash and return the number of affected rows and error if exists
+func (db *Database) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.DeleteGroupContext(context.Background(), group)
+}
+
+// DeleteGroupContext is DeleteGroup with a context bounding the query
+func (db *Database) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, `UPDATE "group" SET deleted=$1 WHERE id=$2 AND deleted=''`, timestamp(),
+		group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// ScanGroups get group where group name in like requested string
+func (db *Database) ScanGroups(val string) (groups []datastructs.Group, err error) {
+	return db.ScanGroupsContext(context.Background(), val)
+}
+
+// ScanGroupsContext is ScanGroups with a context bounding the query
+func (db *Database) ScanGroupsContext(ctx context.Context, val string) (groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return groups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT group_id, name, variables, enabled, monitored,"+
+		" num_children, num_hosts, child_groups, created, updated, revision FROM groups_view WHERE name LIKE $1", "%"+val+"%")
+	if errors.Is(err, sql.ErrNoRows) {
+		return groups, nil
+	} else if err != nil {
+		return groups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		group := new(datastructs.Group)
+		if err = rows.Scan(&group.ID, &group.Name, &group.Variables, &group.Enabled,
+			&group.Monitored, &group.NumChildren, &group.NumHosts, &group.ChildGroups,
+			&group.Created, &group.Updated,
+			&group.Revision); err != nil {
+			return groups, err
+		}
+
+		groups = append(groups, *group)
+	}
+
+	return groups, nil
+}
+
+// ChildGroups
+
+// SelectChildGroup accept child and parent group names and return the matching child-group relationships.
+// will error if either is missing
+func (db *Database) SelectChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error) {
+	return db.SelectChildGroupContext(context.Background(), child, parent)
+}
+
+// SelectChildGroupContext is SelectChildGroup with a context bounding the query
+func (db *Database) SelectChildGroupContext(ctx context.Context, child, parent string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if child != "" && parent != "" {
+		var rows *sql.Rows
+
+		rows, err = db.conn().QueryContext(ctx, "SELECT relationship_id, parent, parent_id, child, child_id"+
+			" FROM childgroups_view WHERE parent=$1 AND child=$2", parent, child)
+		if errors.Is(err, sql.ErrNoRows) {
+			return childGroups, nil
+		} else if err != nil {
+			return childGroups, err
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			childGroup := new(datastructs.ChildGroup)
+			if err = rows.Scan(&childGroup.ID, &childGroup.Parent, &childGroup.ParentID,
+				&childGroup.Child, &childGroup.ChildID); err != nil {
+				return childGroups, err
+			}
+
+			childGroups = append(childGroups, *childGroup)
+		}
+
+		return childGroups, err
+	}
+
+	return childGroups, fmt.Errorf("please provide child and parent group names")
+}
+
+// GetChildGroups return all child groups relationships in the inventory
+func (db *Database) GetChildGroups() (childGroups []datastructs.ChildGroup, err error) {
+	return db.GetChildGroupsContext(context.Background())
+}
+
+// GetChildGroupsContext is GetChildGroups with a context bounding the query
+func (db *Database) GetChildGroupsContext(ctx context.Context) (childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx,
+		"SELECT relationship_id, parent, parent_id, child, child_id FROM childgroups_view")
+	if errors.Is(err, sql.ErrNoRows) {
+		return childGroups, nil
+	} else if err != nil {
+		return childGroups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		childGroup := new(datastructs.ChildGroup)
+		if err = rows.Scan(&childGroup.ID, &childGroup.Parent, &childGroup.ParentID,
+			&childGroup.Child, &childGroup.ChildID); err != nil {
+			return childGroups, err
+		}
+
+		childGroups = append(childGroups, *childGroup)
+	}
+
+	return childGroups, nil
+}
+
+// InsertChildGroup accept ChildGroup to insert and return the number of affected rows and error if exists
+func (db *Database) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+// InsertChildGroupContext is InsertChildGroup with a context bounding the query
+func (db *Database) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	sql := `INSERT INTO childgroups (child_id, parent_id) VALUES ($1,$2)`
+
+	res, err := db.conn().ExecContext(ctx, sql, childGroup.ChildID, childGroup.ParentID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// DeleteChildGroup accept ChildGroup to delete and return the number of affected rows and error if exists
+func (db *Database) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return db.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+// DeleteChildGroupContext is DeleteChildGroup with a context bounding the query
+func (db *Database) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM childgroups WHERE child_id=$1 AND parent_id=$2",
+		childGroup.ChildID, childGroup.ParentID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+
+	return affected, err
+}
+
+// ScanChildGroups get child-group relationships where parent or child is like requested string
+func (db *Database) ScanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error) {
+	return db.ScanChildGroupsContext(context.Background(), val)
+}
+
+// ScanChildGroupsContext is ScanChildGroups with a context bounding the query
+func (db *Database) ScanChildGroupsContext(ctx context.Context, val string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return childGroups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, "SELECT relationship_id, parent, parent_id, child, child_id FROM"+
+		" childgroups_view WHERE parent LIKE $1 OR child LIKE $1", "%"+val+"%")
+	if errors.Is(err, sql.ErrNoRows) {
+		return childGroups, nil
+	} else if err != nil {
+		return childGroups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		childGroup := new(datastructs.ChildGroup)
+		if err = rows.Scan(&childGroup.ID, &childGroup.Parent, &childGroup.ParentID,
+			&childGroup.Child, &childGroup.ChildID); err != nil {
+			return childGroups, err
+		}
+
+		childGroups = append(childGroups, *childGroup)
+	}
+
+	return childGroups, nil
+}
+
+// HostGroups
+
+// SelectHostGroup accept hostname and return slice of HostGroup for the host.
+func (db *Database) SelectHostGroup(host string) (hostGroups []datastructs.HostGroup, err error) {
+	return db.SelectHostGroupContext(context.Background(), host)
+}
+
+// SelectHostGroupContext is SelectHostGroup with a context bounding the query
+func (db *Database) SelectHostGroupContext(ctx context.Context, host string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if host != "" {
+		rows, err := db.conn().QueryContext(ctx, `SELECT relationship_id, "group", group_id,`+
+			` host, host_id FROM hostgroup_view WHERE host=$1`, host)
+		if errors.Is(err, sql.ErrNoRows) {
+			return hostGroups, nil
+		} else if err != nil {
+			return hostGroups, err
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			hostGroup := new(datastructs.HostGroup)
+			if err = rows.Scan(&hostGroup.ID, &hostGroup.Group, &hostGroup.GroupID,
+				&hostGroup.Host, &hostGroup.HostID); err != nil {
+				return hostGroups, err
+			}
+
+			hostGroups = append(hostGroups, *hostGroup)
+		}
+
+		return hostGroups, nil
+	}
+
+	return hostGroups, fmt.Errorf("please provide either host or group id")
+}
+
+// GetHostGroups return all host groups relationships in the inventory
+func (db *Database) GetHostGroups() (hostGroups []datastructs.HostGroup, err error) {
+	return db.GetHostGroupsContext(context.Background())
+}
+
+// GetHostGroupsContext is GetHostGroups with a context bounding the query
+func (db *Database) GetHostGroupsContext(ctx context.Context) (hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.conn().QueryContext(ctx,
+		`SELECT relationship_id, "group", group_id, host, host_id FROM hostgroup_view`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return hostGroups, nil
+	} else if err != nil {
+		return hostGroups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		hostGroup := new(datastructs.HostGroup)
+		if err = rows.Scan(&hostGroup.ID, &hostGroup.Group, &hostGroup.GroupID,
+			&hostGroup.Host, &hostGroup.HostID); err != nil {
+			return hostGroups, err
+		}
+
+		hostGroups = append(hostGroups, *hostGroup)
+	}
+
+	return hostGroups, nil
+}
+
+// InsertHostGroup accept HostGroup to add the host to the group, keeping its other group memberships,
+// and return the number of affected rows and error if exists
+func (db *Database) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+// InsertHostGroupContext is InsertHostGroup with a context bounding the query
+func (db *Database) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	sql := `INSERT INTO hostgroups (host_id, group_id) VALUES ($1,$2) ON CONFLICT (host_id, group_id) DO NOTHING`
+
+	res, err := db.conn().ExecContext(ctx, sql, hostGroup.HostID, hostGroup.GroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	return affected, db.bumpHostRevision(ctx, hostGroup.HostID)
+}
+
+// DeleteHostGroup accept HostGroup to delete and return the number of affected rows and error if exists
+func (db *Database) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return db.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+// DeleteHostGroupContext is DeleteHostGroup with a context bounding the query
+func (db *Database) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM hostgroups WHERE host_id=$1 AND group_id=$2",
+		hostGroup.HostID, hostGroup.GroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	return affected, db.bumpHostRevision(ctx, hostGroup.HostID)
+}
+
+// ScanHostGroups get host-groups where group is like requested string
+func (db *Database) ScanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error) {
+	return db.ScanHostGroupsContext(context.Background(), val)
+}
+
+// ScanHostGroupsContext is ScanHostGroups with a context bounding the query
+func (db *Database) ScanHostGroupsContext(ctx context.Context, val string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if val == "" {
+		return hostGroups, fmt.Errorf("no search value passed")
+	}
+
+	rows, err := db.conn().QueryContext(ctx, `SELECT relationship_id, host_id, host, group_id,`+
+		` "group" FROM hostgroup_view WHERE "group" LIKE $1`, "%"+val+"%")
+	if errors.Is(err, sql.ErrNoRows) {
+		return hostGroups, nil
+	} else if err != nil {
+		return hostGroups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		hg := new(datastructs.HostGroup)
+		if err = rows.Scan(&hg.ID, &hg.HostID, &hg.Host, &hg.GroupID, &hg.Group); err != nil {
+			return hostGroups, err
+		}
+
+		hostGroups = append(hostGroups, *hg)
+	}
+
+	return hostGroups, nil
+}
+
+// PopulateTestData populate test database for internal testing
+// nolint:gosec
+func (db *Database) PopulateTestData(fixturesPath string) (err error) {
+	sql, _ := ioutil.ReadFile(fixturesPath + "/postgres/02_test_data.sql")
+	sqlfileE := base64.StdEncoding.EncodeToString(sql)
+	sqlfileD, _ := base64.StdEncoding.DecodeString(sqlfileE)
+	queries := strings.Split(string(sqlfileD), ";\n\n")
+
+	for _, query := range queries {
+		_, err = db.Conn.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/via-justa/admiral/database/internal/where"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+var dialect = where.Dialect{Like: "ILIKE", Regex: "~*",
+	Var: func(path string) (string, []interface{}) {
+		return "(variables::jsonb #>> ?)", []interface{}{pq.Array(datastructs.VarPath(path))}
+	},
+	HasVar: func(path string) (string, []interface{}) {
+		return "(variables::jsonb #> ?) IS NOT NULL", []interface{}{pq.Array(datastructs.VarPath(path))}
+	},
+}
+
+// hostColumns map the host filter fields to host_view
+var hostColumns = map[string]where.Column{
+	"hostname":  {Expr: "hostname"},
+	"ip":        {Expr: "host"},
+	"domain":    {Expr: "domain"},
+	"enabled":   {Expr: "enabled"},
+	"monitored": {Expr: "monitored"},
+	"group": {Expr: `hv."group"`,
+		Exists: "SELECT 1 FROM hostgroup_view hv WHERE hv.host_id=host_view.host_id AND %v"},
+	// the groups inherited the same way as host_view.inherited_groups
+	"inherited_group": {Expr: "g.name", Exists: `WITH RECURSIVE inherited (child_id, parent_id) AS (
+		SELECT child_id, parent_id FROM childgroups_view
+		UNION ALL
+		SELECT cv.child_id, i.parent_id FROM inherited i JOIN childgroups_view cv ON i.child_id = cv.parent_id)
+	SELECT 1 FROM hostgroup_view hv JOIN inherited i ON hv.group_id = i.child_id JOIN "group" g
+		ON i.parent_id = g.id WHERE hv.host_id=host_view.host_id AND %v`},
+}
+
+// groupColumns map the group filter fields to groups_view
+var groupColumns = map[string]where.Column{
+	"name":      {Expr: "name"},
+	"enabled":   {Expr: "enabled"},
+	"monitored": {Expr: "monitored"},
+}
+
+// FilterHosts return the hosts matching the filter ordered by hostname
+func (db *Database) FilterHosts(filter datastructs.Filter) (hosts []datastructs.Host, err error) {
+	return db.FilterHostsContext(context.Background(), filter)
+}
+
+// FilterHostsContext is FilterHosts with a context bounding the query
+func (db *Database) FilterHostsContext(ctx context.Context, filter datastructs.Filter) (
+	hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = filter.Validate(datastructs.HostFilterFields); err != nil {
+		return hosts, err
+	}
+
+	clause, args, err := where.Clause(filter, hostColumns, dialect)
+	if err != nil {
+		return hosts, err
+	}
+
+	err = db.conn().SelectContext(ctx, &hosts, sqlx.Rebind(sqlx.DOLLAR, "SELECT host_id, host, hostname, domain,"+
+		" variables, enabled, monitored, direct_group, inherited_groups, created, updated, revision FROM host_view"+
+		" WHERE "+clause+" ORDER BY hostname"), args...)
+
+	return hosts, err
+}
+
+// FilterGroups return the groups matching the filter ordered by ID
+func (db *Database) FilterGroups(filter datastructs.Filter) (groups []datastructs.Group, err error) {
+	return db.FilterGroupsContext(context.Background(), filter)
+}
+
+// FilterGroupsContext is FilterGroups with a context bounding the query
+func (db *Database) FilterGroupsContext(ctx context.Context, filter datastructs.Filter) (
+	groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = filter.Validate(datastructs.GroupFilterFields); err != nil {
+		return groups, err
+	}
+
+	clause, args, err := where.Clause(filter, groupColumns, dialect)
+	if err != nil {
+		return groups, err
+	}
+
+	err = db.conn().SelectContext(ctx, &groups, sqlx.Rebind(sqlx.DOLLAR, "SELECT group_id, name, variables, enabled,"+
+		" monitored, num_children, num_hosts, child_groups, created, updated, revision FROM groups_view WHERE "+
+		clause+" ORDER BY group_id"), args...)
+
+	return groups, err
+}
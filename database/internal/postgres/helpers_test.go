@@ -0,0 +1,40 @@
+// nolint:
+package postgres
+
+import (
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/fixtures"
+)
+
+// the records of fixtures/02_test_data.sql and the records the tests create, see package fixtures
+var (
+	testGroup1               = fixtures.Group1
+	testGroup2               = fixtures.Group2
+	testGroup3               = fixtures.Group3
+	testGroup4               = fixtures.Group4
+	testGroup5               = fixtures.Group5
+	testHost1                = fixtures.Host1
+	testHost2                = fixtures.Host2
+	testHost3                = fixtures.Host3
+	testHostGroup1           = fixtures.HostGroup1
+	testHostGroup2           = fixtures.HostGroup2
+	testHostGroup3           = fixtures.HostGroup3
+	testChild1               = fixtures.Child1
+	testChild2               = fixtures.Child2
+	createTestHost10         = fixtures.CreateHost10
+	createTestGroup10        = fixtures.CreateGroup10
+	createTestChild10        = fixtures.CreateChild10
+	createTestChild11        = fixtures.CreateChild11
+	createTestHostGroup10    = fixtures.CreateHostGroup10
+	createTestHostGroup11Err = fixtures.CreateHostGroup11Err
+)
+
+// dbConfig matches the postgres service from docker-compose.yml
+var dbConfig = config.PostgresConfig{
+	User:     "postgres",
+	Password: "local",
+	Host:     "localhost",
+	Port:     5432,
+	DB:       "ansible",
+	SSLMode:  "disable",
+}
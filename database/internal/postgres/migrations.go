@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/via-justa/admiral/database/internal/migrate"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func migrations() ([]migrate.Migration, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.Load(files)
+}
+
+// Migrate apply all pending schema migrations and return the applied migrations
+func (db *Database) Migrate() (applied []datastructs.Migration, err error) {
+	m, err := migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.Up(db.Conn, m)
+}
+
+// MigrationStatus return all schema migrations known to admiral and when they were applied
+func (db *Database) MigrationStatus() (status []datastructs.Migration, err error) {
+	m, err := migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.Status(db.Conn, m)
+}
+
+// checkSchema return error if the database schema is newer than this binary
+func (db *Database) checkSchema() error {
+	m, err := migrations()
+	if err != nil {
+		return err
+	}
+
+	return migrate.Check(db.Conn, m)
+}
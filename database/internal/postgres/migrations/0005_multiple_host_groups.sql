@@ -0,0 +1,4 @@
+-- a host can be a direct member of multiple groups
+ALTER TABLE hostgroups DROP CONSTRAINT IF EXISTS hostgroups_host_id;
+
+ALTER TABLE hostgroups ADD CONSTRAINT hostgroups_host_id_group_id UNIQUE (host_id, group_id);
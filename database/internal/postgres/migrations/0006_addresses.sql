@@ -0,0 +1,24 @@
+-- a host can have multiple named addresses (e.g. management, storage, public), at most one of them primary
+CREATE TABLE IF NOT EXISTS addresses (
+  id serial PRIMARY KEY,
+  host_id integer NOT NULL,
+  name varchar(255) NOT NULL,
+  address varchar(255) NOT NULL,
+  is_primary boolean NOT NULL DEFAULT false,
+  CONSTRAINT addresses_host_id_name UNIQUE (host_id, name),
+  CONSTRAINT addresses_ibfk_1 FOREIGN KEY (host_id) REFERENCES host (id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE VIEW address_view AS
+SELECT
+    addresses.id AS address_id,
+    addresses.host_id AS host_id,
+    host.hostname AS host,
+    addresses.name AS name,
+    addresses.address AS address,
+    addresses.is_primary AS is_primary
+FROM addresses
+JOIN host
+    ON addresses.host_id = host.id
+WHERE host.deleted = ''
+ORDER BY host.hostname, addresses.name;
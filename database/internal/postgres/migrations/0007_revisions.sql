@@ -0,0 +1,88 @@
+-- hosts and groups carry a revision increased on every change, writes based on an older revision are rejected
+ALTER TABLE host ADD COLUMN IF NOT EXISTS revision integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "group" ADD COLUMN IF NOT EXISTS revision integer NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE VIEW host_view AS
+WITH RECURSIVE inherited (child_id, parent_id) AS (
+SELECT
+	child_id,
+	parent_id
+FROM
+	childgroups_view cv
+UNION ALL
+SELECT
+	cv.child_id,
+	i.parent_id
+FROM
+	inherited i
+JOIN childgroups_view cv ON
+	i.child_id = cv.parent_id )
+SELECT
+	host.id AS host_id,
+    host.hostname AS hostname,
+    host.domain AS domain,
+    host.host AS host,
+    host.enabled AS enabled,
+    host.monitored AS monitored,
+    host.variables AS variables,
+    COALESCE(string_agg(DISTINCT g1.name, ',' ORDER BY g1.name), '') AS direct_group,
+    COALESCE(string_agg(DISTINCT g2.name, ',' ORDER BY g2.name), '') AS inherited_groups,
+    host.created AS created,
+    host.updated AS updated,
+    host.revision AS revision
+FROM
+	host
+LEFT JOIN hostgroup_view hv ON
+	host.id = hv.host_id
+LEFT JOIN inherited i ON
+	hv.group_id = i.child_id
+LEFT JOIN "group" g1 ON
+	hv.group_id = g1.id
+LEFT JOIN "group" g2 ON
+	i.parent_id = g2.id
+WHERE
+	host.deleted = ''
+GROUP BY
+	host.id
+ORDER BY
+	host.hostname;
+
+CREATE OR REPLACE VIEW groups_view AS
+WITH RECURSIVE inherited (child_id, parent_id) AS (
+SELECT
+    cv.child_id AS child_id,
+    cv.parent_id AS parent_id
+FROM
+    childgroups_view cv
+UNION ALL
+SELECT
+    cv.child_id AS child_id,
+    i.parent_id AS parent_id
+FROM
+    inherited i
+JOIN childgroups_view cv ON i.child_id = cv.parent_id)
+SELECT
+	g1.id AS group_id,
+    g1.name AS name,
+    g1.enabled AS enabled,
+    g1.monitored AS monitored,
+	COUNT(DISTINCT h.id) AS num_hosts,
+	COUNT(DISTINCT g2.name) AS num_children,
+	COALESCE(string_agg(DISTINCT g2.name, ',' ORDER BY g2.name), '') AS child_groups,
+	g1.variables AS variables,
+	g1.created AS created,
+	g1.updated AS updated,
+	g1.revision AS revision
+FROM "group" g1
+LEFT JOIN inherited i ON
+     g1.id = i.parent_id
+LEFT JOIN "group" g2 ON
+     i.child_id = g2.id
+LEFT JOIN hostgroups hg ON
+	hg.group_id = g1.id
+LEFT JOIN host h ON
+	h.id = hg.host_id AND h.deleted = ''
+WHERE g1.deleted = ''
+GROUP BY g1.id
+ORDER BY g1.id;
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// queryer is implemented by both sqlx.DB and sqlx.Tx
+type queryer interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// conn return the transaction if one is in progress, otherwise the connection pool
+func (db *Database) conn() queryer {
+	if db.tx != nil {
+		return db.tx
+	}
+
+	return db.Conn
+}
+
+// withTimeout bound ctx with the configured query timeout
+func (db *Database) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, db.timeout)
+}
+
+// ping verify the connection to the database within the configured timeout
+func (db *Database) ping(ctx context.Context) error {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	return db.Conn.PingContext(ctx)
+}
+
+// Begin start a new transaction and return a Database bound to it
+func (db *Database) Begin() (*Database, error) {
+	return db.BeginContext(context.Background())
+}
+
+// BeginContext is Begin with a context, the transaction is rolled back if the context is canceled
+// before it is committed
+func (db *Database) BeginContext(ctx context.Context) (*Database, error) {
+	if db.tx != nil {
+		return nil, fmt.Errorf("transaction already in progress")
+	}
+
+	tx, err := db.Conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{Conn: db.Conn, tx: tx, timeout: db.timeout}, nil
+}
+
+// Commit apply all changes made in the transaction
+func (db *Database) Commit() error {
+	if db.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	return db.tx.Commit()
+}
+
+// Rollback discard all changes made in the transaction
+func (db *Database) Rollback() error {
+	if db.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	return db.tx.Rollback()
+}
@@ -0,0 +1,71 @@
+// nolint: golint
+package postgres
+
+import (
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func TestDatabase_Begin(t *testing.T) {
+	prepEnv(t)
+
+	defer testDB.Conn.Close()
+
+	tests := []struct {
+		name      string
+		commit    bool
+		wantSaved bool
+	}{
+		{
+			name:      "commit",
+			commit:    true,
+			wantSaved: true,
+		},
+		{
+			name:      "rollback",
+			commit:    false,
+			wantSaved: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := testDB.Begin()
+			if err != nil {
+				t.Fatalf("Database.Begin() error = %v", err)
+			}
+
+			if _, err = tx.Begin(); err == nil {
+				t.Errorf("Database.Begin() on transaction error = nil, want error")
+			}
+
+			group := &datastructs.Group{Name: "tx-group", Variables: "{}", Enabled: true, Monitored: true}
+			if _, err = tx.InsertGroup(group); err != nil {
+				t.Fatalf("Database.InsertGroup() error = %v", err)
+			}
+
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+
+			if err != nil {
+				t.Fatalf("Database.Commit() / Database.Rollback() error = %v", err)
+			}
+
+			got, err := testDB.SelectGroup(group.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (got.ID != 0) != tt.wantSaved {
+				t.Errorf("Database.Begin() saved = %v, want %v", got.ID != 0, tt.wantSaved)
+			}
+
+			if _, err = testDB.DeleteGroup(&got); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
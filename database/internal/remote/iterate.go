@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"context"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// iteratePage is the most records requested from the server at once while iterating
+const iteratePage = 500
+
+// chunks split page into the pages requested from the server, fetch return the records of a chunk and the
+// iteration stops at the first chunk shorter than requested
+func chunks(page datastructs.Page, fetch func(chunk datastructs.Page) (n int, err error)) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	for offset := page.Offset; page.Limit == 0 || offset < page.Offset+page.Limit; offset += iteratePage {
+		chunk := datastructs.Page{Limit: iteratePage, Offset: offset}
+		if page.Limit > 0 && page.Offset+page.Limit-offset < iteratePage {
+			chunk.Limit = page.Offset + page.Limit - offset
+		}
+
+		n, err := fetch(chunk)
+		if err != nil {
+			return err
+		}
+
+		if n < chunk.Limit {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// IterateHosts call fn with every host ordered by hostname, within page. The iteration stops at the first error
+// returned by fn
+func (q querier) IterateHosts(page datastructs.Page, fn func(host *datastructs.Host) error) error {
+	return q.IterateHostsContext(context.Background(), page, fn)
+}
+
+// IterateHostsContext is IterateHosts with a context bounding the requests
+func (q querier) IterateHostsContext(ctx context.Context, page datastructs.Page,
+	fn func(host *datastructs.Host) error) error {
+	return chunks(page, func(chunk datastructs.Page) (int, error) {
+		var hosts []datastructs.Host
+		if err := q.call(ctx, "IterateHostsContext", []interface{}{chunk}, &hosts); err != nil {
+			return 0, err
+		}
+
+		for i := range hosts {
+			if err := fn(&hosts[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(hosts), nil
+	})
+}
+
+// IterateGroups call fn with every group ordered by name, within page. The iteration stops at the first error
+// returned by fn
+func (q querier) IterateGroups(page datastructs.Page, fn func(group *datastructs.Group) error) error {
+	return q.IterateGroupsContext(context.Background(), page, fn)
+}
+
+// IterateGroupsContext is IterateGroups with a context bounding the requests
+func (q querier) IterateGroupsContext(ctx context.Context, page datastructs.Page,
+	fn func(group *datastructs.Group) error) error {
+	return chunks(page, func(chunk datastructs.Page) (int, error) {
+		var groups []datastructs.Group
+		if err := q.call(ctx, "IterateGroupsContext", []interface{}{chunk}, &groups); err != nil {
+			return 0, err
+		}
+
+		for i := range groups {
+			if err := fn(&groups[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(groups), nil
+	})
+}
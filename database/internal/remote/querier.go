@@ -0,0 +1,379 @@
+package remote
+
+import (
+	"context"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// SelectHost return host information. The function will search for the host in the following order:
+// By hostname, if hostname is empty by host and if both hostname and host are empty by id
+func (q querier) SelectHost(hostname string) (returnedHost datastructs.Host, err error) {
+	return q.SelectHostContext(context.Background(), hostname)
+}
+
+// SelectHostContext is SelectHost with a context bounding the request
+func (q querier) SelectHostContext(ctx context.Context, hostname string) (returnedHost datastructs.Host, err error) {
+	err = q.call(ctx, "SelectHostContext", []interface{}{hostname}, &returnedHost)
+	return returnedHost, err
+}
+
+// GetHosts return all hosts in the inventory
+func (q querier) GetHosts() (hosts []datastructs.Host, err error) {
+	return q.GetHostsContext(context.Background())
+}
+
+// GetHostsContext is GetHosts with a context bounding the request
+func (q querier) GetHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	err = q.call(ctx, "GetHostsContext", nil, &hosts)
+	return hosts, err
+}
+
+// InsertHost accept Host to insert or update and return the number of affected rows and error if exists
+func (q querier) InsertHost(host *datastructs.Host) (affected int64, err error) {
+	return q.InsertHostContext(context.Background(), host)
+}
+
+// InsertHostContext is InsertHost with a context bounding the request
+func (q querier) InsertHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = q.call(ctx, "InsertHostContext", []interface{}{host}, &affected)
+	return affected, err
+}
+
+// DeleteHost accept Host to move to the trash and return the number of affected rows and error if exists
+func (q querier) DeleteHost(host *datastructs.Host) (affected int64, err error) {
+	return q.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext is DeleteHost with a context bounding the request
+func (q querier) DeleteHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = q.call(ctx, "DeleteHostContext", []interface{}{host}, &affected)
+	return affected, err
+}
+
+// ScanHosts get hosts where hostname or IP is like requested string
+func (q querier) ScanHosts(val string) (hosts []datastructs.Host, err error) {
+	return q.ScanHostsContext(context.Background(), val)
+}
+
+// ScanHostsContext is ScanHosts with a context bounding the request
+func (q querier) ScanHostsContext(ctx context.Context, val string) (hosts []datastructs.Host, err error) {
+	err = q.call(ctx, "ScanHostsContext", []interface{}{val}, &hosts)
+	return hosts, err
+}
+
+// FilterHosts return the hosts matching the filter ordered by hostname
+func (q querier) FilterHosts(filter datastructs.Filter) (hosts []datastructs.Host, err error) {
+	return q.FilterHostsContext(context.Background(), filter)
+}
+
+// FilterHostsContext is FilterHosts with a context bounding the request
+func (q querier) FilterHostsContext(ctx context.Context, filter datastructs.Filter) (
+	hosts []datastructs.Host, err error) {
+	err = q.call(ctx, "FilterHostsContext", []interface{}{filter}, &hosts)
+	return hosts, err
+}
+
+// SelectGroup return group information. The function will search for the group in the following order:
+// By name, if name is empty by id
+func (q querier) SelectGroup(name string) (returnedGroup datastructs.Group, err error) {
+	return q.SelectGroupContext(context.Background(), name)
+}
+
+// SelectGroupContext is SelectGroup with a context bounding the request
+func (q querier) SelectGroupContext(ctx context.Context, name string) (returnedGroup datastructs.Group, err error) {
+	err = q.call(ctx, "SelectGroupContext", []interface{}{name}, &returnedGroup)
+	return returnedGroup, err
+}
+
+// GetGroups return all groups in the inventory
+func (q querier) GetGroups() (groups []datastructs.Group, err error) {
+	return q.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is GetGroups with a context bounding the request
+func (q querier) GetGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	err = q.call(ctx, "GetGroupsContext", nil, &groups)
+	return groups, err
+}
+
+// InsertGroup accept Group to insert or update and return the number of affected rows and error if exists
+func (q querier) InsertGroup(group *datastructs.Group) (affected int64, err error) {
+	return q.InsertGroupContext(context.Background(), group)
+}
+
+// InsertGroupContext is InsertGroup with a context bounding the request
+func (q querier) InsertGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = q.call(ctx, "InsertGroupContext", []interface{}{group}, &affected)
+	return affected, err
+}
+
+// DeleteGroup accept Group to move to the trash and return the number of affected rows and error if exists
+func (q querier) DeleteGroup(group *datastructs.Group) (affected int64, err error) {
+	return q.DeleteGroupContext(context.Background(), group)
+}
+
+// DeleteGroupContext is DeleteGroup with a context bounding the request
+func (q querier) DeleteGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = q.call(ctx, "DeleteGroupContext", []interface{}{group}, &affected)
+	return affected, err
+}
+
+// ScanGroups get group where group name in like requested string
+func (q querier) ScanGroups(val string) (groups []datastructs.Group, err error) {
+	return q.ScanGroupsContext(context.Background(), val)
+}
+
+// ScanGroupsContext is ScanGroups with a context bounding the request
+func (q querier) ScanGroupsContext(ctx context.Context, val string) (groups []datastructs.Group, err error) {
+	err = q.call(ctx, "ScanGroupsContext", []interface{}{val}, &groups)
+	return groups, err
+}
+
+// FilterGroups return the groups matching the filter ordered by ID
+func (q querier) FilterGroups(filter datastructs.Filter) (groups []datastructs.Group, err error) {
+	return q.FilterGroupsContext(context.Background(), filter)
+}
+
+// FilterGroupsContext is FilterGroups with a context bounding the request
+func (q querier) FilterGroupsContext(ctx context.Context, filter datastructs.Filter) (
+	groups []datastructs.Group, err error) {
+	err = q.call(ctx, "FilterGroupsContext", []interface{}{filter}, &groups)
+	return groups, err
+}
+
+// GetDeletedHosts return all hosts in the trash
+func (q querier) GetDeletedHosts() (hosts []datastructs.Host, err error) {
+	return q.GetDeletedHostsContext(context.Background())
+}
+
+// GetDeletedHostsContext is GetDeletedHosts with a context bounding the request
+func (q querier) GetDeletedHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	err = q.call(ctx, "GetDeletedHostsContext", nil, &hosts)
+	return hosts, err
+}
+
+// RestoreHost accept Host to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (q querier) RestoreHost(host *datastructs.Host) (affected int64, err error) {
+	return q.RestoreHostContext(context.Background(), host)
+}
+
+// RestoreHostContext is RestoreHost with a context bounding the request
+func (q querier) RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = q.call(ctx, "RestoreHostContext", []interface{}{host}, &affected)
+	return affected, err
+}
+
+// PurgeHost accept Host in the trash to delete permanently and return the number of affected rows and error if exists
+func (q querier) PurgeHost(host *datastructs.Host) (affected int64, err error) {
+	return q.PurgeHostContext(context.Background(), host)
+}
+
+// PurgeHostContext is PurgeHost with a context bounding the request
+func (q querier) PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	err = q.call(ctx, "PurgeHostContext", []interface{}{host}, &affected)
+	return affected, err
+}
+
+// GetDeletedGroups return all groups in the trash
+func (q querier) GetDeletedGroups() (groups []datastructs.Group, err error) {
+	return q.GetDeletedGroupsContext(context.Background())
+}
+
+// GetDeletedGroupsContext is GetDeletedGroups with a context bounding the request
+func (q querier) GetDeletedGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	err = q.call(ctx, "GetDeletedGroupsContext", nil, &groups)
+	return groups, err
+}
+
+// RestoreGroup accept Group to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (q querier) RestoreGroup(group *datastructs.Group) (affected int64, err error) {
+	return q.RestoreGroupContext(context.Background(), group)
+}
+
+// RestoreGroupContext is RestoreGroup with a context bounding the request
+func (q querier) RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = q.call(ctx, "RestoreGroupContext", []interface{}{group}, &affected)
+	return affected, err
+}
+
+// PurgeGroup accept Group in the trash to delete permanently and return the number of affected rows and error if exists
+func (q querier) PurgeGroup(group *datastructs.Group) (affected int64, err error) {
+	return q.PurgeGroupContext(context.Background(), group)
+}
+
+// PurgeGroupContext is PurgeGroup with a context bounding the request
+func (q querier) PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	err = q.call(ctx, "PurgeGroupContext", []interface{}{group}, &affected)
+	return affected, err
+}
+
+// SelectChildGroup accept either child or parent id and return slice of ids for parent or child groups respectively.
+// If child is provided will return slice of parent ids
+// If parent is provided will return slice of child ids
+// will error if none is provided
+func (q querier) SelectChildGroup(child, parent string) (childGroups []datastructs.ChildGroup, err error) {
+	return q.SelectChildGroupContext(context.Background(), child, parent)
+}
+
+// SelectChildGroupContext is SelectChildGroup with a context bounding the request
+func (q querier) SelectChildGroupContext(ctx context.Context, child, parent string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	err = q.call(ctx, "SelectChildGroupContext", []interface{}{child, parent}, &childGroups)
+	return childGroups, err
+}
+
+// GetChildGroups return all child groups relationships in the inventory
+func (q querier) GetChildGroups() (childGroups []datastructs.ChildGroup, err error) {
+	return q.GetChildGroupsContext(context.Background())
+}
+
+// GetChildGroupsContext is GetChildGroups with a context bounding the request
+func (q querier) GetChildGroupsContext(ctx context.Context) (childGroups []datastructs.ChildGroup, err error) {
+	err = q.call(ctx, "GetChildGroupsContext", nil, &childGroups)
+	return childGroups, err
+}
+
+// InsertChildGroup accept ChildGroup to insert and return the number of affected rows and error if exists
+func (q querier) InsertChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return q.InsertChildGroupContext(context.Background(), childGroup)
+}
+
+// InsertChildGroupContext is InsertChildGroup with a context bounding the request
+func (q querier) InsertChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	err = q.call(ctx, "InsertChildGroupContext", []interface{}{childGroup}, &affected)
+	return affected, err
+}
+
+// DeleteChildGroup accept ChildGroup to delete and return the number of affected rows and error if exists
+func (q querier) DeleteChildGroup(childGroup *datastructs.ChildGroup) (affected int64, err error) {
+	return q.DeleteChildGroupContext(context.Background(), childGroup)
+}
+
+// DeleteChildGroupContext is DeleteChildGroup with a context bounding the request
+func (q querier) DeleteChildGroupContext(ctx context.Context, childGroup *datastructs.ChildGroup) (
+	affected int64, err error) {
+	err = q.call(ctx, "DeleteChildGroupContext", []interface{}{childGroup}, &affected)
+	return affected, err
+}
+
+// ScanChildGroups get child-group relationships where parent or child is like requested string
+func (q querier) ScanChildGroups(val string) (childGroups []datastructs.ChildGroup, err error) {
+	return q.ScanChildGroupsContext(context.Background(), val)
+}
+
+// ScanChildGroupsContext is ScanChildGroups with a context bounding the request
+func (q querier) ScanChildGroupsContext(ctx context.Context, val string) (
+	childGroups []datastructs.ChildGroup, err error) {
+	err = q.call(ctx, "ScanChildGroupsContext", []interface{}{val}, &childGroups)
+	return childGroups, err
+}
+
+// SelectHostGroup accept hostname and return slice of HostGroup for the host.
+func (q querier) SelectHostGroup(host string) (hostGroups []datastructs.HostGroup, err error) {
+	return q.SelectHostGroupContext(context.Background(), host)
+}
+
+// SelectHostGroupContext is SelectHostGroup with a context bounding the request
+func (q querier) SelectHostGroupContext(ctx context.Context, host string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	err = q.call(ctx, "SelectHostGroupContext", []interface{}{host}, &hostGroups)
+	return hostGroups, err
+}
+
+// GetHostGroups return all host groups relationships in the inventory
+func (q querier) GetHostGroups() (hostGroups []datastructs.HostGroup, err error) {
+	return q.GetHostGroupsContext(context.Background())
+}
+
+// GetHostGroupsContext is GetHostGroups with a context bounding the request
+func (q querier) GetHostGroupsContext(ctx context.Context) (hostGroups []datastructs.HostGroup, err error) {
+	err = q.call(ctx, "GetHostGroupsContext", nil, &hostGroups)
+	return hostGroups, err
+}
+
+// InsertHostGroup accept HostGroup to add the host to the group, keeping its other group memberships,
+// and return the number of affected rows and error if exists
+func (q querier) InsertHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return q.InsertHostGroupContext(context.Background(), hostGroup)
+}
+
+// InsertHostGroupContext is InsertHostGroup with a context bounding the request
+func (q querier) InsertHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	err = q.call(ctx, "InsertHostGroupContext", []interface{}{hostGroup}, &affected)
+	return affected, err
+}
+
+// DeleteHostGroup accept HostGroup to delete and return the number of affected rows and error if exists
+func (q querier) DeleteHostGroup(hostGroup *datastructs.HostGroup) (affected int64, err error) {
+	return q.DeleteHostGroupContext(context.Background(), hostGroup)
+}
+
+// DeleteHostGroupContext is DeleteHostGroup with a context bounding the request
+func (q querier) DeleteHostGroupContext(ctx context.Context, hostGroup *datastructs.HostGroup) (
+	affected int64, err error) {
+	err = q.call(ctx, "DeleteHostGroupContext", []interface{}{hostGroup}, &affected)
+	return affected, err
+}
+
+// ScanHostGroups get host-groups where group is like requested string
+func (q querier) ScanHostGroups(val string) (hostGroups []datastructs.HostGroup, err error) {
+	return q.ScanHostGroupsContext(context.Background(), val)
+}
+
+// ScanHostGroupsContext is ScanHostGroups with a context bounding the request
+func (q querier) ScanHostGroupsContext(ctx context.Context, val string) (
+	hostGroups []datastructs.HostGroup, err error) {
+	err = q.call(ctx, "ScanHostGroupsContext", []interface{}{val}, &hostGroups)
+	return hostGroups, err
+}
+
+// SelectAddresses accept hostname and return the host addresses ordered by name
+func (q querier) SelectAddresses(host string) (addresses datastructs.Addresses, err error) {
+	return q.SelectAddressesContext(context.Background(), host)
+}
+
+// SelectAddressesContext is SelectAddresses with a context bounding the request
+func (q querier) SelectAddressesContext(ctx context.Context, host string) (addresses datastructs.Addresses, err error) {
+	err = q.call(ctx, "SelectAddressesContext", []interface{}{host}, &addresses)
+	return addresses, err
+}
+
+// GetAddresses return the addresses of all hosts ordered by hostname and name
+func (q querier) GetAddresses() (addresses datastructs.Addresses, err error) {
+	return q.GetAddressesContext(context.Background())
+}
+
+// GetAddressesContext is GetAddresses with a context bounding the request
+func (q querier) GetAddressesContext(ctx context.Context) (addresses datastructs.Addresses, err error) {
+	err = q.call(ctx, "GetAddressesContext", nil, &addresses)
+	return addresses, err
+}
+
+// InsertAddress accept Address to insert or update by host ID and name and return the number of
+// affected rows and error if exists
+func (q querier) InsertAddress(address *datastructs.Address) (affected int64, err error) {
+	return q.InsertAddressContext(context.Background(), address)
+}
+
+// InsertAddressContext is InsertAddress with a context bounding the request
+func (q querier) InsertAddressContext(ctx context.Context, address *datastructs.Address) (affected int64, err error) {
+	err = q.call(ctx, "InsertAddressContext", []interface{}{address}, &affected)
+	return affected, err
+}
+
+// DeleteAddress accept Address to delete by host ID and name and return the number of affected
+// rows and error if exists
+func (q querier) DeleteAddress(address *datastructs.Address) (affected int64, err error) {
+	return q.DeleteAddressContext(context.Background(), address)
+}
+
+// DeleteAddressContext is DeleteAddress with a context bounding the request
+func (q querier) DeleteAddressContext(ctx context.Context, address *datastructs.Address) (affected int64, err error) {
+	err = q.call(ctx, "DeleteAddressContext", []interface{}{address}, &affected)
+	return affected, err
+}
@@ -0,0 +1,278 @@
+// Package remote perform the database functions through the admiral API server (`admiral serve`), so admiral can
+// be used without access to the database.
+//
+// Every function is a POST to Path followed by the function name, e.g. /rpc/SelectHostContext. The arguments,
+// without the context, are gob encoded one after the other in the request body and the results, without the
+// error, in the response body. A transaction is started by the Begin function answering its ID and ended by the
+// Commit or Rollback functions, the functions called within it carry its ID in TxHeader. Errors are answered
+// with a status other than 200 OK and a json Error.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+const (
+	// Path is the path of the database functions on the server
+	Path = "/rpc/"
+	// TxHeader carry the ID of the transaction the function is called within
+	TxHeader = "Admiral-Tx"
+	// ContentType is the content type of the gob encoded arguments and results
+	ContentType = "application/x-gob"
+	// CodeRevisionConflict is the Error code of datastructs.ErrRevisionConflict
+	CodeRevisionConflict = "revision_conflict"
+	// CodeTxDone is the Error code of sql.ErrTxDone, returned as well for unknown transactions
+	CodeTxDone = "tx_done"
+)
+
+func init() {
+	// the dynamic types of the json variables in datastructs.InventoryVars
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Error is an error answered by the server, Code identify the errors the client check for
+type Error struct {
+	Message string `json:"error"`
+	Code    string `json:"code,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap return the error identified by Code, if any
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case CodeRevisionConflict:
+		return datastructs.ErrRevisionConflict
+	case CodeTxDone:
+		return sql.ErrTxDone
+	}
+
+	return nil
+}
+
+// ErrorCode return the Error code of err, empty when the client does not check for it
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, datastructs.ErrRevisionConflict):
+		return CodeRevisionConflict
+	case errors.Is(err, sql.ErrTxDone):
+		return CodeTxDone
+	}
+
+	return ""
+}
+
+// client is the connection to the server
+type client struct {
+	url     string
+	token   string
+	timeout time.Duration
+	http    *http.Client
+}
+
+// querier call the database functions on the server, within the transaction tx when set
+type querier struct {
+	c  *client
+	tx string
+}
+
+// Database exposes the database of the admiral API server
+type Database struct {
+	querier
+}
+
+// Connect returns a Database calling the server of conf
+func Connect(conf config.RemoteConfig) (*Database, error) {
+	return ConnectContext(context.Background(), conf)
+}
+
+// ConnectContext is Connect with a context bounding the check that the server answers
+func ConnectContext(ctx context.Context, conf config.RemoteConfig) (*Database, error) {
+	db := Database{querier{c: &client{
+		url:     strings.TrimSuffix(conf.URL, "/"),
+		token:   conf.Token,
+		timeout: conf.Timeout,
+		http:    &http.Client{},
+	}}}
+
+	if db.c.timeout == 0 {
+		db.c.timeout = config.DefaultTimeout
+	}
+
+	if u, err := url.Parse(db.c.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &db, fmt.Errorf("invalid remote url %q, expected http(s)://host[:port]", conf.URL)
+	}
+
+	if err := db.call(ctx, "Ping", nil); err != nil {
+		return &db, fmt.Errorf("remote %v: %w", db.c.url, err)
+	}
+
+	return &db, nil
+}
+
+// call run the database function on the server with args and decode its results into results
+func (q querier) call(ctx context.Context, function string, args []interface{}, results ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, q.c.timeout)
+	defer cancel()
+
+	var body bytes.Buffer
+
+	enc := gob.NewEncoder(&body)
+	for _, arg := range args {
+		if err := enc.Encode(arg); err != nil {
+			return fmt.Errorf("%v: %w", function, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, q.c.url+Path+function, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", ContentType)
+
+	if q.c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+q.c.token)
+	}
+
+	if q.tx != "" {
+		req.Header.Set(TxHeader, q.tx)
+	}
+
+	resp, err := q.c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return readError(resp)
+	}
+
+	dec := gob.NewDecoder(resp.Body)
+	for _, result := range results {
+		if err = dec.Decode(result); err != nil {
+			return fmt.Errorf("%v: %w", function, err)
+		}
+	}
+
+	return nil
+}
+
+// readError return the Error of the response, or its status when the body is not an Error
+func readError(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var e Error
+	if err = json.Unmarshal(b, &e); err != nil || e.Message == "" {
+		return fmt.Errorf("remote server answered %v: %s", resp.Status, bytes.TrimSpace(b))
+	}
+
+	return &e
+}
+
+// Tx is a transaction on the server
+type Tx struct {
+	querier
+	// done is closed once the transaction is ended
+	done chan struct{}
+	once sync.Once
+}
+
+// Begin start a new transaction
+func (db *Database) Begin() (*Tx, error) {
+	return db.BeginContext(context.Background())
+}
+
+// BeginContext is Begin with a context, the transaction is rolled back if the context is canceled
+// before it is committed
+func (db *Database) BeginContext(ctx context.Context) (*Tx, error) {
+	var id string
+	if err := db.call(ctx, "Begin", nil, &id); err != nil {
+		return nil, err
+	}
+
+	tx := &Tx{querier: querier{c: db.c, tx: id}, done: make(chan struct{})}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			tx.Rollback() // nolint: errcheck,gosec
+		case <-tx.done:
+		}
+	}()
+
+	return tx, nil
+}
+
+// end the transaction by the Commit or Rollback function
+func (tx *Tx) end(function string) error {
+	tx.once.Do(func() { close(tx.done) })
+
+	return tx.call(context.Background(), function, nil)
+}
+
+// Commit apply the changes made in the transaction
+func (tx *Tx) Commit() error {
+	return tx.end("Commit")
+}
+
+// Rollback discard the changes made in the transaction
+func (tx *Tx) Rollback() error {
+	return tx.end("Rollback")
+}
+
+// SelectChanges return the audit log of the entity, or of all entities when entity is empty
+func (db *Database) SelectChanges(entity, name string) (changes []datastructs.Change, err error) {
+	return db.SelectChangesContext(context.Background(), entity, name)
+}
+
+// SelectChangesContext is SelectChanges with a context bounding the query
+func (db *Database) SelectChangesContext(ctx context.Context, entity, name string) (
+	changes []datastructs.Change, err error) {
+	err = db.call(ctx, "SelectChangesContext", []interface{}{entity, name}, &changes)
+	return changes, err
+}
+
+// SelectChange return the change with the given ID
+func (db *Database) SelectChange(id int64) (change datastructs.Change, err error) {
+	return db.SelectChangeContext(context.Background(), id)
+}
+
+// SelectChangeContext is SelectChange with a context bounding the query
+func (db *Database) SelectChangeContext(ctx context.Context, id int64) (change datastructs.Change, err error) {
+	err = db.call(ctx, "SelectChangeContext", []interface{}{id}, &change)
+	return change, err
+}
+
+// PopulateTestData is not available through the server
+func (db *Database) PopulateTestData(fixturesPath string) (err error) {
+	return fmt.Errorf("test data cannot be loaded through the remote server")
+}
+
+// Close release the idle connections to the server
+func (db *Database) Close() (err error) {
+	db.c.http.CloseIdleConnections()
+	return nil
+}
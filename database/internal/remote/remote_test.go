@@ -0,0 +1,310 @@
+// nolint
+package remote_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/via-justa/admiral/config"
+	"github.com/via-justa/admiral/database"
+	"github.com/via-justa/admiral/database/internal/remote"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// serve return a client of an SQLite database holding the fixtures data served by database.RPCHandler, the served
+// database and the server
+func serve(t *testing.T) (*remote.Database, database.DBInterface, *httptest.Server) {
+	t.Helper()
+
+	serverDB, err := database.Connect(&config.Config{
+		SQLite: config.SQLiteConfig{Path: "remote.sqlite", Memory: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = serverDB.(database.Migrator).Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = serverDB.PopulateTestData("../../../fixtures"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(database.RPCHandler(serverDB))
+
+	t.Cleanup(func() {
+		srv.Close()
+		serverDB.Close()
+	})
+
+	db, err := remote.Connect(config.RemoteConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, serverDB, srv
+}
+
+// post call the function on the server with the gob encoded args and the transaction header tx when set
+func post(t *testing.T, srv *httptest.Server, function, tx string, args ...interface{}) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	enc := gob.NewEncoder(&body)
+	for _, arg := range args {
+		if err := enc.Encode(arg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+remote.Path+function, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", remote.ContentType)
+
+	if tx != "" {
+		req.Header.Set(remote.TxHeader, tx)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func TestTx(t *testing.T) {
+	db, serverDB, _ := serve(t)
+
+	tests := []struct {
+		name      string
+		commit    bool
+		wantSaved bool
+	}{
+		{
+			name:      "commit",
+			commit:    true,
+			wantSaved: true,
+		},
+		{
+			name:      "rollback",
+			commit:    false,
+			wantSaved: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("Database.Begin() error = %v", err)
+			}
+
+			group := &datastructs.Group{Name: "tx-group", Variables: "{}", Enabled: true, Monitored: true}
+			if _, err = tx.InsertGroup(group); err != nil {
+				t.Fatalf("Tx.InsertGroup() error = %v", err)
+			}
+
+			// the group is visible within the transaction only
+			if got, _ := tx.SelectGroup(group.Name); got.ID == 0 {
+				t.Errorf("Tx.SelectGroup() did not return the group inserted in the transaction")
+			}
+
+			if got, _ := db.SelectGroup(group.Name); got.ID != 0 {
+				t.Errorf("Database.SelectGroup() returned the group of the open transaction")
+			}
+
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+
+			if err != nil {
+				t.Fatalf("Tx.Commit() / Tx.Rollback() error = %v", err)
+			}
+
+			got, err := serverDB.SelectGroup(group.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (got.ID != 0) != tt.wantSaved {
+				t.Errorf("Database.Begin() saved = %v, want %v", got.ID != 0, tt.wantSaved)
+			}
+
+			// the ended transaction is forgotten by the server
+			if _, err = tx.SelectGroup(group.Name); !errors.Is(err, sql.ErrTxDone) {
+				t.Errorf("Tx.SelectGroup() after end error = %v, want %v", err, sql.ErrTxDone)
+			}
+
+			if err = tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+				t.Errorf("Tx.Rollback() after end error = %v, want %v", err, sql.ErrTxDone)
+			}
+
+			if got.ID != 0 {
+				if _, err = serverDB.DeleteGroup(&got); err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err = serverDB.PurgeGroup(&got); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestTx_expired(t *testing.T) {
+	defer func(idle time.Duration) { database.RPCTxIdleTimeout = idle }(database.RPCTxIdleTimeout)
+
+	database.RPCTxIdleTimeout = 50 * time.Millisecond
+
+	db, serverDB, _ := serve(t)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Database.Begin() error = %v", err)
+	}
+
+	group := &datastructs.Group{Name: "expired-group", Variables: "{}", Enabled: true, Monitored: true}
+	if _, err = tx.InsertGroup(group); err != nil {
+		t.Fatalf("Tx.InsertGroup() error = %v", err)
+	}
+
+	time.Sleep(10 * database.RPCTxIdleTimeout)
+
+	if _, err = tx.SelectGroup(group.Name); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("Tx.SelectGroup() on expired transaction error = %v, want %v", err, sql.ErrTxDone)
+	}
+
+	if err = tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("Tx.Commit() on expired transaction error = %v, want %v", err, sql.ErrTxDone)
+	}
+
+	// the expired transaction is rolled back
+	if got, _ := serverDB.SelectGroup(group.Name); got.ID != 0 {
+		t.Errorf("Tx.Commit() on expired transaction saved the group")
+	}
+}
+
+func TestCall(t *testing.T) {
+	_, _, srv := serve(t)
+
+	// the ID of an open transaction
+	var openTx string
+	if resp := post(t, srv, "Begin", ""); resp.StatusCode == http.StatusOK {
+		if err := gob.NewDecoder(resp.Body).Decode(&openTx); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		t.Fatalf("Begin status = %v, want %v", resp.Status, http.StatusOK)
+	}
+
+	defer post(t, srv, "Rollback", openTx)
+
+	tests := []struct {
+		name         string
+		function     string
+		tx           string
+		args         []interface{}
+		wantStatus   int
+		wantCode     string
+		wantHostname string
+	}{
+		{
+			name:         "context function",
+			function:     "SelectHostContext",
+			args:         []interface{}{"host1"},
+			wantStatus:   http.StatusOK,
+			wantHostname: "host1",
+		},
+		{
+			name:         "context function within transaction",
+			function:     "SelectHostContext",
+			tx:           openTx,
+			args:         []interface{}{"host2"},
+			wantStatus:   http.StatusOK,
+			wantHostname: "host2",
+		},
+		{
+			name:       "unknown transaction",
+			function:   "SelectHostContext",
+			tx:         "unknown",
+			args:       []interface{}{"host1"},
+			wantStatus: http.StatusConflict,
+			wantCode:   remote.CodeTxDone,
+		},
+		{
+			name:       "function without context",
+			function:   "SelectHost",
+			args:       []interface{}{"host1"},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "begin within a call",
+			function:   "BeginContext",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unknown function",
+			function:   "DropDatabaseContext",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing arguments",
+			function:   "SelectHostContext",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wrong argument type",
+			function:   "SelectHostContext",
+			args:       []interface{}{42},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, srv, tt.function, tt.tx, tt.args...)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("%v status = %v, want %v", tt.function, resp.Status, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				var e remote.Error
+				if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Message == "" {
+					t.Fatalf("%v error body = %v, %v, want an Error", tt.function, e, err)
+				}
+
+				if e.Code != tt.wantCode {
+					t.Errorf("%v error code = %q, want %q", tt.function, e.Code, tt.wantCode)
+				}
+
+				return
+			}
+
+			var host datastructs.Host
+			if err := gob.NewDecoder(resp.Body).Decode(&host); err != nil {
+				t.Fatal(err)
+			}
+
+			if host.Hostname != tt.wantHostname {
+				t.Errorf("%v hostname = %q, want %q", tt.function, host.Hostname, tt.wantHostname)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// nolint: golint
+package sqlite
+
+import (
+	"context"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+const addressColumns = "SELECT address_id, host_id, host, name, address, is_primary FROM address_view"
+
+// SelectAddresses accept hostname and return the host addresses ordered by name
+func (db *Database) SelectAddresses(host string) (addresses datastructs.Addresses, err error) {
+	return db.SelectAddressesContext(context.Background(), host)
+}
+
+// SelectAddressesContext is SelectAddresses with a context bounding the query
+func (db *Database) SelectAddressesContext(ctx context.Context, host string) (
+	addresses datastructs.Addresses, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	err = db.conn().SelectContext(ctx, &addresses, addressColumns+" WHERE host=?", host)
+
+	return addresses, err
+}
+
+// GetAddresses return the addresses of all hosts ordered by hostname and name
+func (db *Database) GetAddresses() (addresses datastructs.Addresses, err error) {
+	return db.GetAddressesContext(context.Background())
+}
+
+// GetAddressesContext is GetAddresses with a context bounding the query
+func (db *Database) GetAddressesContext(ctx context.Context) (addresses datastructs.Addresses, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	err = db.conn().SelectContext(ctx, &addresses, addressColumns)
+
+	return addresses, err
+}
+
+// InsertAddress accept Address to insert or update by host ID and name and return the number of
+// affected rows and error if exists
+func (db *Database) InsertAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.InsertAddressContext(context.Background(), address)
+}
+
+// InsertAddressContext is InsertAddress with a context bounding the query
+func (db *Database) InsertAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	sql := `INSERT INTO addresses (host_id, name, address, is_primary) VALUES (?,?,?,?)
+			ON CONFLICT(host_id, name) DO UPDATE SET address=excluded.address, is_primary=excluded.is_primary`
+
+	res, err := db.conn().ExecContext(ctx, sql, address.HostID, address.Name, address.Address, address.Primary)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	return affected, db.bumpHostRevision(ctx, address.HostID)
+}
+
+// DeleteAddress accept Address to delete by host ID and name and return the number of affected
+// rows and error if exists
+func (db *Database) DeleteAddress(address *datastructs.Address) (affected int64, err error) {
+	return db.DeleteAddressContext(context.Background(), address)
+}
+
+// DeleteAddressContext is DeleteAddress with a context bounding the query
+func (db *Database) DeleteAddressContext(ctx context.Context, address *datastructs.Address) (
+	affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM addresses WHERE host_id=? AND name=?",
+		address.HostID, address.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil || affected == 0 {
+		return affected, err
+	}
+
+	return affected, db.bumpHostRevision(ctx, address.HostID)
+}
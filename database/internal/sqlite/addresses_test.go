@@ -0,0 +1,52 @@
+// nolint: golint
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func TestDatabase_addresses(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	management := datastructs.Address{HostID: testHost1.ID, Name: "management", Address: "10.0.0.1"}
+	public := datastructs.Address{HostID: testHost1.ID, Name: "public", Address: "1.1.1.1", Primary: true}
+
+	for _, address := range []datastructs.Address{management, public} {
+		a := address
+		if affected, err := testDB.InsertAddress(&a); err != nil || affected != 1 {
+			t.Fatalf("Database.InsertAddress() = %v, %v, want 1", affected, err)
+		}
+	}
+
+	got, err := testDB.SelectAddresses("host1")
+	if err != nil {
+		t.Fatalf("Database.SelectAddresses() error = %v", err)
+	} else if len(got) != 2 || got[0].Name != "management" || got[0].Host != "host1" || !got[1].Primary {
+		t.Fatalf("Database.SelectAddresses() = %v, want management and primary public", got)
+	}
+
+	management.Address = "10.0.0.2"
+	if _, err = testDB.InsertAddress(&management); err != nil {
+		t.Fatalf("Database.InsertAddress() error = %v", err)
+	}
+
+	if got, _ = testDB.SelectAddresses("host1"); got[0].Address != "10.0.0.2" {
+		t.Errorf("Database.InsertAddress() did not update address %v", got[0])
+	}
+
+	if affected, err := testDB.DeleteAddress(&public); err != nil || affected != 1 {
+		t.Fatalf("Database.DeleteAddress() = %v, %v, want 1", affected, err)
+	}
+
+	if _, err = testDB.DeleteHost(&testHost1); err != nil {
+		t.Fatalf("Database.DeleteHost() error = %v", err)
+	}
+
+	if got, _ = testDB.GetAddresses(); len(got) != 0 {
+		t.Errorf("Database.GetAddresses() = %v, want deleted host addresses hidden", got)
+	}
+}
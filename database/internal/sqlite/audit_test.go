@@ -0,0 +1,117 @@
+// nolint: golint
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func TestDatabase_SelectChanges(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	changes := []datastructs.Change{
+		{Entity: datastructs.EntityHost, Name: "host1", Action: datastructs.ActionInsert, After: "{}"},
+		{Entity: datastructs.EntityHostGroup, Name: "host1", Related: "group1", Action: datastructs.ActionInsert,
+			After: "[]"},
+		{Entity: datastructs.EntityGroup, Name: "group1", Action: datastructs.ActionDelete, Before: "{}"},
+		{Entity: datastructs.EntityChildGroup, Name: "group2", Related: "group1", Action: datastructs.ActionDelete,
+			Before: "{}"},
+	}
+
+	for i := range changes {
+		id, err := testDB.InsertChange(&changes[i])
+		if err != nil {
+			t.Fatalf("Database.InsertChange() error = %v", err)
+		} else if id == 0 {
+			t.Fatalf("Database.InsertChange() id = 0, want id")
+		}
+
+		changes[i].ID = id
+	}
+
+	tests := []struct {
+		name    string
+		entity  string
+		val     string
+		wantIDs []int64
+		wantErr bool
+	}{
+		{
+			name:    "all changes",
+			wantIDs: []int64{changes[0].ID, changes[1].ID, changes[2].ID, changes[3].ID},
+		},
+		{
+			name:    "host changes",
+			entity:  datastructs.EntityHost,
+			val:     "host1",
+			wantIDs: []int64{changes[0].ID, changes[1].ID},
+		},
+		{
+			name:    "group changes",
+			entity:  datastructs.EntityGroup,
+			val:     "group1",
+			wantIDs: []int64{changes[1].ID, changes[2].ID, changes[3].ID},
+		},
+		{
+			name:   "no changes",
+			entity: datastructs.EntityHost,
+			val:    "host2",
+		},
+		{
+			name:    "unknown entity",
+			entity:  "user",
+			val:     "host1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testDB.SelectChanges(tt.entity, tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Database.SelectChanges() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Database.SelectChanges() = %v, want IDs %v", got, tt.wantIDs)
+			}
+
+			for i := range got {
+				if got[i].ID != tt.wantIDs[i] {
+					t.Errorf("Database.SelectChanges() = %v, want IDs %v", got, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabase_SelectChange(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	change := datastructs.Change{Entity: datastructs.EntityHost, Name: "host1", Action: datastructs.ActionDelete,
+		Before: `{"hostname":"host1"}`, ChangedAt: "2020-12-01T10:00:00Z", Actor: "tester"}
+
+	id, err := testDB.InsertChange(&change)
+	if err != nil {
+		t.Fatalf("Database.InsertChange() error = %v", err)
+	}
+
+	got, err := testDB.SelectChange(id)
+	if err != nil {
+		t.Fatalf("Database.SelectChange() error = %v", err)
+	}
+
+	change.ID = id
+	if got != change {
+		t.Errorf("Database.SelectChange() = %v, want %v", got, change)
+	}
+
+	if got, _ = testDB.SelectChange(id + 1); got.ID != 0 {
+		t.Errorf("Database.SelectChange() = %v, want empty change", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
@@ -164,6 +165,69 @@ func TestDatabase_InsertHost(t *testing.T) {
 	}
 }
 
+func TestDatabase_InsertHost_timestamps(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	unchangedHost1 := testHost1
+	editTestHost2 := testHost2
+	editTestHost2.Enabled = false
+
+	tests := []struct {
+		name        string
+		host        *datastructs.Host
+		wantCreated string
+		wantUpdated string
+	}{
+		{
+			name:        "unchanged host keep updated",
+			host:        &unchangedHost1,
+			wantCreated: testHost1.Created,
+			wantUpdated: testHost1.Updated,
+		},
+		{
+			name:        "changed host set updated",
+			host:        &editTestHost2,
+			wantCreated: testHost2.Created,
+		},
+		{
+			name: "new host set created and updated",
+			host: &createTestHost10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := testDB.InsertHost(tt.host); err != nil {
+				t.Fatalf("Database.InsertHost() error = %v", err)
+			}
+
+			got, err := testDB.SelectHost(tt.host.Hostname)
+			if err != nil {
+				t.Fatalf("Database.SelectHost() error = %v", err)
+			}
+
+			if tt.wantCreated != "" && got.Created != tt.wantCreated {
+				t.Errorf("Database.InsertHost() created = %v, want %v", got.Created, tt.wantCreated)
+			}
+
+			if tt.wantUpdated != "" && got.Updated != tt.wantUpdated {
+				t.Errorf("Database.InsertHost() updated = %v, want %v", got.Updated, tt.wantUpdated)
+			}
+
+			for _, ts := range []string{got.Created, got.Updated} {
+				if _, err := time.Parse(time.RFC3339, ts); err != nil {
+					t.Errorf("Database.InsertHost() timestamp %q error = %v", ts, err)
+				}
+			}
+
+			if tt.wantUpdated == "" && got.Updated <= testHost2.Updated {
+				t.Errorf("Database.InsertHost() updated = %v, want current time", got.Updated)
+			}
+		})
+	}
+}
+
 func TestDatabase_DeleteHost(t *testing.T) {
 	prepEnv()
 
@@ -846,12 +910,19 @@ func TestDatabase_InsertHostGroup(t *testing.T) {
 			wantErr:      false,
 		},
 		{
-			name:         "update testHostGroup1 (host1, group2)",
+			name:         "add second group to testHostGroup1 (host1, group2)",
 			Conn:         testDB.Conn,
 			hostGroup:    &editTestHostGroup1,
 			wantAffected: 1,
 			wantErr:      false,
 		},
+		{
+			name:         "existing testHostGroup1 (host1, group1)",
+			Conn:         testDB.Conn,
+			hostGroup:    &testHostGroup1,
+			wantAffected: 0,
+			wantErr:      false,
+		},
 		{
 			name:         "none-existing FK",
 			Conn:         testDB.Conn,
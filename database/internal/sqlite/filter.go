@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/via-justa/admiral/database/internal/where"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// driver is the sqlite3 driver with the REGEXP and var_value functions used by filters
+const driver = "sqlite3_admiral"
+
+func init() {
+	sql.Register(driver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// `X REGEXP Y` call regexp(Y, X)
+			err := conn.RegisterFunc("regexp", func(re string, s interface{}) (bool, error) {
+				// like the other operators NULL, e.g. of a variable that is not set, does not match
+				text, ok := s.(string)
+				if !ok {
+					return false, nil
+				}
+
+				return regexp.MatchString("(?i)"+re, text)
+			}, true)
+			if err != nil {
+				return err
+			}
+
+			// the bundled sqlite is built without the json1 extension unless the sqlite_json build tag is set,
+			// requiring the tag would fail the filters at runtime for every `go build` and `go install` made
+			// without it. The variables are read by the same function as the files backend instead
+			err = conn.RegisterFunc("var_set", func(variables, path string) bool {
+				_, ok := datastructs.VarValue(variables, path)
+				return ok
+			}, true)
+			if err != nil {
+				return err
+			}
+
+			return conn.RegisterFunc("var_value", func(variables, path string) string {
+				value, _ := datastructs.VarValue(variables, path)
+				return value
+			}, true)
+		},
+	})
+}
+
+var dialect = where.Dialect{Like: "LIKE", Regex: "REGEXP",
+	Var: func(path string) (string, []interface{}) {
+		return "(CASE WHEN var_set(variables, ?) THEN var_value(variables, ?) END)", []interface{}{path, path}
+	},
+	HasVar: func(path string) (string, []interface{}) {
+		return "var_set(variables, ?)", []interface{}{path}
+	},
+}
+
+// hostColumns map the host filter fields to host_view
+var hostColumns = map[string]where.Column{
+	"hostname":  {Expr: "hostname"},
+	"ip":        {Expr: "host"},
+	"domain":    {Expr: "domain"},
+	"enabled":   {Expr: "enabled"},
+	"monitored": {Expr: "monitored"},
+	"group": {Expr: "hv.`group`",
+		Exists: "SELECT 1 FROM hostgroup_view hv WHERE hv.host_id=host_view.host_id AND %v"},
+	// the groups inherited the same way as host_view.inherited_groups
+	"inherited_group": {Expr: "g.name", Exists: `WITH RECURSIVE inherited (child_id, parent_id) AS (
+		SELECT child_id, parent_id FROM childgroups_view
+		UNION ALL
+		SELECT cv.child_id, i.parent_id FROM inherited i JOIN childgroups_view cv ON i.child_id = cv.parent_id)
+	SELECT 1 FROM hostgroup_view hv JOIN inherited i ON hv.group_id = i.child_id JOIN ` + "`group`" + ` g
+		ON i.parent_id = g.id WHERE hv.host_id=host_view.host_id AND %v`},
+}
+
+// groupColumns map the group filter fields to groups_view
+var groupColumns = map[string]where.Column{
+	"name":      {Expr: "name"},
+	"enabled":   {Expr: "enabled"},
+	"monitored": {Expr: "monitored"},
+}
+
+// FilterHosts return the hosts matching the filter ordered by hostname
+func (db *Database) FilterHosts(filter datastructs.Filter) (hosts []datastructs.Host, err error) {
+	return db.FilterHostsContext(context.Background(), filter)
+}
+
+// FilterHostsContext is FilterHosts with a context bounding the query
+func (db *Database) FilterHostsContext(ctx context.Context, filter datastructs.Filter) (
+	hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = filter.Validate(datastructs.HostFilterFields); err != nil {
+		return hosts, err
+	}
+
+	clause, args, err := where.Clause(filter, hostColumns, dialect)
+	if err != nil {
+		return hosts, err
+	}
+
+	err = db.conn().SelectContext(ctx, &hosts, "SELECT host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, direct_group, inherited_groups, created, updated, revision FROM host_view WHERE "+clause+
+		" ORDER BY hostname", args...)
+
+	return hosts, err
+}
+
+// FilterGroups return the groups matching the filter ordered by ID
+func (db *Database) FilterGroups(filter datastructs.Filter) (groups []datastructs.Group, err error) {
+	return db.FilterGroupsContext(context.Background(), filter)
+}
+
+// FilterGroupsContext is FilterGroups with a context bounding the query
+func (db *Database) FilterGroupsContext(ctx context.Context, filter datastructs.Filter) (
+	groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	if err = filter.Validate(datastructs.GroupFilterFields); err != nil {
+		return groups, err
+	}
+
+	clause, args, err := where.Clause(filter, groupColumns, dialect)
+	if err != nil {
+		return groups, err
+	}
+
+	err = db.conn().SelectContext(ctx, &groups, "SELECT group_id, name, variables, enabled, monitored, num_children,"+
+		" num_hosts, child_groups, created, updated, revision FROM `groups_view` WHERE "+clause+" ORDER BY group_id",
+		args...)
+
+	return groups, err
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `changed_at` varchar(32) NOT NULL,
+  `actor` varchar(255) NOT NULL,
+  `entity` varchar(32) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `related` varchar(255) NOT NULL DEFAULT '',
+  `action` varchar(16) NOT NULL,
+  `before_state` longtext NOT NULL DEFAULT '',
+  `after_state` longtext NOT NULL DEFAULT '',
+  `cause` integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS `audit_log_name` ON `audit_log` (`name`);
+
+CREATE INDEX IF NOT EXISTS `audit_log_related` ON `audit_log` (`related`);
+
+CREATE INDEX IF NOT EXISTS `audit_log_cause` ON `audit_log` (`cause`);
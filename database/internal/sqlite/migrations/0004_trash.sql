@@ -1,45 +1,9 @@
-CREATE TABLE `group` (
-  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
-  `name` varchar(255) NOT NULL,
-  `variables` varchar(8192) NOT NULL DEFAULT '{}',
-  `enabled` tinyint(1) NOT NULL DEFAULT '0',
-  `monitored` tinyint(1) NOT NULL DEFAULT '0',
---   `created` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
---   `updated` TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  UNIQUE (`name`)
-);
+-- deleted hosts and groups are kept in the trash until purged, the views hide them and their relationships
+ALTER TABLE `host` ADD COLUMN `deleted` varchar(32) NOT NULL DEFAULT '';
 
-CREATE TABLE `childgroups` (
-  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
-  `child_id` integer NOT NULL,
-  `parent_id` integer NOT NULL,
-  UNIQUE (`child_id`,`parent_id`),
-  CONSTRAINT `childgroups_ibfk_2` FOREIGN KEY (`parent_id`) REFERENCES `group` (`id`),
-  CONSTRAINT `childgroups_ibfk_3` FOREIGN KEY (`child_id`) REFERENCES `group` (`id`) ON DELETE CASCADE
-);
+ALTER TABLE `group` ADD COLUMN `deleted` varchar(32) NOT NULL DEFAULT '';
 
-CREATE TABLE `host` (
-  `id` integer  NOT NULL PRIMARY KEY AUTOINCREMENT,
-  `host` varchar(255) NOT NULL,
-  `hostname` varchar(255) NOT NULL,
-  `domain` varchar(250) DEFAULT NULL,
-  `variables` longtext NOT NULL DEFAULT '{}',
-  `enabled` tinyint(1) NOT NULL,
-  `monitored` tinyint(1) NOT NULL DEFAULT '0',
---   `created` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
---   `updated` TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  UNIQUE (`host`),
-  UNIQUE (`hostname`)
-);
-
-CREATE TABLE `hostgroups` (
-  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
-  `host_id` integer NOT NULL,
-  `group_id` integer NOT NULL,
-  UNIQUE (`host_id`),
-  FOREIGN KEY (`host_id`) REFERENCES `host` (`id`) ON DELETE CASCADE,
-  FOREIGN KEY (`group_id`) REFERENCES `group` (`id`) ON DELETE CASCADE
-);
+DROP VIEW IF EXISTS `hostgroup_view`;
 
 CREATE VIEW IF NOT EXISTS `hostgroup_view` AS
 SELECT
@@ -53,8 +17,11 @@ LEFT JOIN `group`
     ON `hostgroups`.`group_id` = `group`.`id`
 LEFT JOIN `host`
     ON `hostgroups`.`host_id` = `host`.`id`
+WHERE `host`.`deleted` = '' AND `group`.`deleted` = ''
 ORDER BY `group`.`name`;
 
+DROP VIEW IF EXISTS `childgroups_view`;
+
 CREATE VIEW IF NOT EXISTS `childgroups_view` AS
 SELECT
     `childgroups`.`id` AS `relationship_id`,
@@ -67,8 +34,11 @@ LEFT JOIN `group` `gparent`
 	ON `childgroups`.`parent_id` = `gparent`.`id`
 LEFT JOIN `group` `gchild`
 	ON `childgroups`.`child_id` = `gchild`.`id`
+WHERE `gparent`.`deleted` = '' AND `gchild`.`deleted` = ''
 ORDER BY `gparent`.`name`;
 
+DROP VIEW IF EXISTS `host_view`;
+
 CREATE VIEW IF NOT EXISTS `host_view` AS
 WITH RECURSIVE inherited (child_id, parent_id) AS (
 SELECT
@@ -92,6 +62,8 @@ SELECT
     `host`.`enabled` AS `enabled`,
     `host`.`monitored` AS `monitored`,
     `host`.`variables` AS `variables`,
+    `host`.`created` AS `created`,
+    `host`.`updated` AS `updated`,
     ifnull(group_concat(distinct `g1`.`name`),"") AS `direct_group`,
     ifnull(group_concat(distinct `g2`.`name`),"") AS `inherited_groups`
 FROM
@@ -104,11 +76,15 @@ LEFT JOIN `group` g1 ON
 	hv.group_id = g1.id
 LEFT JOIN `group` g2 ON
 	i.parent_id = g2.id
+WHERE
+	host.deleted = ''
 GROUP BY
 	host.hostname
 ORDER BY
 	host.hostname;
 
+DROP VIEW IF EXISTS `groups_view`;
+
 CREATE VIEW IF NOT EXISTS `groups_view` AS 
 WITH RECURSIVE inherited(`child_id`, `parent_id`) AS (
 SELECT
@@ -131,7 +107,9 @@ SELECT
 	COUNT(DISTINCT h.id) AS `num_hosts`,
 	COUNT(DISTINCT `g2`.`name`) AS `num_children`,
 	IFNULL(GROUP_CONCAT(DISTINCT `g2`.`name`),'') AS `child_groups`,
-	`g1`.`variables` AS `variables`
+	`g1`.`variables` AS `variables`,
+	`g1`.`created` AS `created`,
+	`g1`.`updated` AS `updated`
 FROM `group` g1
 LEFT JOIN `inherited` `i` ON
      `g1`.`id` = `i`.`parent_id`
@@ -140,6 +118,7 @@ LEFT JOIN `group` `g2` ON
 LEFT JOIN hostgroups hg ON
 	`hg`.`group_id` = `g1`.`id` 
 LEFT JOIN host h ON
-	`h`.`id` = `hg`.`host_id` 
+	`h`.`id` = `hg`.`host_id` AND `h`.`deleted` = ''
+WHERE `g1`.`deleted` = ''
 GROUP BY `g1`.`id` 
 ORDER BY `g1`.`id`;
@@ -0,0 +1,24 @@
+-- a host can have multiple named addresses (e.g. management, storage, public), at most one of them primary
+CREATE TABLE IF NOT EXISTS `addresses` (
+  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `host_id` integer NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `address` varchar(255) NOT NULL,
+  `is_primary` tinyint(1) NOT NULL DEFAULT '0',
+  UNIQUE (`host_id`,`name`),
+  FOREIGN KEY (`host_id`) REFERENCES `host` (`id`) ON DELETE CASCADE
+);
+
+CREATE VIEW IF NOT EXISTS `address_view` AS
+SELECT
+    `addresses`.`id` AS `address_id`,
+    `addresses`.`host_id` AS `host_id`,
+    `host`.`hostname` AS `host`,
+    `addresses`.`name` AS `name`,
+    `addresses`.`address` AS `address`,
+    `addresses`.`is_primary` AS `is_primary`
+FROM `addresses`
+JOIN `host`
+    ON `addresses`.`host_id` = `host`.`id`
+WHERE `host`.`deleted` = ''
+ORDER BY `host`.`hostname`, `addresses`.`name`;
@@ -0,0 +1,28 @@
+-- the audit log no longer records the change causing a relationship change, sqlite cannot drop the column in
+-- place so the table is rebuilt
+DROP INDEX IF EXISTS `audit_log_cause`;
+
+CREATE TABLE `audit_log_new` (
+  `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `changed_at` varchar(32) NOT NULL,
+  `actor` varchar(255) NOT NULL,
+  `entity` varchar(32) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `related` varchar(255) NOT NULL DEFAULT '',
+  `action` varchar(16) NOT NULL,
+  `before_state` longtext NOT NULL DEFAULT '',
+  `after_state` longtext NOT NULL DEFAULT ''
+);
+
+INSERT INTO `audit_log_new` (`id`, `changed_at`, `actor`, `entity`, `name`, `related`, `action`, `before_state`,
+  `after_state`)
+SELECT `id`, `changed_at`, `actor`, `entity`, `name`, `related`, `action`, `before_state`, `after_state`
+FROM `audit_log`;
+
+DROP TABLE `audit_log`;
+
+ALTER TABLE `audit_log_new` RENAME TO `audit_log`;
+
+CREATE INDEX IF NOT EXISTS `audit_log_name` ON `audit_log` (`name`);
+
+CREATE INDEX IF NOT EXISTS `audit_log_related` ON `audit_log` (`related`);
@@ -0,0 +1,139 @@
+// nolint: golint
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// GetDeletedHosts return all hosts in the trash
+func (db *Database) GetDeletedHosts() (hosts []datastructs.Host, err error) {
+	return db.GetDeletedHostsContext(context.Background())
+}
+
+// GetDeletedHostsContext is GetDeletedHosts with a context bounding the query
+func (db *Database) GetDeletedHostsContext(ctx context.Context) (hosts []datastructs.Host, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	err = db.conn().SelectContext(ctx, &hosts, "SELECT id AS host_id, host, hostname, domain, variables, enabled,"+
+		" monitored, created, updated, deleted, revision FROM host WHERE deleted<>'' ORDER BY hostname")
+
+	return hosts, err
+}
+
+// RestoreHost accept Host to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (db *Database) RestoreHost(host *datastructs.Host) (affected int64, err error) {
+	return db.RestoreHostContext(context.Background(), host)
+}
+
+// RestoreHostContext is RestoreHost with a context bounding the query
+func (db *Database) RestoreHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "UPDATE host SET deleted='', revision=revision+1, updated=?"+
+		" WHERE id=? AND deleted<>''", timestamp(), host.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// PurgeHost accept Host in the trash to delete permanently and return the number of affected rows and error if exists
+func (db *Database) PurgeHost(host *datastructs.Host) (affected int64, err error) {
+	return db.PurgeHostContext(context.Background(), host)
+}
+
+// PurgeHostContext is PurgeHost with a context bounding the query
+func (db *Database) PurgeHostContext(ctx context.Context, host *datastructs.Host) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM host WHERE id=? AND deleted<>''", host.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// GetDeletedGroups return all groups in the trash
+func (db *Database) GetDeletedGroups() (groups []datastructs.Group, err error) {
+	return db.GetDeletedGroupsContext(context.Background())
+}
+
+// GetDeletedGroupsContext is GetDeletedGroups with a context bounding the query
+func (db *Database) GetDeletedGroupsContext(ctx context.Context) (groups []datastructs.Group, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	err = db.conn().SelectContext(ctx, &groups, "SELECT id AS group_id, name, variables, enabled, monitored,"+
+		" created, updated, deleted, revision FROM `group` WHERE deleted<>'' ORDER BY name")
+
+	return groups, err
+}
+
+// RestoreGroup accept Group to move out of the trash together with its relationships and return the
+// number of affected rows and error if exists
+func (db *Database) RestoreGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.RestoreGroupContext(context.Background(), group)
+}
+
+// RestoreGroupContext is RestoreGroup with a context bounding the query
+func (db *Database) RestoreGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	res, err := db.conn().ExecContext(ctx, "UPDATE `group` SET deleted='', revision=revision+1, updated=?"+
+		" WHERE id=? AND deleted<>''", timestamp(), group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// PurgeGroup accept Group in the trash to delete permanently and return the number of affected rows and error if exists
+func (db *Database) PurgeGroup(group *datastructs.Group) (affected int64, err error) {
+	return db.PurgeGroupContext(context.Background(), group)
+}
+
+// PurgeGroupContext is PurgeGroup with a context bounding the query
+func (db *Database) PurgeGroupContext(ctx context.Context, group *datastructs.Group) (affected int64, err error) {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	// the parent relationships do not cascade, delete them first
+	_, err = db.conn().ExecContext(ctx, "DELETE FROM childgroups WHERE parent_id IN"+
+		" (SELECT id FROM `group` WHERE id=? AND deleted<>'')", group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.conn().ExecContext(ctx, "DELETE FROM `group` WHERE id=? AND deleted<>''", group.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// inTrash return error if a record with the given unique value is in the trash
+func (db *Database) inTrash(ctx context.Context, table, column, val string) error {
+	var deleted []string
+
+	err := db.conn().SelectContext(ctx, &deleted, "SELECT deleted FROM `"+table+"` WHERE "+column+"=? AND deleted<>''",
+		val)
+	if err != nil {
+		return err
+	} else if len(deleted) > 0 {
+		return fmt.Errorf("%v %v is in the trash, restore or purge it first", table, val)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// nolint: golint
+package sqlite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func TestDatabase_trashHost(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	if _, err := testDB.DeleteHost(&testHost1); err != nil {
+		t.Fatalf("Database.DeleteHost() error = %v", err)
+	}
+
+	if got, _ := testDB.SelectHost("host1"); got.ID != 0 {
+		t.Errorf("Database.SelectHost() = %v, want deleted host hidden", got)
+	}
+
+	if got, _ := testDB.SelectHostGroup("host1"); len(got) != 0 {
+		t.Errorf("Database.SelectHostGroup() = %v, want deleted host memberships hidden", got)
+	}
+
+	deleted, err := testDB.GetDeletedHosts()
+	if err != nil {
+		t.Fatalf("Database.GetDeletedHosts() error = %v", err)
+	} else if len(deleted) != 1 || deleted[0].Hostname != "host1" || deleted[0].Deleted == "" {
+		t.Fatalf("Database.GetDeletedHosts() = %v, want host1", deleted)
+	}
+
+	if _, err = testDB.InsertHost(&testHost1); err == nil {
+		t.Errorf("Database.InsertHost() error = nil, want host in the trash error")
+	}
+
+	sameIP := datastructs.Host{Host: testHost1.Host, Hostname: "host10", Variables: "{}"}
+	if _, err = testDB.InsertHost(&sameIP); err == nil || !strings.Contains(err.Error(), "is in the trash") {
+		t.Errorf("Database.InsertHost() error = %v, want IP of host in the trash error", err)
+	}
+
+	if affected, err := testDB.RestoreHost(&deleted[0]); err != nil || affected != 1 {
+		t.Fatalf("Database.RestoreHost() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ := testDB.SelectHostGroup("host1"); len(got) != 1 || got[0].Group != "group1" {
+		t.Errorf("Database.SelectHostGroup() = %v, want restored group1 membership", got)
+	}
+
+	if affected, _ := testDB.PurgeHost(&testHost1); affected != 0 {
+		t.Errorf("Database.PurgeHost() = %v, want 0 for host not in the trash", affected)
+	}
+
+	if _, err = testDB.DeleteHost(&testHost1); err != nil {
+		t.Fatalf("Database.DeleteHost() error = %v", err)
+	}
+
+	if affected, err := testDB.PurgeHost(&testHost1); err != nil || affected != 1 {
+		t.Fatalf("Database.PurgeHost() = %v, %v, want 1", affected, err)
+	}
+
+	if deleted, _ = testDB.GetDeletedHosts(); len(deleted) != 0 {
+		t.Errorf("Database.GetDeletedHosts() = %v, want empty trash", deleted)
+	}
+}
+
+func TestDatabase_trashGroup(t *testing.T) {
+	prepEnv()
+
+	defer testDB.Conn.Close()
+
+	// group4 is both a child of group5 and the parent of group3
+	if _, err := testDB.DeleteGroup(&testGroup4); err != nil {
+		t.Fatalf("Database.DeleteGroup() error = %v", err)
+	}
+
+	if got, _ := testDB.GetChildGroups(); len(got) != 0 {
+		t.Errorf("Database.GetChildGroups() = %v, want deleted group relationships hidden", got)
+	}
+
+	deleted, err := testDB.GetDeletedGroups()
+	if err != nil {
+		t.Fatalf("Database.GetDeletedGroups() error = %v", err)
+	} else if len(deleted) != 1 || deleted[0].Name != "group4" {
+		t.Fatalf("Database.GetDeletedGroups() = %v, want group4", deleted)
+	}
+
+	if affected, err := testDB.RestoreGroup(&deleted[0]); err != nil || affected != 1 {
+		t.Fatalf("Database.RestoreGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ := testDB.GetChildGroups(); len(got) != 2 {
+		t.Errorf("Database.GetChildGroups() = %v, want restored relationships", got)
+	}
+
+	if _, err = testDB.DeleteGroup(&testGroup4); err != nil {
+		t.Fatalf("Database.DeleteGroup() error = %v", err)
+	}
+
+	if affected, err := testDB.PurgeGroup(&testGroup4); err != nil || affected != 1 {
+		t.Fatalf("Database.PurgeGroup() = %v, %v, want 1", affected, err)
+	}
+
+	if got, _ := testDB.SelectGroup("group3"); got.ID == 0 {
+		t.Errorf("Database.SelectGroup() = %v, want child group3 kept", got)
+	}
+}
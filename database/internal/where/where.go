@@ -0,0 +1,137 @@
+// Package where translate host and group filters to SQL conditions for the SQL database backends.
+package where
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// escape is the LIKE escape character, `\` would need escaping itself in MariaDB string literals
+const escape = "!"
+
+// Dialect is the SQL flavour of a backend
+type Dialect struct {
+	// Like is the case insensitive LIKE operator
+	Like string
+	// Regex is the case insensitive regular expression match operator
+	Regex string
+	// Fold is set when Like and Regex are case sensitive, e.g. under a binary collation. The LIKE operands are
+	// then lowered and the regular expressions are given the (?i) flag
+	Fold bool
+	// Var return the expression of the variable at the dot separated path, strings without quotes and other
+	// values as json, and its arguments
+	Var func(path string) (expr string, args []interface{})
+	// HasVar return the condition true when the variable at the dot separated path is set and its arguments
+	HasVar func(path string) (clause string, args []interface{})
+}
+
+// Column is the SQL expression a filter field is compared to. Fields with many values, like the groups of a host,
+// set Exists to a subquery with %v in place of the comparison of Expr, the field match when it returns rows
+type Column struct {
+	Expr   string
+	Exists string
+}
+
+// Clause return the filter as SQL condition with ? placeholders and its arguments. The filter fields must be
+// columns keys
+func Clause(f datastructs.Filter, columns map[string]Column, d Dialect) (clause string, args []interface{},
+	err error) {
+	switch {
+	case len(f.And) > 0:
+		return join(f.And, " AND ", columns, d)
+	case len(f.Or) > 0:
+		return join(f.Or, " OR ", columns, d)
+	case f.Field == "":
+		return "1=1", nil, nil
+	}
+
+	var column Column
+
+	switch {
+	case f.Field == datastructs.HasVarField:
+		clause, args = d.HasVar(f.Value)
+		return clause, args, nil
+	case strings.HasPrefix(f.Field, datastructs.VarPrefix):
+		column.Expr, args = d.Var(strings.TrimPrefix(f.Field, datastructs.VarPrefix))
+	default:
+		var ok bool
+		if column, ok = columns[f.Field]; !ok {
+			return "", nil, fmt.Errorf("unknown filter field %v", f.Field)
+		}
+	}
+
+	switch f.Match {
+	case datastructs.MatchExact:
+		if f.IsBool() {
+			clause, args = column.Expr+"=?", append(args, f.Bool())
+		} else {
+			clause, args = column.Expr+"=?", append(args, f.Value)
+		}
+	case datastructs.MatchPrefix:
+		clause, args = d.like(column.Expr), append(args, escapeLike(f.Value)+"%")
+	case datastructs.MatchGlob:
+		clause, args = d.like(column.Expr), append(args, globLike(f.Value))
+	case datastructs.MatchRegex:
+		clause, args = column.Expr+" "+d.Regex+" ?", append(args, d.regex(f.Value))
+	default:
+		return "", nil, fmt.Errorf("unknown filter match %q", f.Match)
+	}
+
+	if column.Exists != "" {
+		clause = "EXISTS (" + fmt.Sprintf(column.Exists, clause) + ")"
+	}
+
+	return clause, args, nil
+}
+
+func join(filters []datastructs.Filter, sep string, columns map[string]Column, d Dialect) (clause string,
+	args []interface{}, err error) {
+	clauses := make([]string, len(filters))
+
+	for i := range filters {
+		var nested []interface{}
+
+		if clauses[i], nested, err = Clause(filters[i], columns, d); err != nil {
+			return "", nil, err
+		}
+
+		args = append(args, nested...)
+	}
+
+	return "(" + strings.Join(clauses, sep) + ")", args, nil
+}
+
+// JSONPath return the MariaDB JSON path of the dot separated variable path
+func JSONPath(path string) string {
+	return `$."` + strings.Join(datastructs.VarPath(path), `"."`) + `"`
+}
+
+// like return the case insensitive LIKE condition of expr
+func (d Dialect) like(expr string) string {
+	if d.Fold {
+		return "LOWER(" + expr + ") " + d.Like + " LOWER(?) ESCAPE '" + escape + "'"
+	}
+
+	return expr + " " + d.Like + " ? ESCAPE '" + escape + "'"
+}
+
+// regex return the case insensitive regular expression of re
+func (d Dialect) regex(re string) string {
+	if d.Fold {
+		return "(?i)" + re
+	}
+
+	return re
+}
+
+// escapeLike escape the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(escape, escape+escape, "%", escape+"%", "_", escape+"_").Replace(s)
+}
+
+// globLike return the LIKE pattern of a glob pattern
+func globLike(pattern string) string {
+	return strings.NewReplacer("*", "%", "?", "_").Replace(escapeLike(pattern))
+}
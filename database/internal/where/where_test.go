@@ -0,0 +1,60 @@
+// nolint
+package where
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/via-justa/admiral/datastructs"
+)
+
+func TestClause(t *testing.T) {
+	columns := map[string]Column{
+		"hostname": {Expr: "hostname"},
+		"enabled":  {Expr: "enabled"},
+		"group":    {Expr: "hv.name", Exists: "SELECT 1 FROM hv WHERE hv.host_id=h.id AND %v"},
+	}
+	d := Dialect{Like: "ILIKE", Regex: "~*"}
+
+	tests := []struct {
+		filter     string
+		fold       bool
+		wantClause string
+		wantArgs   []interface{}
+		wantErr    bool
+	}{
+		{filter: "", wantClause: "1=1"},
+		{filter: "hostname=web_1 and enabled=false", wantClause: "(hostname=? AND enabled=?)",
+			wantArgs: []interface{}{"web_1", false}},
+		{filter: "hostname^=web_1!", wantClause: "hostname ILIKE ? ESCAPE '!'", wantArgs: []interface{}{"web!_1!!%"}},
+		{filter: "hostname*=*web?%", wantClause: "hostname ILIKE ? ESCAPE '!'", wantArgs: []interface{}{"%web_!%"}},
+		{filter: "hostname~=^web or group=web", wantClause: "(hostname ~* ? OR EXISTS (SELECT 1 FROM hv WHERE" +
+			" hv.host_id=h.id AND hv.name=?))", wantArgs: []interface{}{"^web", "web"}},
+		{filter: "domain=prod.local", wantErr: true},
+		{filter: "hostname^=Web", fold: true, wantClause: "LOWER(hostname) LIKE LOWER(?) ESCAPE '!'",
+			wantArgs: []interface{}{"Web%"}},
+		{filter: "hostname~=^Web", fold: true, wantClause: "hostname REGEXP ?", wantArgs: []interface{}{"(?i)^Web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := datastructs.ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := d
+			if tt.fold {
+				d = Dialect{Like: "LIKE", Regex: "REGEXP", Fold: true}
+			}
+
+			gotClause, gotArgs, err := Clause(f, columns, d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Clause() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if gotClause != tt.wantClause || !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Clause() = %v, %v, want %v, %v", gotClause, gotArgs, tt.wantClause, tt.wantArgs)
+			}
+		})
+	}
+}
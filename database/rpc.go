@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/via-justa/admiral/database/internal/remote"
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// RPCPath is the path the database functions are served at by RPCHandler
+const RPCPath = remote.Path
+
+// RPCTxIdleTimeout is the time a transaction is kept open without calls before it is rolled back, it is read
+// when the handler is created by RPCHandler
+var RPCTxIdleTimeout = time.Minute
+
+// rpcMaxBodySize is the largest arguments accepted by a call
+const rpcMaxBodySize = 32 << 20
+
+var (
+	querierType     = reflect.TypeOf((*Querier)(nil)).Elem()
+	dbInterfaceType = reflect.TypeOf((*DBInterface)(nil)).Elem()
+)
+
+// errUnknownFunction is returned for the functions that are not served
+var errUnknownFunction = errors.New("unknown function")
+
+// RPCHandler return the handler serving the database functions of db to the remote backend, a connection
+// configured with the remote section perform its functions through it
+func RPCHandler(db DBInterface) http.Handler {
+	return &rpcServer{db: db, idle: RPCTxIdleTimeout, txs: map[string]*rpcTx{}}
+}
+
+// rpcServer serve the database functions and keep the transactions open between the calls made within them
+type rpcServer struct {
+	db   DBInterface
+	idle time.Duration
+
+	mu  sync.Mutex
+	txs map[string]*rpcTx
+}
+
+// rpcTx is an open transaction, its calls are serialized as a transaction is not safe for concurrent use
+type rpcTx struct {
+	mu     sync.Mutex
+	tx     Tx
+	cancel context.CancelFunc
+	idle   *time.Timer
+}
+
+func (s *rpcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeRPCError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method))
+
+		return
+	}
+
+	function := strings.TrimPrefix(r.URL.Path, RPCPath)
+	body := http.MaxBytesReader(w, r.Body, rpcMaxBodySize)
+
+	var (
+		results []interface{}
+		err     error
+	)
+
+	switch function {
+	case "Ping":
+	case "Begin":
+		var id string
+		if id, err = s.begin(); err == nil {
+			results = []interface{}{id}
+		}
+	case "Commit", "Rollback":
+		err = s.end(r.Header.Get(remote.TxHeader), function == "Commit")
+	default:
+		if id := r.Header.Get(remote.TxHeader); id != "" {
+			results, err = s.callTx(r.Context(), id, function, body)
+		} else {
+			results, err = call(r.Context(), s.db, dbInterfaceType, function, body)
+		}
+	}
+
+	if err != nil {
+		writeRPCError(w, rpcStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", remote.ContentType)
+
+	enc := gob.NewEncoder(w)
+	for _, result := range results {
+		if err = enc.Encode(result); err != nil {
+			// the status is already sent, the client fails to decode the missing results
+			return
+		}
+	}
+}
+
+// begin start a transaction and return its ID, the transaction outlive the request and is rolled back once
+// idle for RPCTxIdleTimeout
+func (s *rpcServer) begin() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	id := hex.EncodeToString(b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tx, err := s.db.BeginContext(ctx)
+	if err != nil {
+		cancel()
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.txs[id] = &rpcTx{tx: tx, cancel: cancel, idle: time.AfterFunc(s.idle, func() {
+		s.end(id, false) // nolint: errcheck,gosec
+	})}
+
+	return id, nil
+}
+
+// end commit or roll back the transaction and forget it
+func (s *rpcServer) end(id string, commit bool) error {
+	s.mu.Lock()
+	t, ok := s.txs[id]
+	delete(s.txs, id)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("transaction %q: %w", id, sql.ErrTxDone)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.idle.Stop()
+	defer t.cancel()
+
+	if commit {
+		return t.tx.Commit()
+	}
+
+	return t.tx.Rollback()
+}
+
+// callTx call the function within the transaction id
+func (s *rpcServer) callTx(ctx context.Context, id, function string, body io.Reader) ([]interface{}, error) {
+	s.mu.Lock()
+	t, ok := s.txs[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("transaction %q: %w", id, sql.ErrTxDone)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.idle.Reset(s.idle)
+
+	return call(ctx, t.tx, querierType, function, body)
+}
+
+// call decode the arguments of the function of q from body, call it with ctx and return its results without the
+// error. Only the context functions of iface are called, the iterate functions return the records of the page
+func call(ctx context.Context, q Querier, iface reflect.Type, function string, body io.Reader) (
+	[]interface{}, error) {
+	dec := gob.NewDecoder(body)
+
+	switch function {
+	case "IterateHostsContext":
+		var page datastructs.Page
+		if err := dec.Decode(&page); err != nil {
+			return nil, &rpcDecodeError{err}
+		}
+
+		var hosts []datastructs.Host
+
+		err := q.IterateHostsContext(ctx, page, func(host *datastructs.Host) error {
+			hosts = append(hosts, *host)
+			return nil
+		})
+
+		return []interface{}{hosts}, err
+	case "IterateGroupsContext":
+		var page datastructs.Page
+		if err := dec.Decode(&page); err != nil {
+			return nil, &rpcDecodeError{err}
+		}
+
+		var groups []datastructs.Group
+
+		err := q.IterateGroupsContext(ctx, page, func(group *datastructs.Group) error {
+			groups = append(groups, *group)
+			return nil
+		})
+
+		return []interface{}{groups}, err
+	case "BeginContext":
+		return nil, fmt.Errorf("%v: %w", function, errUnknownFunction)
+	}
+
+	if _, ok := iface.MethodByName(function); !ok || !strings.HasSuffix(function, "Context") {
+		return nil, fmt.Errorf("%v: %w", function, errUnknownFunction)
+	}
+
+	method := reflect.ValueOf(q).MethodByName(function)
+	methodType := method.Type()
+
+	args := []reflect.Value{reflect.ValueOf(ctx)}
+
+	for i := 1; i < methodType.NumIn(); i++ {
+		arg := reflect.New(methodType.In(i))
+		if err := dec.Decode(arg.Interface()); err != nil {
+			return nil, &rpcDecodeError{fmt.Errorf("%v argument %v: %w", function, i, err)}
+		}
+
+		args = append(args, arg.Elem())
+	}
+
+	out := method.Call(args)
+
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, len(out)-1)
+	for _, v := range out[:len(out)-1] {
+		results = append(results, v.Interface())
+	}
+
+	return results, nil
+}
+
+// rpcDecodeError is returned for arguments that cannot be decoded
+type rpcDecodeError struct{ err error }
+
+func (e *rpcDecodeError) Error() string { return e.err.Error() }
+
+func (e *rpcDecodeError) Unwrap() error { return e.err }
+
+// rpcStatus return the http status of the call error
+func rpcStatus(err error) int {
+	var decodeErr *rpcDecodeError
+
+	switch {
+	case errors.Is(err, errUnknownFunction):
+		return http.StatusNotFound
+	case errors.As(err, &decodeErr):
+		return http.StatusBadRequest
+	case errors.Is(err, datastructs.ErrRevisionConflict), errors.Is(err, sql.ErrTxDone):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// writeRPCError write the error in the format expected by the remote backend
+func writeRPCError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(remote.Error{Message: err.Error(), Code: remote.ErrorCode(err)}) // nolint: errcheck,gosec
+}
@@ -0,0 +1,543 @@
+package datastructs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Match is how a Filter compares a field to its value
+type Match string
+
+// Filter matches, exact matches are case sensitive while prefix, glob and regex matches are not.
+// Glob patterns support `*` for any text and `?` for any single character
+const (
+	MatchExact  Match = "="
+	MatchPrefix Match = "^="
+	MatchGlob   Match = "*="
+	MatchRegex  Match = "~="
+)
+
+// matches in the order they are looked for while parsing, longer operators first
+var matches = []Match{MatchPrefix, MatchGlob, MatchRegex, MatchExact}
+
+// HostFilterFields are the fields hosts can be filtered by. `group` and `inherited_group` match when any of
+// the direct or inherited groups of the host match
+var HostFilterFields = []string{"hostname", "ip", "domain", "enabled", "monitored", "group", "inherited_group"}
+
+// GroupFilterFields are the fields groups can be filtered by
+var GroupFilterFields = []string{"name", "enabled", "monitored"}
+
+// Variable filter fields. `var.` followed by the dot separated keys of a variable, e.g. `var.os.family`, match
+// the variable value: strings without quotes, other values as json. `has_var` match hosts or groups setting the
+// variable at the dot separated keys given as value
+const (
+	VarPrefix   = "var."
+	HasVarField = "has_var"
+)
+
+// boolFields are matched as booleans, only exactly
+var boolFields = map[string]bool{"enabled": true, "monitored": true}
+
+// Filter select hosts or groups by their fields. A filter is either a single field predicate or, when `And` or
+// `Or` is set, all or any of the nested filters. The zero Filter matches everything
+type Filter struct {
+	Field string   `json:"field,omitempty"`
+	Match Match    `json:"match,omitempty"`
+	Value string   `json:"value,omitempty"`
+	And   []Filter `json:"and,omitempty"`
+	Or    []Filter `json:"or,omitempty"`
+}
+
+// IsZero return true for the filter matching everything
+func (f Filter) IsZero() bool {
+	return f.Field == "" && len(f.And) == 0 && len(f.Or) == 0
+}
+
+// Validate return error if the filter use fields other than the given ones or can not be matched
+func (f Filter) Validate(fields []string) error {
+	switch {
+	case len(f.And) > 0 || len(f.Or) > 0:
+		if f.Field != "" || (len(f.And) > 0 && len(f.Or) > 0) {
+			return fmt.Errorf("filter must be either a field match, and or or")
+		}
+
+		for _, nested := range append(f.And, f.Or...) {
+			if err := nested.Validate(fields); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case f.Field == "":
+		return nil
+	}
+
+	known := false
+
+	switch {
+	case f.Field == HasVarField:
+		if f.Match != MatchExact {
+			return fmt.Errorf("%v can only be matched exactly", f.Field)
+		}
+
+		return validVarPath(f.Value)
+	case strings.HasPrefix(f.Field, VarPrefix):
+		if err := validVarPath(strings.TrimPrefix(f.Field, VarPrefix)); err != nil {
+			return err
+		}
+
+		known = true
+	}
+
+	for _, field := range fields {
+		if f.Field == field {
+			known = true
+		}
+	}
+
+	if !known {
+		return fmt.Errorf("unknown filter field %v, allowed fields are %v, %vkeys and %v", f.Field,
+			strings.Join(fields, ", "), VarPrefix, HasVarField)
+	}
+
+	switch f.Match {
+	case MatchExact, MatchPrefix, MatchGlob:
+	case MatchRegex:
+		if _, err := regexp.Compile(f.Value); err != nil {
+			return fmt.Errorf("%v: %w", f.Field, err)
+		}
+	default:
+		return fmt.Errorf("unknown filter match %q, allowed matches are =, ^=, *=, ~=", f.Match)
+	}
+
+	if f.IsBool() {
+		if f.Match != MatchExact {
+			return fmt.Errorf("%v can only be matched exactly", f.Field)
+		} else if _, err := strconv.ParseBool(f.Value); err != nil {
+			return fmt.Errorf("%v must be true or false", f.Field)
+		}
+	}
+
+	return nil
+}
+
+// IsBool return true for a filter on a boolean field
+func (f Filter) IsBool() bool {
+	return boolFields[f.Field]
+}
+
+// Bool return the value of a filter on a boolean field
+func (f Filter) Bool() bool {
+	b, _ := strconv.ParseBool(f.Value)
+	return b
+}
+
+// Matches return true if the values of the fields, as returned by values, match the filter. Fields with many
+// values match when any of them match. The filter must be valid
+func (f Filter) Matches(values func(field string) []string) bool {
+	switch {
+	case len(f.And) > 0:
+		for _, nested := range f.And {
+			if !nested.Matches(values) {
+				return false
+			}
+		}
+
+		return true
+	case len(f.Or) > 0:
+		for _, nested := range f.Or {
+			if nested.Matches(values) {
+				return true
+			}
+		}
+
+		return false
+	case f.Field == "":
+		return true
+	case f.Field == HasVarField:
+		return len(values(VarPrefix+f.Value)) > 0
+	}
+
+	for _, value := range values(f.Field) {
+		if f.matchValue(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Filter) matchValue(value string) bool {
+	switch f.Match {
+	case MatchExact:
+		if f.IsBool() {
+			b, _ := strconv.ParseBool(value)
+			return b == f.Bool()
+		}
+
+		return value == f.Value
+	case MatchPrefix:
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(f.Value))
+	case MatchGlob:
+		return regexp.MustCompile(globRegexp(f.Value)).MatchString(value)
+	case MatchRegex:
+		return regexp.MustCompile("(?i)" + f.Value).MatchString(value)
+	}
+
+	return false
+}
+
+// validVarPath return error if path is not dot separated variable keys
+func validVarPath(path string) error {
+	for _, key := range VarPath(path) {
+		if key == "" || strings.ContainsAny(key, "\"\\") {
+			return fmt.Errorf("invalid variable %q, expecting dot separated keys, e.g. os.family", path)
+		}
+	}
+
+	return nil
+}
+
+// VarPath return the keys of a dot separated variable path
+func VarPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// VarValue return the value of the variable at the dot separated path in the json encoded variables. Strings
+// are returned without quotes and other values as json, ok is false when the variable is not set
+func VarValue(variables, path string) (value string, ok bool) {
+	d := json.NewDecoder(strings.NewReader(variables))
+	d.UseNumber()
+
+	var v interface{}
+
+	if d.Decode(&v) != nil {
+		return "", false
+	}
+
+	for _, key := range VarPath(path) {
+		m, isMap := v.(map[string]interface{})
+		if !isMap {
+			return "", false
+		}
+
+		if v, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+
+	if s, isString := v.(string); isString {
+		return s, true
+	}
+
+	var b bytes.Buffer
+
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+
+	if e.Encode(v) != nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), true
+}
+
+// globRegexp return the case insensitive regular expression matching the whole value to the glob pattern
+func globRegexp(pattern string) string {
+	var b strings.Builder
+
+	b.WriteString("(?is)^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// String return the filter in the format read by ParseFilter
+func (f Filter) String() string {
+	switch {
+	case len(f.And) > 0:
+		parts := make([]string, len(f.And))
+		for i, nested := range f.And {
+			parts[i] = nested.String()
+			if len(nested.Or) > 0 {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+
+		return strings.Join(parts, " and ")
+	case len(f.Or) > 0:
+		parts := make([]string, len(f.Or))
+		for i, nested := range f.Or {
+			parts[i] = nested.String()
+		}
+
+		return strings.Join(parts, " or ")
+	case f.Field == "":
+		return ""
+	}
+
+	value := f.Value
+	if value == "" || strings.ContainsAny(value, " \t()'\"") {
+		value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+
+	return f.Field + string(f.Match) + value
+}
+
+// ParseFilter read a filter of field matches combined with `and` and `or`, e.g.
+// `domain=prod.local and (hostname^=web or group=web)`. `and` bind tighter than `or`, values holding spaces
+// or parentheses are quoted with ' or " and a quote is escaped by doubling it. An empty filter matches
+// everything
+func ParseFilter(s string) (Filter, error) {
+	p := filterParser{s: s}
+
+	p.skipSpace()
+
+	if p.done() {
+		return Filter{}, nil
+	}
+
+	f, err := p.or()
+	if err != nil {
+		return f, err
+	}
+
+	if !p.done() {
+		return f, fmt.Errorf("unexpected %q at position %v of filter", p.s[p.pos:], p.pos+1)
+	}
+
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *filterParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// keyword consume the case insensitive word if it is next
+func (p *filterParser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.s) || !strings.EqualFold(p.s[p.pos:end], word) {
+		return false
+	}
+
+	if end < len(p.s) && !unicode.IsSpace(rune(p.s[end])) && p.s[end] != '(' {
+		return false
+	}
+
+	p.pos = end
+	p.skipSpace()
+
+	return true
+}
+
+func (p *filterParser) or() (Filter, error) {
+	var filters []Filter
+
+	for {
+		f, err := p.and()
+		if err != nil {
+			return f, err
+		}
+
+		filters = append(filters, f)
+
+		if !p.keyword("or") {
+			break
+		}
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return Filter{Or: filters}, nil
+}
+
+func (p *filterParser) and() (Filter, error) {
+	var filters []Filter
+
+	for {
+		f, err := p.term()
+		if err != nil {
+			return f, err
+		}
+
+		filters = append(filters, f)
+
+		if !p.keyword("and") {
+			break
+		}
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return Filter{And: filters}, nil
+}
+
+func (p *filterParser) term() (Filter, error) {
+	if !p.done() && p.s[p.pos] == '(' {
+		p.pos++
+		p.skipSpace()
+
+		f, err := p.or()
+		if err != nil {
+			return f, err
+		}
+
+		if p.done() || p.s[p.pos] != ')' {
+			return f, fmt.Errorf("missing ) at position %v of filter", p.pos+1)
+		}
+
+		p.pos++
+		p.skipSpace()
+
+		return f, nil
+	}
+
+	return p.match()
+}
+
+func (p *filterParser) match() (f Filter, err error) {
+	start := p.pos
+	for !p.done() && (strings.ContainsRune("_.-", rune(p.s[p.pos])) || unicode.IsLetter(rune(p.s[p.pos])) ||
+		unicode.IsDigit(rune(p.s[p.pos]))) {
+		p.pos++
+	}
+
+	if f.Field = strings.ToLower(p.s[start:p.pos]); f.Field == "" {
+		return f, fmt.Errorf("expected field name at position %v of filter", p.pos+1)
+	}
+
+	// variable keys are case sensitive
+	if strings.HasPrefix(f.Field, VarPrefix) {
+		f.Field = VarPrefix + p.s[start+len(VarPrefix):p.pos]
+	}
+
+	p.skipSpace()
+
+	for _, m := range matches {
+		if strings.HasPrefix(p.s[p.pos:], string(m)) {
+			f.Match = m
+			p.pos += len(m)
+
+			break
+		}
+	}
+
+	if f.Match == "" {
+		return f, fmt.Errorf("expected one of =, ^=, *=, ~= after %v at position %v of filter", f.Field, p.pos+1)
+	}
+
+	p.skipSpace()
+
+	if f.Value, err = p.value(); err != nil {
+		return f, err
+	}
+
+	p.skipSpace()
+
+	return f, nil
+}
+
+func (p *filterParser) value() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("missing value at the end of filter")
+	}
+
+	if quote := p.s[p.pos]; quote == '\'' || quote == '"' {
+		var b strings.Builder
+
+		for p.pos++; !p.done(); p.pos++ {
+			if p.s[p.pos] != quote {
+				b.WriteByte(p.s[p.pos])
+				continue
+			}
+
+			// a doubled quote is part of the value
+			if p.pos+1 < len(p.s) && p.s[p.pos+1] == quote {
+				b.WriteByte(quote)
+				p.pos++
+
+				continue
+			}
+
+			p.pos++
+
+			return b.String(), nil
+		}
+
+		return "", fmt.Errorf("missing closing %c in filter", quote)
+	}
+
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(rune(p.s[p.pos])) && p.s[p.pos] != ')' {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", fmt.Errorf("missing value at position %v of filter", p.pos+1)
+	}
+
+	return p.s[start:p.pos], nil
+}
+
+// Page select a window of an ordered listing
+type Page struct {
+	// Limit is the most records in the page, zero is no limit
+	Limit int `json:"limit,omitempty"`
+	// Offset is the number of records skipped before the page
+	Offset int `json:"offset,omitempty"`
+}
+
+// Validate return error if the limit or offset is negative
+func (p Page) Validate() error {
+	if p.Limit < 0 || p.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative, got limit %v offset %v", p.Limit, p.Offset)
+	}
+
+	return nil
+}
+
+// Bounds return the slice bounds of the page within a listing of n records
+func (p Page) Bounds(n int) (start, end int) {
+	start, end = p.Offset, n
+	if start > n {
+		start = n
+	}
+
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+
+	return start, end
+}
@@ -0,0 +1,190 @@
+// nolint
+package datastructs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	host1 := Filter{Field: "hostname", Match: MatchExact, Value: "host1"}
+	prod := Filter{Field: "domain", Match: MatchExact, Value: "prod.local"}
+	web := Filter{Field: "group", Match: MatchPrefix, Value: "web"}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    Filter
+		wantErr bool
+	}{
+		{name: "empty", s: "  "},
+		{name: "single match", s: "hostname=host1", want: host1},
+		{name: "spaces around match", s: " hostname = host1 ", want: host1},
+		{name: "and", s: "hostname=host1 and domain=prod.local", want: Filter{And: []Filter{host1, prod}}},
+		{name: "and bind tighter than or", s: "hostname=host1 or domain=prod.local AND group^=web",
+			want: Filter{Or: []Filter{host1, {And: []Filter{prod, web}}}}},
+		{name: "parentheses", s: "(hostname=host1 or domain=prod.local) and group^=web",
+			want: Filter{And: []Filter{{Or: []Filter{host1, prod}}, web}}},
+		{name: "glob", s: "ip*=10.0.*", want: Filter{Field: "ip", Match: MatchGlob, Value: "10.0.*"}},
+		{name: "quoted regex", s: `hostname~='^(web|db)[0-9]+$'`,
+			want: Filter{Field: "hostname", Match: MatchRegex, Value: "^(web|db)[0-9]+$"}},
+		{name: "doubled quote", s: `domain="a ""b"""`, want: Filter{Field: "domain", Match: MatchExact, Value: `a "b"`}},
+		{name: "value named like a keyword", s: "hostname=or", want: Filter{Field: "hostname", Match: MatchExact,
+			Value: "or"}},
+		{name: "variable keep key case", s: "VAR.OS.Family=debian",
+			want: Filter{Field: "var.OS.Family", Match: MatchExact, Value: "debian"}},
+		{name: "has var", s: "has_var=os-release", want: Filter{Field: HasVarField, Match: MatchExact,
+			Value: "os-release"}},
+		{name: "missing value", s: "hostname=", wantErr: true},
+		{name: "missing match", s: "hostname host1", wantErr: true},
+		{name: "missing field", s: "=host1", wantErr: true},
+		{name: "dangling and", s: "hostname=host1 and", wantErr: true},
+		{name: "unclosed parentheses", s: "(hostname=host1", wantErr: true},
+		{name: "unclosed quote", s: "hostname='host1", wantErr: true},
+		{name: "trailing text", s: "hostname=host1 host2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter() = %+v, want %+v", got, tt.want)
+			}
+
+			// the filter is written back in a format read to the same filter
+			if again, err := ParseFilter(got.String()); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ParseFilter(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantErr bool
+	}{
+		{name: "zero", filter: Filter{}},
+		{name: "host field", filter: Filter{Field: "inherited_group", Match: MatchGlob, Value: "dc*"}},
+		{name: "bool", filter: Filter{Field: "enabled", Match: MatchExact, Value: "false"}},
+		{name: "unknown field", filter: Filter{Field: "name", Match: MatchExact, Value: "group1"}, wantErr: true},
+		{name: "unknown match", filter: Filter{Field: "hostname", Match: "!=", Value: "host1"}, wantErr: true},
+		{name: "bool prefix", filter: Filter{Field: "enabled", Match: MatchPrefix, Value: "t"}, wantErr: true},
+		{name: "bool value", filter: Filter{Field: "enabled", Match: MatchExact, Value: "yes"}, wantErr: true},
+		{name: "invalid regex", filter: Filter{Field: "hostname", Match: MatchRegex, Value: "("}, wantErr: true},
+		{name: "nested", filter: Filter{And: []Filter{{Field: "hostname", Match: "?"}}}, wantErr: true},
+		{name: "variable", filter: Filter{Field: "var.os.family", Match: MatchRegex, Value: "^deb"}},
+		{name: "empty variable key", filter: Filter{Field: "var.os..family", Match: MatchExact, Value: "a"},
+			wantErr: true},
+		{name: "has var", filter: Filter{Field: HasVarField, Match: MatchExact, Value: "os.family"}},
+		{name: "has var prefix", filter: Filter{Field: HasVarField, Match: MatchPrefix, Value: "os"}, wantErr: true},
+		{name: "and with field", filter: Filter{Field: "hostname", And: []Filter{{}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(HostFilterFields); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	values := map[string][]string{
+		"hostname": {"Web1"},
+		"enabled":  {"true"},
+		"group":    {"web", "dc1-prod"},
+		"var.port": {"22"},
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{filter: "", want: true},
+		{filter: "hostname=Web1", want: true},
+		{filter: "hostname=web1"},
+		{filter: "hostname^=WEB", want: true},
+		{filter: "hostname*=w?b*", want: true},
+		{filter: "hostname*=w.b*"},
+		{filter: "hostname~=^web[0-9]$", want: true},
+		{filter: "enabled=TRUE", want: true},
+		{filter: "enabled=false"},
+		{filter: "group*=dc?-*", want: true},
+		{filter: "group=dc1"},
+		{filter: "domain=prod.local or group=web", want: true},
+		{filter: "domain=prod.local and group=web"},
+		{filter: "var.port=22 and has_var=port", want: true},
+		{filter: "has_var=os"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := f.Matches(func(field string) []string { return values[field] }); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVarValue(t *testing.T) {
+	variables := `{"os": {"family": "Debian", "version": 10.4, "tags": ["a", "<b>"]}, "managed": true, "none": null}`
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{path: "os.family", want: "Debian", wantOk: true},
+		{path: "os.version", want: "10.4", wantOk: true},
+		{path: "os.tags", want: `["a","<b>"]`, wantOk: true},
+		{path: "os", want: `{"family":"Debian","tags":["a","<b>"],"version":10.4}`, wantOk: true},
+		{path: "managed", want: "true", wantOk: true},
+		{path: "none", want: "null", wantOk: true},
+		{path: "os.missing"},
+		{path: "managed.sub"},
+		{path: "OS.family"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := VarValue(variables, tt.path)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("VarValue() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPage_Bounds(t *testing.T) {
+	tests := []struct {
+		page      Page
+		n         int
+		wantStart int
+		wantEnd   int
+	}{
+		{page: Page{}, n: 5, wantStart: 0, wantEnd: 5},
+		{page: Page{Limit: 2}, n: 5, wantStart: 0, wantEnd: 2},
+		{page: Page{Limit: 2, Offset: 4}, n: 5, wantStart: 4, wantEnd: 5},
+		{page: Page{Offset: 3}, n: 5, wantStart: 3, wantEnd: 5},
+		{page: Page{Limit: 2, Offset: 7}, n: 5, wantStart: 5, wantEnd: 5},
+		{page: Page{Limit: 1}, n: 0, wantStart: 0, wantEnd: 0},
+	}
+	for _, tt := range tests {
+		start, end := tt.page.Bounds(tt.n)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("%+v.Bounds(%v) = %v, %v, want %v, %v", tt.page, tt.n, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
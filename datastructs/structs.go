@@ -2,12 +2,19 @@ package datastructs
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/netip"
 	"sort"
 	"strings"
 )
 
-// Host represents inventory host
+// ErrRevisionConflict is returned when writing a host or group based on a revision other than the stored one,
+// meaning the record was changed by someone else since it was read
+var ErrRevisionConflict = errors.New("changed by someone else since it was read")
+
+// Host represents inventory host. `Revision` is increased on every change of the host, writing a host
+// with a `Revision` other than the stored one fails with ErrRevisionConflict unless it is 0
 type Host struct {
 	ID              int           `json:"-" db:"host_id"`
 	Host            string        `json:"ip" db:"host"`
@@ -17,8 +24,34 @@ type Host struct {
 	PrettyVariables InventoryVars `json:"variables"`
 	Enabled         bool          `json:"enable" db:"enabled"`
 	Monitored       bool          `json:"monitor" db:"monitored"`
-	DirectGroup     string        `json:"direct_group" db:"direct_group"`
+	DirectGroups    GroupNames    `json:"direct_groups" db:"direct_group"`
 	InheritedGroups string        `json:"-" db:"inherited_groups"`
+	Addresses       Addresses     `json:"addresses" db:"-"`
+	Created         string        `json:"created,omitempty" db:"created"`
+	Updated         string        `json:"updated,omitempty" db:"updated"`
+	Deleted         string        `json:"deleted,omitempty" db:"deleted"`
+	Revision        int64         `json:"revision,omitempty" db:"revision"`
+}
+
+// UnmarshalJSON decode host json, a single `direct_group` from older exports is read as the
+// only direct group of the host
+func (h *Host) UnmarshalJSON(b []byte) error {
+	type host Host
+
+	legacy := struct {
+		*host
+		DirectGroup string `json:"direct_group"`
+	}{host: (*host)(h)}
+
+	if err := json.Unmarshal(b, &legacy); err != nil {
+		return err
+	}
+
+	if legacy.DirectGroup != "" && len(h.DirectGroups) == 0 {
+		h.DirectGroups = GroupNames{legacy.DirectGroup}
+	}
+
+	return nil
 }
 
 // UnmarshalVars convert string json `Host.Variables` to json value of
@@ -49,6 +82,66 @@ func (h *Host) MarshalVars() error {
 	return nil
 }
 
+// GroupNames is a sorted list of group names, stored by the database as comma separated string
+type GroupNames []string
+
+// Scan implements sql.Scanner for the comma separated group names
+func (g *GroupNames) Scan(src interface{}) error {
+	var names string
+
+	switch v := src.(type) {
+	case nil:
+	case string:
+		names = v
+	case []byte:
+		names = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into group names", src)
+	}
+
+	*g = NewGroupNames(strings.Split(names, ","))
+
+	return nil
+}
+
+// NewGroupNames return the sorted unique non-empty names
+func NewGroupNames(names []string) (g GroupNames) {
+	for _, name := range names {
+		if name != "" && !g.Contains(name) {
+			g = append(g, name)
+		}
+	}
+
+	sort.Strings(g)
+
+	return g
+}
+
+// Contains return true if name is in the list
+func (g GroupNames) Contains(name string) bool {
+	for _, n := range g {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON encode the names as json list, empty list when there are no names
+func (g GroupNames) MarshalJSON() ([]byte, error) {
+	if g == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]string(g))
+}
+
+// String return the comma separated group names
+func (g GroupNames) String() string {
+	return strings.Join(g, ",")
+}
+
 // ByHostname implements sort.Interface for []Host based on
 // the hostname field.
 type ByHostname []Host
@@ -74,7 +167,18 @@ func (h ByIP) Len() int {
 }
 
 func (h ByIP) Less(i, j int) bool {
-	return h[i].Host < h[j].Host
+	a, errA := netip.ParseAddr(h[i].Host)
+	b, errB := netip.ParseAddr(h[j].Host)
+
+	switch {
+	case errA == nil && errB == nil:
+		return a.Less(b)
+	case errA == nil || errB == nil:
+		// valid addresses are sorted before invalid ones
+		return errA == nil
+	default:
+		return h[i].Host < h[j].Host
+	}
 }
 
 func (h ByIP) Swap(i, j int) {
@@ -97,6 +201,26 @@ func (h ByDomain) Swap(i, j int) {
 	h[i], h[j] = h[j], h[i]
 }
 
+// ParseIP return the IPv4 or IPv6 address in val and error if val is not a valid ip address
+func ParseIP(val string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(val)
+	if err != nil {
+		return addr, fmt.Errorf("invalid ip address %q", val)
+	}
+
+	return addr, nil
+}
+
+// FormatIP return val enclosed in brackets when it is an IPv6 address so it can be followed by a port,
+// any other value is returned as is
+func FormatIP(val string) string {
+	if addr, err := netip.ParseAddr(val); err == nil && addr.Is6() {
+		return "[" + val + "]"
+	}
+
+	return val
+}
+
 // Hosts slice of Host
 type Hosts []Host
 
@@ -117,7 +241,7 @@ func (h Hosts) Sort(field string) error {
 	return nil
 }
 
-// Group represent inventory group
+// Group represent inventory group. `Revision` is handled the same way as `Host.Revision`
 type Group struct {
 	ID              int           `json:"-" db:"group_id"`
 	Name            string        `json:"name" db:"name"`
@@ -128,6 +252,10 @@ type Group struct {
 	NumChildren     int           `json:"-" db:"num_children"`
 	NumHosts        int           `json:"-" db:"num_hosts"`
 	ChildGroups     string        `json:"-" db:"child_groups"`
+	Created         string        `json:"created,omitempty" db:"created"`
+	Updated         string        `json:"updated,omitempty" db:"updated"`
+	Deleted         string        `json:"deleted,omitempty" db:"deleted"`
+	Revision        int64         `json:"revision,omitempty" db:"revision"`
 }
 
 // UnmarshalVars convert string json `Group.Variables` to json value of
@@ -294,6 +422,83 @@ type HostGroup struct {
 	GroupID int    `json:"group_id" db:"group_id"`
 }
 
+// Address represent a named network address of a host (e.g. management, storage or public interface)
+type Address struct {
+	ID      int    `json:"-" db:"address_id"`
+	HostID  int    `json:"-" db:"host_id"`
+	Host    string `json:"-" db:"host"`
+	Name    string `json:"name" db:"name"`
+	Address string `json:"address" db:"address"`
+	Primary bool   `json:"primary" db:"is_primary"`
+}
+
+// Addresses slice of Address. A nil slice means the host addresses are unknown and are left
+// untouched when the host is saved, an empty slice means the host has no addresses
+type Addresses []Address
+
+// MarshalJSON encode the addresses as json list, empty list when there are no addresses
+func (a Addresses) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]Address(a))
+}
+
+// Get return the address with the given name and true if exists
+func (a Addresses) Get(name string) (Address, bool) {
+	for i := range a {
+		if a[i].Name == name {
+			return a[i], true
+		}
+	}
+
+	return Address{}, false
+}
+
+// Primary return the primary address and true if exists
+func (a Addresses) Primary() (Address, bool) {
+	for i := range a {
+		if a[i].Primary {
+			return a[i], true
+		}
+	}
+
+	return Address{}, false
+}
+
+// Validate return error if an address is missing its name or address, if an address is not a valid ip
+// address, if two addresses have the same name or if more than one address is primary
+func (a Addresses) Validate() error {
+	names := make(map[string]bool)
+	primary := 0
+
+	for i := range a {
+		switch {
+		case a[i].Name == "" || a[i].Address == "":
+			return fmt.Errorf("address must have both name and address")
+		case names[a[i].Name]:
+			return fmt.Errorf("duplicate address name %v", a[i].Name)
+		}
+
+		if _, err := ParseIP(a[i].Address); err != nil {
+			return err
+		}
+
+		names[a[i].Name] = true
+
+		if a[i].Primary {
+			primary++
+		}
+	}
+
+	if primary > 1 {
+		return fmt.Errorf("only one address can be primary")
+	}
+
+	return nil
+}
+
 // Inventory struct
 
 // InventoryVars is map used to cast inventory json vars to Ansible inventory host / group vars
@@ -306,8 +511,8 @@ type InventoryHosts map[string]InventoryVars
 // group-children and group-hosts relationship
 type InventoryGroupsData struct {
 	Children []string      `json:"children,omitempty"`
-	Vars     InventoryVars `json:"vars,omitempty"`
 	Hosts    []string      `json:"hosts,omitempty"`
+	Vars     InventoryVars `json:"vars,omitempty"`
 }
 
 // InventoryGroups represent a map of group name and it's InventoryGroupsData
@@ -330,3 +535,62 @@ type Prometheus struct {
 		InheritedGroups string `json:"inherited_groups"`
 	} `json:"labels"`
 }
+
+// Migration represent a database schema version, `AppliedAt` is empty for
+// migrations that were not yet applied to the database
+type Migration struct {
+	Version     int    `json:"version" db:"version"`
+	Description string `json:"description" db:"description"`
+	AppliedAt   string `json:"applied_at" db:"applied_at"`
+}
+
+// DumpVersion is the version of the Dump document written by this version of admiral
+const DumpVersion = 1
+
+// Dump is a backend independent point in time copy of the whole inventory. Hosts list their direct groups
+// and addresses, child-group relationships and memberships reference the groups by name
+type Dump struct {
+	Version     int              `json:"version"`
+	Created     string           `json:"created"`
+	Groups      Groups           `json:"groups"`
+	ChildGroups []DumpChildGroup `json:"child_groups"`
+	Hosts       Hosts            `json:"hosts"`
+}
+
+// DumpChildGroup is a child-group relationship in a Dump
+type DumpChildGroup struct {
+	Parent string `json:"parent"`
+	Child  string `json:"child"`
+}
+
+// Audited entities
+const (
+	EntityHost       = "host"
+	EntityGroup      = "group"
+	EntityChildGroup = "child-group"
+	EntityHostGroup  = "host-group"
+	EntityAddress    = "address"
+)
+
+// Audited actions
+const (
+	ActionInsert = "insert"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionPurge  = "purge"
+)
+
+// Change represent an audit log entry of a single inventory change. `Before` and `After` hold the
+// JSON state of the entity, `Before` is empty for inserts and `After` is empty for deletions.
+// Hosts and groups restored from the trash are recorded as inserts
+type Change struct {
+	ID        int64  `json:"id" db:"id"`
+	ChangedAt string `json:"changed_at" db:"changed_at"`
+	Actor     string `json:"actor" db:"actor"`
+	Entity    string `json:"entity" db:"entity"`
+	Name      string `json:"name" db:"name"`
+	Related   string `json:"related" db:"related"`
+	Action    string `json:"action" db:"action"`
+	Before    string `json:"before" db:"before_state"`
+	After     string `json:"after" db:"after_state"`
+}
@@ -0,0 +1,223 @@
+// Package fixtures hold the records of 02_test_data.sql as the database functions return them, shared by the tests
+// of the backends and commands so the test data is described once
+package fixtures
+
+import (
+	"github.com/via-justa/admiral/datastructs"
+)
+
+// The records of 02_test_data.sql
+
+// host:
+// id|host   |hostname |domain       |variables                                        |enabled|monitored|
+// --|-------|---------|-------------|-------------------------------------------------|-------|---------|
+//  1|1.1.1.1|host1    |domain.local |{"host_var1": {"host_sub_var1": "host_sub_val1"}}|      1|        1|
+//  2|2.2.2.2|host2    |domain.local |{"host_var2": "host_val2"}                       |      1|        1|
+//  3|3.3.3.3|host3    |domain.local |{"host_var3": "host_val3"}                       |      1|        1|
+
+// group
+// id|name  |variables                                           |enabled|monitored|
+// --|------|----------------------------------------------------|-------|---------|
+//  1|group1|{"group_var1": {"group_sub_var1": "group_sub_val1"}}|      1|        1|
+//  2|group2|{"group_var2": "group_val2"}                        |      1|        1|
+//  3|group3|{"group_var3": "group_val3"}                        |      1|        1|
+//  4|group4|{"group_var4": "group_val4"}                        |      1|        1|
+//  5|group5|{"group_var5": "group_val5"}                        |      1|        1|
+
+// hostgroups
+// id|host_id|group_id|
+// --|-------|--------|
+//  1|      1|       1|
+//  2|      2|       2|
+//  3|      3|       3|
+
+// childgroups
+// id|child_id|parent_id|
+// --|--------|---------|
+//  1|       3|        4|
+//  2|       4|        5|
+
+// The test data records by table and ID
+var (
+	Group1 = datastructs.Group{
+		ID:        1,
+		Name:      "group1",
+		Variables: "{\"group_var1\": {\"group_sub_var1\": \"group_sub_val1\"}}",
+		Enabled:   true,
+		Monitored: true,
+		NumHosts:  1,
+		Created:   "2020-11-01T10:00:00Z",
+		Updated:   "2020-11-01T10:00:00Z",
+		Revision:  1,
+	}
+	Group2 = datastructs.Group{
+		ID:        2,
+		Name:      "group2",
+		Variables: "{\"group_var2\": \"group_val2\"}",
+		Enabled:   true,
+		Monitored: true,
+		NumHosts:  1,
+		Created:   "2020-11-01T10:00:00Z",
+		Updated:   "2020-11-01T10:00:00Z",
+		Revision:  1,
+	}
+	Group3 = datastructs.Group{
+		ID:        3,
+		Name:      "group3",
+		Variables: "{\"group_var3\": \"group_val3\"}",
+		Enabled:   true,
+		Monitored: true,
+		NumHosts:  1,
+		Created:   "2020-11-01T10:00:00Z",
+		Updated:   "2020-11-01T10:00:00Z",
+		Revision:  1,
+	}
+	Group4 = datastructs.Group{
+		ID:          4,
+		Name:        "group4",
+		Variables:   "{\"group_var4\": \"group_val4\"}",
+		Enabled:     true,
+		Monitored:   true,
+		ChildGroups: "group3",
+		NumChildren: 1,
+		Created:     "2020-11-01T10:00:00Z",
+		Updated:     "2020-11-01T10:00:00Z",
+		Revision:    1,
+	}
+	Group5 = datastructs.Group{
+		ID:          5,
+		Name:        "group5",
+		Variables:   "{\"group_var5\": \"group_val5\"}",
+		Enabled:     true,
+		Monitored:   true,
+		ChildGroups: "group3,group4",
+		NumChildren: 2,
+		Created:     "2020-12-01T10:00:00Z",
+		Updated:     "2020-12-01T10:00:00Z",
+		Revision:    1,
+	}
+	Host1 = datastructs.Host{
+		ID:           1,
+		Hostname:     "host1",
+		Host:         "1.1.1.1",
+		Domain:       "domain.local",
+		Variables:    "{\"host_var1\": {\"host_sub_var1\": \"host_sub_val1\"}}",
+		Enabled:      true,
+		Monitored:    true,
+		DirectGroups: datastructs.GroupNames{"group1"},
+		Created:      "2020-11-01T10:00:00Z",
+		Updated:      "2020-11-01T10:00:00Z",
+		Revision:     1,
+	}
+	Host2 = datastructs.Host{
+		ID:           2,
+		Hostname:     "host2",
+		Host:         "2.2.2.2",
+		Domain:       "domain.local",
+		Variables:    "{\"host_var2\": \"host_val2\"}",
+		Enabled:      true,
+		Monitored:    true,
+		DirectGroups: datastructs.GroupNames{"group2"},
+		Created:      "2020-11-01T10:00:00Z",
+		Updated:      "2020-12-01T10:00:00Z",
+		Revision:     1,
+	}
+	Host3 = datastructs.Host{
+		ID:              3,
+		Hostname:        "host3",
+		Host:            "3.3.3.3",
+		Domain:          "domain.local",
+		Variables:       "{\"host_var3\": \"host_val3\"}",
+		Enabled:         true,
+		Monitored:       true,
+		DirectGroups:    datastructs.GroupNames{"group3"},
+		InheritedGroups: "group4,group5",
+		Created:         "2020-12-01T10:00:00Z",
+		Updated:         "2020-12-01T10:00:00Z",
+		Revision:        1,
+	}
+	HostGroup1 = datastructs.HostGroup{
+		ID:      1,
+		HostID:  1,
+		Host:    "host1",
+		GroupID: 1,
+		Group:   "group1",
+	}
+	HostGroup2 = datastructs.HostGroup{
+		ID:      2,
+		HostID:  2,
+		Host:    "host2",
+		GroupID: 2,
+		Group:   "group2",
+	}
+	HostGroup3 = datastructs.HostGroup{
+		ID:      3,
+		HostID:  3,
+		Host:    "host3",
+		GroupID: 3,
+		Group:   "group3",
+	}
+	Child1 = datastructs.ChildGroup{
+		ID:       1,
+		ChildID:  3,
+		Child:    "group3",
+		ParentID: 4,
+		Parent:   "group4",
+	}
+	Child2 = datastructs.ChildGroup{
+		ID:       2,
+		ChildID:  4,
+		Child:    "group4",
+		ParentID: 5,
+		Parent:   "group5",
+	}
+)
+
+// The records the tests create, they are not in the test data
+var (
+	CreateHost10 = datastructs.Host{
+		ID:           10,
+		Hostname:     "host10",
+		Host:         "10.10.10.10",
+		Domain:       "domain.local",
+		Variables:    "{\"host_var10\": \"host_val10\"}",
+		Enabled:      true,
+		Monitored:    true,
+		DirectGroups: datastructs.GroupNames{"group1"},
+	}
+	CreateGroup10 = datastructs.Group{
+		ID:        1,
+		Name:      "group10",
+		Variables: "{\"group_var10\": {\"group_sub_var10\": \"group_sub_val10\"}}",
+		Enabled:   true,
+		Monitored: true,
+	}
+	CreateChild10 = datastructs.ChildGroup{
+		ID:       10,
+		ChildID:  2,
+		Child:    "group2",
+		ParentID: 3,
+		Parent:   "group3",
+	}
+	CreateChild11 = datastructs.ChildGroup{
+		ID:       11,
+		ChildID:  10,
+		Child:    "group10",
+		ParentID: 11,
+		Parent:   "group11",
+	}
+	CreateHostGroup10 = datastructs.HostGroup{
+		ID:      10,
+		HostID:  3,
+		Host:    "host3",
+		GroupID: 5,
+		Group:   "group5",
+	}
+	CreateHostGroup11Err = datastructs.HostGroup{
+		ID:      11,
+		HostID:  10,
+		Host:    "host10",
+		GroupID: 10,
+		Group:   "group10",
+	}
+)
@@ -0,0 +1,32 @@
+-- Clean tables
+TRUNCATE audit_log, addresses, hostgroups, childgroups, host, "group" RESTART IDENTITY;
+
+-- Create groups
+INSERT INTO "group" (id, name, variables, enabled, monitored, created, updated) VALUES (1, 'group1', '{"group_var1": {"group_sub_var1": "group_sub_val1"}}', true, true, '2020-11-01T10:00:00Z', '2020-11-01T10:00:00Z');
+INSERT INTO "group" (id, name, variables, enabled, monitored, created, updated) VALUES (2, 'group2', '{"group_var2": "group_val2"}', true, true, '2020-11-01T10:00:00Z', '2020-11-01T10:00:00Z');
+INSERT INTO "group" (id, name, variables, enabled, monitored, created, updated) VALUES (3, 'group3', '{"group_var3": "group_val3"}', true, true, '2020-11-01T10:00:00Z', '2020-11-01T10:00:00Z');
+INSERT INTO "group" (id, name, variables, enabled, monitored, created, updated) VALUES (4, 'group4', '{"group_var4": "group_val4"}', true, true, '2020-11-01T10:00:00Z', '2020-11-01T10:00:00Z');
+INSERT INTO "group" (id, name, variables, enabled, monitored, created, updated) VALUES (5, 'group5', '{"group_var5": "group_val5"}', true, true, '2020-12-01T10:00:00Z', '2020-12-01T10:00:00Z');
+
+-- Create hosts
+INSERT INTO host (id, host, hostname, domain, variables, enabled, monitored, created, updated) VALUES (1, '1.1.1.1', 'host1', 'domain.local', '{"host_var1": {"host_sub_var1": "host_sub_val1"}}', true, true, '2020-11-01T10:00:00Z', '2020-11-01T10:00:00Z');
+INSERT INTO host (id, host, hostname, domain, variables, enabled, monitored, created, updated) VALUES (2, '2.2.2.2', 'host2', 'domain.local', '{"host_var2": "host_val2"}', true, true, '2020-11-01T10:00:00Z', '2020-12-01T10:00:00Z');
+INSERT INTO host (id, host, hostname, domain, variables, enabled, monitored, created, updated) VALUES (3, '3.3.3.3', 'host3', 'domain.local', '{"host_var3": "host_val3"}', true, true, '2020-12-01T10:00:00Z', '2020-12-01T10:00:00Z');
+
+-- Create host-groups
+INSERT INTO hostgroups (id, host_id, group_id) VALUES (1, 1, 1);
+INSERT INTO hostgroups (id, host_id, group_id) VALUES (2, 2, 2);
+INSERT INTO hostgroups (id, host_id, group_id) VALUES (3, 3, 3);
+
+-- Create child-groups
+INSERT INTO childgroups (id, child_id, parent_id) VALUES (1, 3, 4);
+INSERT INTO childgroups (id, child_id, parent_id) VALUES (2, 4, 5);
+
+-- Move the sequences past the fixture ids
+SELECT setval(pg_get_serial_sequence('"group"', 'id'), (SELECT MAX(id) FROM "group"));
+
+SELECT setval(pg_get_serial_sequence('host', 'id'), (SELECT MAX(id) FROM host));
+
+SELECT setval(pg_get_serial_sequence('hostgroups', 'id'), (SELECT MAX(id) FROM hostgroups));
+
+SELECT setval(pg_get_serial_sequence('childgroups', 'id'), (SELECT MAX(id) FROM childgroups));